@@ -6,6 +6,8 @@
 
 package adapt
 
+import "time"
+
 type CaptData struct {
 	CaptchaKey        string `json:"captcha_key,omitempty"`
 	MasterImageBase64 string `json:"master_image_base64,omitempty"`
@@ -18,12 +20,34 @@ type CaptData struct {
 	DisplayX          int32  `json:"display_x,omitempty"`
 	DisplayY          int32  `json:"display_y,omitempty"`
 	Id                string `json:"id,omitempty"`
+
+	ExpiresAt time.Time `json:"-"`
+}
+
+// Point .
+type Point struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+}
+
+// TrackPoint is a point of the slide track, T is the offset in milliseconds from the start of the slide
+type TrackPoint struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	T int64 `json:"t"`
+}
+
+// SlideAnswer .
+type SlideAnswer struct {
+	Point Point        `json:"point"`
+	Track []TrackPoint `json:"track"`
 }
 
 type CaptNormalDataResponse struct {
 	Code    int32       `json:"code" default:"200"`
 	Message string      `json:"message" default:""`
 	Data    interface{} `json:"data"`
+	Reason  string      `json:"reason,omitempty"`
 }
 
 type CaptStatusInfo struct {
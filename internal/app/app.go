@@ -8,34 +8,54 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
-	"net"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sony/gobreaker"
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/gateway"
+	"github.com/wenlng/go-captcha-service/internal/grpcmux"
+	"github.com/wenlng/go-captcha-service/internal/health"
 	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/listener"
+	"github.com/wenlng/go-captcha-service/internal/logging"
 	"github.com/wenlng/go-captcha-service/internal/middleware"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
 	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
 	"github.com/wenlng/go-captcha-service/internal/server"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/internal/tlsconfig"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
+	"github.com/wenlng/go-captcha-service/internal/webhook"
 	"github.com/wenlng/go-captcha-service/proto"
+	protov2 "github.com/wenlng/go-captcha-service/proto/v2"
 	"github.com/wenlng/go-service-link/dynaconfig"
 	"github.com/wenlng/go-service-link/servicediscovery"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 )
 
 // App manages the application components
 type App struct {
 	logger         *zap.Logger
+	logs           *logging.Logger
+	audit          *audit.Log
+	webhooks       *webhook.Dispatcher
 	dynamicCfg     *config.DynamicConfig
 	dynamicCaptCfg *config2.DynamicCaptchaConfig
 	cacheMgr       *cache.CacheManager
@@ -43,9 +63,22 @@ type App struct {
 	configManager  *dynaconfig.ConfigManager
 	httpServer     *http.Server
 	grpcServer     *grpc.Server
+	adminHTTP      *http.Server
+	adminGRPC      *grpc.Server
 	cacheBreaker   *gobreaker.CircuitBreaker
 	limiter        *middleware.DynamicLimiter
+	keyedLimiter   *middleware.KeyedRateLimiter
+	ipFilter       *middleware.IPFilter
+	sigVerifier    *middleware.SignatureVerifier
+	payloadCodec   *middleware.PayloadCodec
+	jwtVerifier    *middleware.JWTVerifier
 	captcha        *gocaptcha.GoCaptcha
+	tenants        *tenant.Registry
+	tlsReloader    *tlsconfig.Reloader
+	adminTLS       *tlsconfig.Reloader
+	tracer         *tracing.Provider
+	healthChecker  *health.Checker
+	grpcHealth     *grpchealth.Server
 }
 
 // NewApp initializes the application
@@ -57,7 +90,12 @@ func NewApp() (*App, error) {
 	serviceName := flag.String("service-name", "", "Name for service")
 	serviceNode := flag.Int64("service-node", 1, "Node number for service")
 	httpPort := flag.String("http-port", "", "Port for HTTP server")
+	httpAddr := flag.String("http-addr", "", "Listener address for HTTP server: tcp://host:port, unix:///path")
 	grpcPort := flag.String("grpc-port", "", "Port for gRPC server")
+	grpcAddr := flag.String("grpc-addr", "", "Listener address for gRPC server: tcp://host:port, unix:///path")
+	unixSocketMode := flag.String("unix-socket-mode", "", "Octal file mode of the Unix domain sockets")
+	singlePort := flag.String("single-port", "false", "Serve gRPC and gRPC-Web on the HTTP listener")
+	enableGRPCWeb := flag.String("enable-grpc-web", "false", "Serve gRPC-Web on the HTTP listener")
 
 	cacheType := flag.String("cache-type", "", "CacheManager type: redis, memory, etcd, memcache")
 	cacheAddrs := flag.String("cache-addrs", "", "Comma-separated Cache cluster addresses")
@@ -99,14 +137,41 @@ func NewApp() (*App, error) {
 
 	rateLimitQPS := flag.Int("rate-limit-qps", 0, "Rate limit QPS")
 	rateLimitBurst := flag.Int("rate-limit-burst", 0, "Rate limit burst")
+	rateLimitKeyBy := flag.String("rate-limit-key-by", "", "Comma-separated rate limit keys: ip, api_key, site_key")
+	rateLimitMode := flag.String("rate-limit-mode", "", "Rate limit mode: local, distributed")
+	trustedProxies := flag.String("trusted-proxies", "", "Comma-separated trusted proxy IPs or CIDR blocks")
+	ipAllow := flag.String("ip-allow", "", "Comma-separated allowed client IPs or CIDR blocks")
+	ipDeny := flag.String("ip-deny", "", "Comma-separated denied client IPs or CIDR blocks")
+	tlsCertFile := flag.String("tls-cert-file", "", "Path to TLS certificate file of the HTTP and gRPC servers")
+	tlsKeyFile := flag.String("tls-key-file", "", "Path to TLS key file of the HTTP and gRPC servers")
+	tlsCaFile := flag.String("tls-ca-file", "", "Path to CA file verifying the client certificates")
+	tlsClientAuth := flag.String("tls-client-auth", "", "Client certificate verification: none, manage, all")
+	tlsMinVersion := flag.String("tls-min-version", "", "Minimum TLS version: 1.2, 1.3")
+	enableAdmin := flag.String("enable-admin", "false", "Serve the management routes on the admin listener")
+	adminBindAddr := flag.String("admin-bind-addr", "", "Bind address of the admin listener")
+	adminHTTPPort := flag.String("admin-http-port", "", "Port for the admin HTTP server")
+	adminGRPCPort := flag.String("admin-grpc-port", "", "Port for the admin gRPC server")
+	corsAllowedOrigins := flag.String("cors-allowed-origins", "", "Comma-separated origins allowed to make cross-origin requests")
 	apiKeys := flag.String("api-keys", "", "Comma-separated API keys")
 	authApis := flag.String("auth-apis", "", "Comma-separated Auth APIs")
-	logLevel := flag.String("log-level", "", "Set log level: error, debug, warn, info")
+	logLevel := flag.String("log-level", "", "Set log level: error, debug, warn, info, none")
 	healthCheckFlag := flag.String("health-check", "false", "Run health check and exit")
+	hashAPIKey := flag.String("hash-api-key", "", "Print the secret_hash of an API key secret and exit")
 	enableCorsFlag := flag.String("enable-cors", "true", "Enable cross-domain resources")
 
+	enableTracing := flag.String("enable-tracing", "false", "Enable OpenTelemetry tracing")
+	tracingExporter := flag.String("tracing-exporter", "", "Tracing exporter: otlp-grpc, otlp-http, stdout, file")
+	tracingEndpoint := flag.String("tracing-endpoint", "", "Endpoint for OTLP tracing exporter")
+	tracingSampleRatio := flag.Float64("tracing-sample-ratio", -1, "Ratio of traces to sample, between 0 and 1")
+	tracingFile := flag.String("tracing-file", "", "Path to output file for the file tracing exporter")
+
 	flag.Parse()
 
+	if *hashAPIKey != "" {
+		fmt.Println(config.HashAPIKeySecret(*hashAPIKey))
+		os.Exit(0)
+	}
+
 	// Read environment variables
 	if v, exists := os.LookupEnv("CONFIG"); exists {
 		*configFile = v
@@ -128,9 +193,69 @@ func NewApp() (*App, error) {
 	if v, exists := os.LookupEnv("GRPC_PORT"); exists {
 		*grpcPort = v
 	}
+	if v, exists := os.LookupEnv("HTTP_ADDR"); exists {
+		*httpAddr = v
+	}
+	if v, exists := os.LookupEnv("GRPC_ADDR"); exists {
+		*grpcAddr = v
+	}
+	if v, exists := os.LookupEnv("UNIX_SOCKET_MODE"); exists {
+		*unixSocketMode = v
+	}
+	if v, exists := os.LookupEnv("SINGLE_PORT"); exists {
+		*singlePort = v
+	}
+	if v, exists := os.LookupEnv("ENABLE_GRPC_WEB"); exists {
+		*enableGRPCWeb = v
+	}
 	if v, exists := os.LookupEnv("API_KEYS"); exists {
 		*apiKeys = v
 	}
+	if v, exists := os.LookupEnv("RATE_LIMIT_KEY_BY"); exists {
+		*rateLimitKeyBy = v
+	}
+	if v, exists := os.LookupEnv("RATE_LIMIT_MODE"); exists {
+		*rateLimitMode = v
+	}
+	if v, exists := os.LookupEnv("TRUSTED_PROXIES"); exists {
+		*trustedProxies = v
+	}
+	if v, exists := os.LookupEnv("IP_ALLOW"); exists {
+		*ipAllow = v
+	}
+	if v, exists := os.LookupEnv("IP_DENY"); exists {
+		*ipDeny = v
+	}
+	if v, exists := os.LookupEnv("TLS_CERT_FILE"); exists {
+		*tlsCertFile = v
+	}
+	if v, exists := os.LookupEnv("TLS_KEY_FILE"); exists {
+		*tlsKeyFile = v
+	}
+	if v, exists := os.LookupEnv("TLS_CA_FILE"); exists {
+		*tlsCaFile = v
+	}
+	if v, exists := os.LookupEnv("TLS_CLIENT_AUTH"); exists {
+		*tlsClientAuth = v
+	}
+	if v, exists := os.LookupEnv("TLS_MIN_VERSION"); exists {
+		*tlsMinVersion = v
+	}
+	if v, exists := os.LookupEnv("ENABLE_ADMIN"); exists {
+		*enableAdmin = v
+	}
+	if v, exists := os.LookupEnv("ADMIN_BIND_ADDR"); exists {
+		*adminBindAddr = v
+	}
+	if v, exists := os.LookupEnv("ADMIN_HTTP_PORT"); exists {
+		*adminHTTPPort = v
+	}
+	if v, exists := os.LookupEnv("ADMIN_GRPC_PORT"); exists {
+		*adminGRPCPort = v
+	}
+	if v, exists := os.LookupEnv("CORS_ALLOWED_ORIGINS"); exists {
+		*corsAllowedOrigins = v
+	}
 	if v, exists := os.LookupEnv("AUTH_APIS"); exists {
 		*authApis = v
 	}
@@ -157,6 +282,24 @@ func NewApp() (*App, error) {
 		*enableCorsFlag = v
 	}
 
+	if v, exists := os.LookupEnv("ENABLE_TRACING"); exists {
+		*enableTracing = v
+	}
+	if v, exists := os.LookupEnv("TRACING_EXPORTER"); exists {
+		*tracingExporter = v
+	}
+	if v, exists := os.LookupEnv("TRACING_ENDPOINT"); exists {
+		*tracingEndpoint = v
+	}
+	if v, exists := os.LookupEnv("TRACING_SAMPLE_RATIO"); exists {
+		if r, err := strconv.ParseFloat(v, 64); err == nil {
+			*tracingSampleRatio = r
+		}
+	}
+	if v, exists := os.LookupEnv("TRACING_FILE"); exists {
+		*tracingFile = v
+	}
+
 	if v, exists := os.LookupEnv("ENABLE_DYNAMIC_CONFIG"); exists {
 		*enableDynamicConfig = v
 	}
@@ -189,12 +332,11 @@ func NewApp() (*App, error) {
 		*serviceDiscoveryPassword = v
 	}
 
-	// Initialize logger
+	// Initialize the startup logger, it is replaced by the configured one once the config is loaded
 	logger, err := zap.NewProduction()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %v", err)
 	}
-	setupLoggerLevel(logger, *logLevel)
 
 	// Load configuration
 	dc, err := config.NewDynamicConfig(*configFile, true)
@@ -206,10 +348,6 @@ func NewApp() (*App, error) {
 		}
 		dc = config.DefaultDynamicConfig()
 	}
-	// Register hot update callback
-	dc.RegisterHotCallback("UPDATE_LOG_LEVEL", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
-		setupLoggerLevel(logger, dnCfg.Get().LogLevel)
-	})
 
 	// Load configuration
 	dgc, err := config2.NewDynamicConfig(*gocaptchaConfigFile, true)
@@ -230,6 +368,12 @@ func NewApp() (*App, error) {
 		"http-port":    *httpPort,
 		"grpc-port":    *grpcPort,
 
+		"http-addr":        *httpAddr,
+		"grpc-addr":        *grpcAddr,
+		"unix-socket-mode": *unixSocketMode,
+		"single-port":      *singlePort,
+		"enable-grpc-web":  *enableGRPCWeb,
+
 		"cache-type":       *cacheType,
 		"cache-addrs":      *cacheAddrs,
 		"cache-username":   *cacheUsername,
@@ -273,16 +417,88 @@ func NewApp() (*App, error) {
 		"enable-cors":      *enableCorsFlag,
 		"api-keys":         *apiKeys,
 		"auth-apis":        *authApis,
+		"log-level":        *logLevel,
+
+		"rate-limit-key-by":    *rateLimitKeyBy,
+		"rate-limit-mode":      *rateLimitMode,
+		"trusted-proxies":      *trustedProxies,
+		"ip-allow":             *ipAllow,
+		"ip-deny":              *ipDeny,
+		"cors-allowed-origins": *corsAllowedOrigins,
+		"tls-cert-file":        *tlsCertFile,
+		"tls-key-file":         *tlsKeyFile,
+		"tls-ca-file":          *tlsCaFile,
+		"tls-client-auth":      *tlsClientAuth,
+		"tls-min-version":      *tlsMinVersion,
+		"enable-admin":         *enableAdmin,
+		"admin-bind-addr":      *adminBindAddr,
+		"admin-http-port":      *adminHTTPPort,
+		"admin-grpc-port":      *adminGRPCPort,
+
+		"enable-tracing":       *enableTracing,
+		"tracing-exporter":     *tracingExporter,
+		"tracing-endpoint":     *tracingEndpoint,
+		"tracing-sample-ratio": *tracingSampleRatio,
+		"tracing-file":         *tracingFile,
 	})
 	if err = dc.Update(cfg); err != nil {
 		logger.Fatal("[App] Configuration validation failed", zap.Error(err))
 	}
 
+	// Setup logger
+	logs, err := logging.New(dc.Get())
+	if err != nil {
+		logger.Fatal("[App] Setup logger", zap.Error(err))
+	}
+	logger = logs.Logger
+	dc.RegisterHotCallback("UPDATE_LOG_LEVEL", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		logs.Update(dnCfg.Get())
+	})
+
+	// Setup audit log
+	auditCfg := dc.Get()
+	auditLog, err := audit.New(auditCfg.GetAuditLog())
+	if err != nil {
+		logger.Fatal("[App] Setup audit log", zap.Error(err))
+	}
+
+	// Setup webhooks
+	webhooks, err := webhook.New(dc.Get(), logger)
+	if err != nil {
+		logger.Fatal("[App] Setup webhooks", zap.Error(err))
+	}
+	dc.RegisterHotCallback("UPDATE_WEBHOOKS", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		webhooks.Update(dnCfg.Get())
+	})
+	setupConfigChangeHooks(auditLog, webhooks, dc, dgc, logs.Component(config.LogComponentConfig))
+
+	// Setup tracing
+	tracer, err := setupTracing(dc, logger)
+	if err != nil {
+		logger.Fatal("[App] Setup tracing", zap.Error(err))
+	}
+
 	// Initialize rate limiter
 	limiter := middleware.NewDynamicLimiter(cfg.RateLimitQPS, cfg.RateLimitBurst)
 	dc.RegisterHotCallback("UPDATE_LIMITER", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
 		limiter.Update(dnCfg.Get().RateLimitQPS, dnCfg.Get().RateLimitBurst)
 	})
+	keyedLimiter := middleware.NewKeyedRateLimiter(dc.Get())
+	dc.RegisterHotCallback("UPDATE_KEYED_LIMITER", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		keyedLimiter.Update(dnCfg.Get())
+	})
+
+	// Initialize ip filter
+	ipFilter := middleware.NewIPFilter(dc.Get())
+	dc.RegisterHotCallback("UPDATE_IP_FILTER", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		ipFilter.Update(dnCfg.Get())
+	})
+
+	// Initialize bearer token verifier
+	jwtVerifier := middleware.NewJWTVerifier(dc.Get(), logger)
+	dc.RegisterHotCallback("UPDATE_JWT_VERIFIER", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		jwtVerifier.Update(dnCfg.Get())
+	})
 
 	// Initialize circuit breaker
 	cacheBreaker := gobreaker.NewCircuitBreaker(gobreaker.Settings{
@@ -296,11 +512,22 @@ func NewApp() (*App, error) {
 	})
 
 	// Setup cache
-	cacheMgr, err := setupCacheManager(dc, logger)
+	cacheMgr, err := setupCacheManager(dc, logs.Component(config.LogComponentCache))
 	if err != nil {
 		logger.Fatal("[App] Create cache manager", zap.Error(err))
 	}
 
+	// Share the rate limits with the other nodes through the cache backend
+	distLimiter := middleware.NewDistributedLimiter(cacheMgr.GetCache, dc.Get(), logger)
+	limiter.SetDistributed(distLimiter)
+	keyedLimiter.SetDistributed(distLimiter)
+	dc.RegisterHotCallback("UPDATE_DISTRIBUTED_LIMITER", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		distLimiter.Update(dnCfg.Get())
+	})
+
+	// Verify signed requests, the nonces are shared with the other nodes through the cache backend
+	sigVerifier := middleware.NewSignatureVerifier(cacheMgr.GetCache, logger)
+
 	// Setup service discovery
 	discovery, err := setupServiceDiscovery(dc, logger)
 	if err != nil {
@@ -308,7 +535,7 @@ func NewApp() (*App, error) {
 	}
 
 	// Setup dynamic config
-	configManager, err := setupDynamicConfig(dc, dgc, logger)
+	configManager, configStatus, err := setupDynamicConfig(dc, dgc, logs.Component(config.LogComponentConfig))
 	if err != nil {
 		logger.Fatal("[App] Setup dynamic config manager", zap.Error(err))
 	}
@@ -324,18 +551,54 @@ func NewApp() (*App, error) {
 			logger.Error("[App] Failed to hot update gocaptcha, without any change: ", zap.Error(err))
 		}
 	})
+	captcha.GenerateQueue = gocaptcha.NewGenerateQueue(cfg.GenerateQueueSize)
+
+	// Setup tenants
+	tenants := tenant.NewRegistry(captcha.GenerateQueue, logger)
+	tenants.Sync(dc.Get())
+	dc.RegisterHotCallback("UPDATE_TENANTS", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		tenants.Sync(dnCfg.Get())
+	})
+
+	// Setup health checker
+	healthChecker := setupHealthChecker(dc, cacheMgr, captcha, configStatus)
 
 	// Perform health check if requested
 	if *healthCheckFlag == "true" {
-		if err = setupHealthCheck(":"+cfg.HTTPPort, ":"+cfg.GRPCPort); err != nil {
+		if err = setupHealthCheck(cfg.GetHTTPAddr(), cfg.GetGRPCAddr(), cfg.IsTLSEnabled(), time.Duration(cfg.HealthCheckTimeout)*time.Second); err != nil {
 			logger.Error("[App] Filed to health check", zap.Error(err))
 			os.Exit(1)
 		}
 		os.Exit(0)
 	}
 
+	// Setup TLS of the servers
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.IsTLSEnabled() {
+		tlsReloader, err = tlsconfig.NewReloader(tlsconfig.ServerOptions(cfg), logger)
+		if err != nil {
+			logger.Fatal("[App] Failed to setup TLS", zap.Error(err))
+		}
+		if err = tlsReloader.Watch(); err != nil {
+			logger.Warn("[App] Failed to watch the TLS certificate files, hot reload disabled", zap.Error(err))
+		}
+	}
+	var adminTLSReloader *tlsconfig.Reloader
+	if cfg.Admin.Enable && cfg.Admin.IsTLSEnabled() {
+		adminTLSReloader, err = tlsconfig.NewReloader(tlsconfig.AdminOptions(cfg.Admin), logger)
+		if err != nil {
+			logger.Fatal("[App] Failed to setup TLS of the admin listener", zap.Error(err))
+		}
+		if err = adminTLSReloader.Watch(); err != nil {
+			logger.Warn("[App] Failed to watch the admin TLS certificate files, hot reload disabled", zap.Error(err))
+		}
+	}
+
 	return &App{
 		logger:         logger,
+		logs:           logs,
+		audit:          auditLog,
+		webhooks:       webhooks,
 		dynamicCfg:     dc,
 		dynamicCaptCfg: dgc,
 		cacheMgr:       cacheMgr,
@@ -343,7 +606,17 @@ func NewApp() (*App, error) {
 		configManager:  configManager,
 		cacheBreaker:   cacheBreaker,
 		limiter:        limiter,
+		keyedLimiter:   keyedLimiter,
+		ipFilter:       ipFilter,
+		sigVerifier:    sigVerifier,
+		payloadCodec:   middleware.NewPayloadCodec(),
+		jwtVerifier:    jwtVerifier,
 		captcha:        captcha,
+		tenants:        tenants,
+		tlsReloader:    tlsReloader,
+		adminTLS:       adminTLSReloader,
+		tracer:         tracer,
+		healthChecker:  healthChecker,
 	}, nil
 }
 
@@ -363,19 +636,20 @@ func (a *App) Start(ctx context.Context) error {
 	svcCtx.DynamicConfig = a.dynamicCfg
 	svcCtx.Logger = a.logger
 	svcCtx.Captcha = a.captcha
+	svcCtx.Tenants = a.tenants
+	svcCtx.HealthChecker = a.healthChecker
+	svcCtx.Audit = a.audit
+	svcCtx.Webhooks = a.webhooks
 
-	// Start HTTP server
-	if cfg.HTTPPort != "" && cfg.HTTPPort != "0" {
-		if err = a.startHTTPServer(svcCtx, &cfg); err != nil {
-			return err
-		}
+	// Start gRPC server, the HTTP server serves it in the single port mode
+	if err = a.startGRPCServer(svcCtx, &cfg); err != nil {
+		return err
 	}
+	go a.watchGRPCHealth(ctx)
 
-	// Start gRPC server
-	if cfg.GRPCPort != "" && cfg.GRPCPort != "0" {
-		if err = a.startGRPCServer(svcCtx, &cfg); err != nil {
-			return err
-		}
+	// Start HTTP server
+	if err = a.startHTTPServer(svcCtx, &cfg); err != nil {
+		return err
 	}
 
 	return nil
@@ -386,7 +660,7 @@ func (a *App) startDiscoveryRegister(ctx context.Context, cfg *config.Config) er
 	var instanceID string
 	if a.discovery != nil {
 		instanceID = uuid.New().String()
-		if err := a.discovery.Register(ctx, cfg.ServiceName, instanceID, "localhost", cfg.HTTPPort, cfg.GRPCPort); err != nil {
+		if err := a.discovery.Register(ctx, cfg.ServiceName, instanceID, "localhost", listener.Port(cfg.GetHTTPAddr()), listener.Port(cfg.GetGRPCAddr())); err != nil {
 			return fmt.Errorf("failed to register service: %v", err)
 		}
 		go a.watchServiceDiscoveryInstances(ctx, instanceID)
@@ -394,9 +668,10 @@ func (a *App) startDiscoveryRegister(ctx context.Context, cfg *config.Config) er
 	return nil
 }
 
-// startHTTPServer start HTTP server
-func (a *App) startHTTPServer(svcCtx *common.SvcContext, cfg *config.Config) error {
-	handlers := server.NewHTTPHandlers(svcCtx)
+// httpMiddlewares returns the middleware chain of the HTTP routes.
+// The admin listener verifies the client certificates in the handshake.
+func (a *App) httpMiddlewares(admin bool) []middleware.HTTPMiddleware {
+	logger := a.logs.Component(config.LogComponentHTTP)
 	var middlewares = make([]middleware.HTTPMiddleware, 0)
 
 	// Enable cross-domain resource
@@ -405,64 +680,283 @@ func (a *App) startHTTPServer(svcCtx *common.SvcContext, cfg *config.Config) err
 	//}
 
 	middlewares = append(middlewares,
-		middleware.CORSMiddleware(a.dynamicCfg, a.logger),
-		middleware.APIKeyMiddleware(a.dynamicCfg, a.logger),
-		middleware.LoggingMiddleware(a.logger),
-		middleware.RateLimitMiddleware(a.limiter, a.logger),
-		middleware.CircuitBreakerMiddleware(a.cacheBreaker, a.logger),
+		middleware.RequestIDMiddleware(),
+		middleware.TracingMiddleware(),
+		middleware.LoggingMiddleware(logger, a.dynamicCfg),
+		middleware.AuditMiddleware(a.audit, a.dynamicCfg, logger),
+		middleware.IPFilterMiddleware(a.ipFilter, logger),
+		middleware.BodyLimitMiddleware(a.dynamicCfg),
 	)
+	if !admin {
+		middlewares = append(middlewares, middleware.ClientCertMiddleware(a.dynamicCfg, logger))
+	}
+	middlewares = append(middlewares,
+		middleware.CORSMiddleware(a.dynamicCfg, logger),
+		middleware.JWTAuthMiddleware(a.jwtVerifier, a.dynamicCfg, logger),
+		middleware.SignatureAuthMiddleware(a.sigVerifier, a.dynamicCfg, logger),
+		middleware.APIKeyMiddleware(a.dynamicCfg, logger),
+		middleware.APIKeyIPFilterMiddleware(a.ipFilter, logger),
+		middleware.TenantMiddleware(a.dynamicCfg),
+		middleware.RateLimitMiddleware(a.limiter, logger),
+		middleware.KeyedRateLimitMiddleware(a.keyedLimiter, logger),
+		middleware.CircuitBreakerMiddleware(a.cacheBreaker, logger),
+	)
+	return middlewares
+}
+
+// startHTTPServer start HTTP server, the management routes are served by the admin listener when it is enabled
+func (a *App) startHTTPServer(svcCtx *common.SvcContext, cfg *config.Config) error {
+	handlers := server.NewHTTPHandlers(svcCtx)
+	mwChain := middleware.NewChainHTTP(a.httpMiddlewares(false)...)
+
+	mux := http.NewServeMux()
+	mux.Handle("/status/health", mwChain.Then(handlers.HealthStatusHandler))
+	mux.Handle("/status/live", mwChain.Then(handlers.LivenessHandler))
+	mux.Handle("/status/ready", mwChain.Then(handlers.ReadinessHandler))
+
+	mux.Handle("/api/v1/public/get-data", mwChain.Then(handlers.GetDataHandler))
+	mux.Handle("/api/v1/public/check-data", mwChain.Then(handlers.CheckDataHandler))
+	mux.Handle("/api/v1/public/check-status", mwChain.Then(handlers.CheckStatusHandler))
+
+	// The JSON transcoding of the google.api.http annotations calls the gRPC services in process
+	gwMux := gateway.NewServeMux()
+	if err := proto.RegisterGoCaptchaServiceHandlerServer(context.Background(), gwMux, server.NewGoCaptchaServer(svcCtx)); err != nil {
+		return fmt.Errorf("failed to register gateway: %v", err)
+	}
+	if err := protov2.RegisterGoCaptchaServiceHandlerServer(context.Background(), gwMux, server.NewGoCaptchaServerV2(svcCtx)); err != nil {
+		return fmt.Errorf("failed to register gateway: %v", err)
+	}
+	mux.Handle("/api/v1/rpc/", mwChain.Then(gwMux.ServeHTTP))
+	mux.Handle("/api/v2/public/", mwChain.Then(gwMux.ServeHTTP))
+
+	adminMux := mux
+	adminChain := mwChain
+	if cfg.Admin.Enable {
+		adminMux = http.NewServeMux()
+		adminChain = middleware.NewChainHTTP(a.httpMiddlewares(true)...)
+		adminMux.Handle("/status/health", adminChain.Then(handlers.HealthStatusHandler))
+		adminMux.Handle("/status/live", adminChain.Then(handlers.LivenessHandler))
+		adminMux.Handle("/status/ready", adminChain.Then(handlers.ReadinessHandler))
+	}
+
+	adminMux.Handle(config.AuthRateLimitPath, adminChain.Then(middleware.RateLimitHandler(a.limiter, a.logger)))
+	adminMux.Handle("/api/v1/manage/get-status-info", adminChain.Then(handlers.GetStatusInfoHandler))
+	adminMux.Handle("/api/v1/manage/del-status-info", adminChain.Then(handlers.DelStatusInfoHandler))
+	adminMux.Handle("/api/v1/manage/upload-resource", adminChain.Then(handlers.UploadResourceHandler))
+	adminMux.Handle("/api/v1/manage/delete-resource", adminChain.Then(handlers.DeleteResourceHandler))
+	adminMux.Handle("/api/v1/manage/get-resource-list", adminChain.Then(handlers.GetResourceListHandler))
+	adminMux.Handle("/api/v1/manage/get-config", adminChain.Then(handlers.GetGoCaptchaConfigHandler))
+	adminMux.Handle("/api/v1/manage/update-hot-config", adminChain.Then(handlers.UpdateHotGoCaptchaConfigHandler))
+	adminMux.Handle("/api/v1/manage/get-tenant-stats", adminChain.Then(handlers.GetTenantStatsHandler))
+	adminMux.Handle("/api/v1/manage/get-audit-log", adminChain.Then(handlers.GetAuditLogHandler))
+	adminMux.Handle("/api/v1/manage/get-webhook-status", adminChain.Then(handlers.GetWebhookStatusHandler))
+	adminMux.Handle("/api/v1/manage/rpc/", adminChain.Then(gwMux.ServeHTTP))
+
+	// The debug endpoints are trusted on the admin listener, the public listener requires the debug scope
+	debugHandlers := server.NewDebugHandlers(svcCtx)
+	debugMiddlewares := append(a.httpMiddlewares(cfg.Admin.Enable), middleware.DebugMiddleware(a.dynamicCfg, a.logs.Component(config.LogComponentHTTP), !cfg.Admin.Enable))
+	debugChain := middleware.NewChainHTTP(debugMiddlewares...)
+	adminMux.Handle("/debug/pprof/", debugChain.Then(pprof.Index))
+	adminMux.Handle("/debug/pprof/cmdline", debugChain.Then(pprof.Cmdline))
+	adminMux.Handle("/debug/pprof/profile", debugChain.Then(pprof.Profile))
+	adminMux.Handle("/debug/pprof/symbol", debugChain.Then(pprof.Symbol))
+	adminMux.Handle("/debug/pprof/trace", debugChain.Then(pprof.Trace))
+	adminMux.Handle("/debug/goroutines", debugChain.Then(debugHandlers.GoroutineDumpHandler))
+	adminMux.Handle("/debug/runtime-stats", debugChain.Then(debugHandlers.RuntimeStatsHandler))
+	adminMux.Handle("/debug/capture-profile", debugChain.Then(debugHandlers.CaptureProfileHandler))
+
+	a.httpServer = newHTTPServer(cfg, a.multiplex(cfg, a.grpcServer, a.tlsReloader, mux))
+	if err := a.serveHTTP("HTTP server", a.httpServer, cfg.GetHTTPAddr(), cfg.GetUnixSocketMode(), a.tlsReloader); err != nil {
+		return err
+	}
+
+	if cfg.Admin.Enable {
+		a.adminHTTP = newHTTPServer(cfg, a.multiplex(cfg, a.adminGRPC, a.adminTLS, adminMux))
+		if err := a.serveHTTP("admin HTTP server", a.adminHTTP, cfg.Admin.GetHTTPAddr(), cfg.GetUnixSocketMode(), a.adminTLS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	mwChain := middleware.NewChainHTTP(middlewares...)
+// newHTTPServer creates an HTTP server with the timeouts and the header limit, the slow clients cannot hold the connections
+func newHTTPServer(cfg *config.Config, handler http.Handler) *http.Server {
+	limits := cfg.GetServerLimits()
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(limits.ReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(limits.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(limits.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(limits.IdleTimeout) * time.Second,
+		MaxHeaderBytes:    limits.MaxHeaderBytes,
+	}
+}
 
-	http.Handle("/status/health", mwChain.Then(handlers.HealthStatusHandler))
-	http.Handle("/rate-limit", mwChain.Then(middleware.RateLimitHandler(a.limiter, a.logger)))
+// multiplex serves the gRPC server and gRPC-Web on the HTTP listener in the single port mode, or only gRPC-Web when it is enabled
+func (a *App) multiplex(cfg *config.Config, grpcServer *grpc.Server, tlsReloader *tlsconfig.Reloader, handler http.Handler) http.Handler {
+	if !cfg.SinglePort && !cfg.EnableGRPCWeb {
+		return handler
+	}
 
-	http.Handle("/api/v1/public/get-data", mwChain.Then(handlers.GetDataHandler))
-	http.Handle("/api/v1/public/check-data", mwChain.Then(handlers.CheckDataHandler))
-	http.Handle("/api/v1/public/check-status", mwChain.Then(handlers.CheckStatusHandler))
+	// Browsers send the preflight requests of gRPC-Web
+	webHandler := middleware.NewChainHTTP(middleware.CORSMiddleware(a.dynamicCfg, a.logger)).Then(middleware.HandlerFunc(grpcmux.GRPCWebHandler(grpcServer)))
+	if !cfg.SinglePort {
+		return grpcmux.NewWebMux(grpcServer, webHandler, handler)
+	}
 
-	http.Handle("/api/v1/manage/get-status-info", mwChain.Then(handlers.GetStatusInfoHandler))
-	http.Handle("/api/v1/manage/del-status-info", mwChain.Then(handlers.DelStatusInfoHandler))
-	http.Handle("/api/v1/manage/upload-resource", mwChain.Then(handlers.UploadResourceHandler))
-	http.Handle("/api/v1/manage/delete-resource", mwChain.Then(handlers.DeleteResourceHandler))
-	http.Handle("/api/v1/manage/get-resource-list", mwChain.Then(handlers.GetResourceListHandler))
-	http.Handle("/api/v1/manage/get-config", mwChain.Then(handlers.GetGoCaptchaConfigHandler))
-	http.Handle("/api/v1/manage/update-hot-config", mwChain.Then(handlers.UpdateHotGoCaptchaConfigHandler))
+	mux := grpcmux.NewMux(grpcServer, webHandler, handler)
+	if tlsReloader != nil {
+		return mux
+	}
+	return grpcmux.H2C(mux)
+}
 
-	a.httpServer = &http.Server{
-		Addr: ":" + cfg.HTTPPort,
+// serveHTTP listens on the address and serves the HTTP server in the background
+func (a *App) serveHTTP(name string, srv *http.Server, addr string, mode os.FileMode, tlsReloader *tlsconfig.Reloader) error {
+	lis, err := listener.Listen(addr, mode)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+	if tlsReloader != nil {
+		lis = tls.NewListener(lis, tlsReloader.ServerConfig("h2", "http/1.1"))
 	}
 
 	go func() {
-		a.logger.Info("[App] Starting HTTP server", zap.String("port", cfg.HTTPPort))
-		if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			a.logger.Fatal("[App] HTTP server failed", zap.Error(err))
+		a.logger.Info("[App] Starting "+name, zap.String("addr", addr), zap.Bool("tls", tlsReloader != nil))
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			a.logger.Fatal("[App] "+name+" failed", zap.Error(err))
 		}
 	}()
-
 	return nil
 }
 
-// startGRPCServer start gRPC server
+// grpcServerOptions returns the interceptors, the limits and the credentials of a gRPC server.
+// The admin listener verifies the client certificates in the handshake.
+func (a *App) grpcServerOptions(cfg *config.Config, tlsReloader *tlsconfig.Reloader, admin bool) []grpc.ServerOption {
+	logger := a.logs.Component(config.LogComponentGRPC)
+	middlewares := []middleware.GRPCMiddleware{
+		// The received payload hashes are taken first, the unary requests are decoded before the chain
+		middleware.GRPCPayloadMiddleware(a.payloadCodec),
+		middleware.GRPCRecoveryMiddleware(logger),
+		middleware.GRPCRequestIDMiddleware(),
+		middleware.GRPCLoggingMiddleware(logger, a.dynamicCfg),
+		middleware.GRPCAuditMiddleware(a.audit, a.dynamicCfg, logger),
+		middleware.GRPCIPFilterMiddleware(a.ipFilter, logger),
+	}
+	if !admin {
+		middlewares = append(middlewares, middleware.GRPCClientCertMiddleware(a.dynamicCfg, logger))
+	}
+	middlewares = append(middlewares,
+		middleware.GRPCOriginMiddleware(a.dynamicCfg, logger),
+		middleware.GRPCJWTAuthMiddleware(a.jwtVerifier, a.dynamicCfg, logger),
+		middleware.GRPCSignatureAuthMiddleware(a.sigVerifier, a.dynamicCfg, logger),
+		middleware.GRPCAPIKeyMiddleware(a.dynamicCfg, logger),
+		middleware.GRPCAPIKeyIPFilterMiddleware(a.ipFilter, logger),
+		middleware.GRPCTenantMiddleware(a.dynamicCfg),
+		// The per-client limits run after the authentication to key the buckets by the API key id
+		middleware.GRPCRateLimitMiddleware(a.limiter, a.keyedLimiter, logger),
+		middleware.GRPCCircuitBreakerMiddleware(a.cacheBreaker, logger),
+	)
+	interceptorChain := middleware.NewChainGRPC(middlewares...)
+
+	limits := cfg.GetServerLimits()
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ForceServerCodecV2(a.payloadCodec),
+		grpc.MaxRecvMsgSize(limits.GRPCMaxRecvMsgSize),
+		grpc.MaxSendMsgSize(limits.GRPCMaxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    time.Duration(limits.GRPCKeepaliveTime) * time.Second,
+			Timeout: time.Duration(limits.GRPCKeepaliveTimeout) * time.Second,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             time.Duration(limits.GRPCKeepaliveMinTime) * time.Second,
+			PermitWithoutStream: limits.GRPCPermitWithoutStream,
+		}),
+	}
+	opts = append(opts, interceptorChain.ServerOptions()...)
+	if tlsReloader != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsReloader.ServerConfig("h2"))))
+	}
+	return opts
+}
+
+// startGRPCServer start gRPC server, the manage service is served by the admin listener when it is enabled
 func (a *App) startGRPCServer(svcCtx *common.SvcContext, cfg *config.Config) error {
-	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
-	if err != nil {
-		return fmt.Errorf("failed to listen: %v", err)
+	a.grpcHealth = grpchealth.NewServer()
+
+	// The HTTP listener terminates TLS in the single port mode
+	tlsReloader, adminTLS := a.tlsReloader, a.adminTLS
+	if cfg.SinglePort {
+		tlsReloader, adminTLS = nil, nil
 	}
 
-	interceptor := middleware.UnaryServerInterceptor(a.dynamicCfg, a.logger, a.cacheBreaker)
-	a.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+	a.grpcServer = grpc.NewServer(a.grpcServerOptions(cfg, tlsReloader, false)...)
 	proto.RegisterGoCaptchaServiceServer(a.grpcServer, server.NewGoCaptchaServer(svcCtx))
+	protov2.RegisterGoCaptchaServiceServer(a.grpcServer, server.NewGoCaptchaServerV2(svcCtx))
+	healthpb.RegisterHealthServer(a.grpcServer, a.grpcHealth)
+
+	manageServer := a.grpcServer
+	if cfg.Admin.Enable {
+		a.adminGRPC = grpc.NewServer(a.grpcServerOptions(cfg, adminTLS, true)...)
+		healthpb.RegisterHealthServer(a.adminGRPC, a.grpcHealth)
+		manageServer = a.adminGRPC
+	}
+	proto.RegisterGoCaptchaManageServiceServer(manageServer, server.NewGoCaptchaManageServer(svcCtx))
+
+	if cfg.SinglePort {
+		return nil
+	}
+	if err := a.serveGRPC("gRPC server", a.grpcServer, cfg.GetGRPCAddr(), cfg.GetUnixSocketMode(), a.tlsReloader != nil); err != nil {
+		return err
+	}
+	if cfg.Admin.Enable {
+		if err := a.serveGRPC("admin gRPC server", a.adminGRPC, cfg.Admin.GetGRPCAddr(), cfg.GetUnixSocketMode(), a.adminTLS != nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serveGRPC listens on the address and serves the gRPC server in the background
+func (a *App) serveGRPC(name string, srv *grpc.Server, addr string, mode os.FileMode, useTLS bool) error {
+	lis, err := listener.Listen(addr, mode)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
 
 	go func() {
-		a.logger.Info("[App] Starting gRPC server", zap.String("port", cfg.GRPCPort))
-		if err := a.grpcServer.Serve(lis); err != nil && err != grpc.ErrServerStopped {
-			a.logger.Fatal("[App] gRPC server failed", zap.Error(err))
+		a.logger.Info("[App] Starting "+name, zap.String("addr", addr), zap.Bool("tls", useTLS))
+		if err := srv.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			a.logger.Fatal("[App] "+name+" failed", zap.Error(err))
 		}
 	}()
 	return nil
 }
 
+// watchGRPCHealth periodically updates the gRPC health serving status from the readiness checks
+func (a *App) watchGRPCHealth(ctx context.Context) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if report := a.healthChecker.Readiness(ctx); !report.Healthy() {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		a.grpcHealth.SetServingStatus("", status)
+		a.grpcHealth.SetServingStatus(proto.GoCaptchaService_ServiceDesc.ServiceName, status)
+		a.grpcHealth.SetServingStatus(protov2.GoCaptchaService_ServiceDesc.ServiceName, status)
+		a.grpcHealth.SetServingStatus(proto.GoCaptchaManageService_ServiceDesc.ServiceName, status)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // watchServiceDiscoveryInstances periodically updates service instances
 func (a *App) watchServiceDiscoveryInstances(ctx context.Context, instanceID string) {
 	if a.discovery == nil {
@@ -500,7 +994,9 @@ func (a *App) Shutdown() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	defer a.logger.Sync()
+	defer a.logs.Close()
+	defer a.audit.Close()
+	defer a.webhooks.Close()
 
 	// Stop HTTP server
 	if a.httpServer != nil {
@@ -511,11 +1007,36 @@ func (a *App) Shutdown() {
 		}
 	}
 
+	if a.adminHTTP != nil {
+		if err := a.adminHTTP.Shutdown(ctx); err != nil {
+			a.logger.Error("[App] Admin HTTP server shutdown error", zap.Error(err))
+		} else {
+			a.logger.Info("[App] Admin HTTP server shut down successfully")
+		}
+	}
+
 	// Stop gRPC server
+	if a.grpcHealth != nil {
+		a.grpcHealth.Shutdown()
+	}
 	if a.grpcServer != nil {
 		a.grpcServer.GracefulStop()
 		a.logger.Info("[App] gRPC server shut down successfully")
 	}
+	if a.adminGRPC != nil {
+		a.adminGRPC.GracefulStop()
+		a.logger.Info("[App] Admin gRPC server shut down successfully")
+	}
+
+	// Stop watching the TLS certificates
+	for _, reloader := range []*tlsconfig.Reloader{a.tlsReloader, a.adminTLS} {
+		if reloader == nil {
+			continue
+		}
+		if err := reloader.Close(); err != nil {
+			a.logger.Error("[App] TLS certificate watcher close error", zap.Error(err))
+		}
+	}
 
 	// Stop cache
 	err := a.cacheMgr.Close()
@@ -543,5 +1064,14 @@ func (a *App) Shutdown() {
 		}
 	}
 
+	// Stop tracing
+	if a.tracer != nil {
+		if err = a.tracer.Shutdown(ctx); err != nil {
+			a.logger.Error("[App] Tracing provider shutdown error", zap.Error(err))
+		} else {
+			a.logger.Info("[App] Tracing provider shut down successfully")
+		}
+	}
+
 	a.logger.Info("[App] App service shutdown")
 }
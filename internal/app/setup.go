@@ -2,21 +2,34 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/health"
+	"github.com/wenlng/go-captcha-service/internal/listener"
+	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
 	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
+	"github.com/wenlng/go-captcha-service/internal/webhook"
 	"github.com/wenlng/go-service-link/dynaconfig"
 	"github.com/wenlng/go-service-link/dynaconfig/provider"
 	"github.com/wenlng/go-service-link/foundation/common"
 	"github.com/wenlng/go-service-link/servicediscovery"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // setupServiceDiscovery ..
@@ -83,12 +96,12 @@ func setupServiceDiscovery(dCfg *config.DynamicConfig, logger *zap.Logger) (serv
 }
 
 // setupDynamicConfig ..
-func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.DynamicCaptchaConfig, logger *zap.Logger) (*dynaconfig.ConfigManager, error) {
+func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.DynamicCaptchaConfig, logger *zap.Logger) (*dynaconfig.ConfigManager, *health.ConfigManagerStatus, error) {
 	appCfg := appDynaCfg.Get()
 	captCfg := appDynaCfg.Get()
 
 	if !appCfg.EnableDynamicConfig {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	appConfigKey := "/config/go-captcha-service/app-config"
@@ -164,10 +177,13 @@ func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.D
 		Configs:        configs,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create config manager, err: %v ", err)
+		return nil, nil, fmt.Errorf("failed to create config manager, err: %v ", err)
 	}
 
+	// The readiness check follows the health checks of the manager on the provider serving the config
+	status := &health.ConfigManagerStatus{}
 	manager.SetOutputLogCallback(func(logType dynaconfig.OutputLogType, message string) {
+		status.Observe(message)
 		if logType == dynaconfig.OutputLogTypeError {
 			logger.Error("[AppSetup] " + message)
 		} else if logType == dynaconfig.OutputLogTypeWarn {
@@ -247,7 +263,7 @@ func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.D
 	})
 
 	captDynaCfg.RegisterHotCallback("ASYNC_CAPTCHA_CONFIG", func(captchaConfig *config2.DynamicCaptchaConfig, callbackType config2.HotCallbackType) {
-		if callbackType == config2.HotCallbackTypeLocalConfigFile {
+		if callbackType == config2.HotCallbackTypeLocalConfigFile || callbackType == config2.HotCallbackTypeManageAPI {
 			manager.RefreshConfig(context.Background(), captConfigKey, &provider.Config{
 				Name:    captConfigName,
 				Version: captDynaCfg.Get().ConfigVersion,
@@ -265,7 +281,7 @@ func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.D
 	manager.ASyncConfig(context.Background())
 
 	if err = manager.Watch(); err != nil {
-		return nil, fmt.Errorf("failed to start watch: %v ", err)
+		return nil, nil, fmt.Errorf("failed to start watch: %v ", err)
 	}
 
 	//////////////////////// testing /////////////////////////
@@ -283,44 +299,151 @@ func setupDynamicConfig(appDynaCfg *config.DynamicConfig, captDynaCfg *config2.D
 	//}()
 	/////////////////////////////////////////////////
 
-	return manager, nil
+	return manager, status, nil
 }
 
-// setupLoggerLevel setting the log Level
-func setupLoggerLevel(logger *zap.Logger, level string) {
-	switch level {
-	case "error":
-		logger.WithOptions(zap.IncreaseLevel(zap.ErrorLevel))
-		break
-	case "debug":
-		logger.WithOptions(zap.IncreaseLevel(zap.DebugLevel))
-		break
-	case "warn":
-		logger.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
-		break
-	case "info":
-		logger.WithOptions(zap.IncreaseLevel(zap.InfoLevel))
-		break
+// setupHealthCheck performs the readiness checks of a running instance through its HTTP and gRPC servers
+func setupHealthCheck(httpAddr, grpcAddr string, useTLS bool, timeout time.Duration) error {
+	// The probe targets this instance, the certificate name is not checked
+	var tlsConfig *tls.Config
+	if useTLS {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
 	}
-}
 
-// setupHealthCheck performs a health check on HTTP and gRPC servers
-func setupHealthCheck(httpAddr, grpcAddr string) error {
-	resp, err := http.Get("http://localhost" + httpAddr + "/status/health")
-	if err != nil || resp.StatusCode != http.StatusNotFound {
+	if err := checkHTTPReady(httpAddr, tlsConfig, timeout); err != nil {
 		return fmt.Errorf("HTTP health check failed: %v", err)
 	}
-	resp.Body.Close()
+	if err := checkGRPCServing(grpcAddr, tlsConfig, timeout); err != nil {
+		return fmt.Errorf("gRPC health check failed: %v", err)
+	}
+	return nil
+}
 
-	conn, err := net.DialTimeout("tcp", "localhost"+grpcAddr, 1*time.Second)
+// checkHTTPReady requests the readiness route of the HTTP server
+func checkHTTPReady(addr string, tlsConfig *tls.Config, timeout time.Duration) error {
+	network, address, err := probeAddress(addr)
 	if err != nil {
-		return fmt.Errorf("gRPC health check failed: %v", err)
+		return err
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	host := address
+	if network == listener.SchemeUnix {
+		host = "localhost"
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		}
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	client := &http.Client{Timeout: timeout, Transport: transport}
+	resp, err := client.Get(scheme + "://" + host + "/status/ready")
+	if err != nil {
+		return err
 	}
-	conn.Close()
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status: %d, body: %s", resp.StatusCode, string(body))
+	}
 	return nil
 }
 
+// checkGRPCServing calls the health service of the gRPC server
+func checkGRPCServing(addr string, tlsConfig *tls.Config, timeout time.Duration) error {
+	network, address, err := probeAddress(addr)
+	if err != nil {
+		return err
+	}
+	target := address
+	if network == listener.SchemeUnix {
+		target = "unix://" + address
+	}
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("status: %v", resp.GetStatus())
+	}
+	return nil
+}
+
+// probeAddress returns the address dialed by the health check, the listeners on all interfaces are reached through localhost
+func probeAddress(addr string) (string, string, error) {
+	network, address, err := listener.Parse(addr)
+	if err != nil || network != listener.SchemeTCP {
+		return network, address, err
+	}
+	host, port, _ := net.SplitHostPort(address)
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return network, net.JoinHostPort(host, port), nil
+}
+
+// setupHealthChecker registers the liveness and readiness checks
+func setupHealthChecker(dCfg *config.DynamicConfig, cacheMgr *cache.CacheManager, captcha *gocaptcha.GoCaptcha, configStatus *health.ConfigManagerStatus) *health.Checker {
+	cfg := dCfg.Get()
+	checker := health.NewChecker(time.Duration(cfg.HealthCheckTimeout) * time.Second)
+
+	checker.AddReadinessCheck("cache", health.CacheRoundTripCheck(cacheMgr))
+	checker.AddReadinessCheck("captcha", health.CaptchaInstancesCheck(captcha))
+	checker.AddReadinessCheck("generate_queue", func(ctx context.Context) error {
+		return health.GenerateQueueCheck(captcha.GenerateQueue, dCfg.Get().ReadinessQueueThreshold)(ctx)
+	})
+	if configStatus != nil {
+		checker.AddReadinessCheck("dynamic_config", health.ConfigManagerCheck(configStatus))
+	}
+
+	return checker
+}
+
+// setupTracing ..
+func setupTracing(dCfg *config.DynamicConfig, logger *zap.Logger) (*tracing.Provider, error) {
+	cfg := dCfg.Get()
+	if !cfg.EnableTracing {
+		return nil, nil
+	}
+
+	sampleRatio := cfg.GetTracingSampleRatio()
+
+	provider, err := tracing.NewProvider(context.Background(), &tracing.Params{
+		ServiceName: cfg.ServiceName,
+		ServiceNode: cfg.ServiceNode,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		Headers:     cfg.TracingHeaders,
+		SampleRatio: sampleRatio,
+		FilePath:    cfg.TracingFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %v", err)
+	}
+
+	logger.Info("[AppSetup] Tracing enabled", zap.String("exporter", cfg.TracingExporter), zap.Float64("sample_ratio", sampleRatio))
+	return provider, nil
+}
+
 // setupCacheManager ...
 func setupCacheManager(dcfg *config.DynamicConfig, logger *zap.Logger) (*cache.CacheManager, error) {
 	cfg := dcfg.Get()
@@ -360,3 +483,58 @@ func setupCacheManager(dcfg *config.DynamicConfig, logger *zap.Logger) (*cache.C
 
 	return cacheMgr, err
 }
+
+// setupConfigChangeHooks records the config changes of the file watchers and the remote config center to the audit log
+// and notifies the webhooks, the unchanged reloads are skipped
+func setupConfigChangeHooks(auditLog *audit.Log, webhooks *webhook.Dispatcher, dc *config.DynamicConfig, dgc *config2.DynamicCaptchaConfig, logger *zap.Logger) {
+	record := func(action, target string, remote bool, change *audit.Change) {
+		if change.Before == change.After {
+			return
+		}
+		entry := audit.Entry{
+			Actor:     audit.ActorFileWatcher,
+			Transport: audit.TransportFile,
+			Action:    action,
+			Target:    target,
+			Change:    change,
+			Result:    audit.ResultSuccess,
+		}
+		if remote {
+			entry.Actor = audit.ActorRemote
+			entry.Transport = audit.TransportRemote
+		}
+		if err := auditLog.Record(entry); err != nil {
+			logger.Error("[AppSetup] Failed to record the audit entry", zap.String("action", action), zap.Error(err))
+		}
+		webhooks.Emit(config.WebhookEventConfigUpdated, "", map[string]any{
+			"target": target,
+			"source": entry.Transport,
+			"actor":  entry.Actor,
+			"fields": change.Fields,
+			"before": change.Before,
+			"after":  change.After,
+		})
+	}
+
+	var mu sync.Mutex
+	appCfg := dc.Get()
+	dc.RegisterHotCallback("AUDIT_APP_CONFIG", func(dnCfg *config.DynamicConfig, hotType config.HotCallbackType) {
+		mu.Lock()
+		defer mu.Unlock()
+		cfg := dnCfg.Get()
+		record("reload-config", "app-config", hotType == config.HotCallbackTypeRemoteConfig, audit.Diff(appCfg, cfg))
+		appCfg = cfg
+	})
+
+	captCfg := dgc.Get()
+	dgc.RegisterHotCallback("AUDIT_CAPTCHA_CONFIG", func(captchaConfig *config2.DynamicCaptchaConfig, hotType config2.HotCallbackType) {
+		mu.Lock()
+		defer mu.Unlock()
+		cfg := captchaConfig.Get()
+		// The manage API updates are recorded by the audit middlewares
+		if hotType != config2.HotCallbackTypeManageAPI {
+			record("reload-config", "gocaptcha-config", hotType == config2.HotCallbackTypeRemoteConfig, audit.Diff(captCfg, cfg))
+		}
+		captCfg = cfg
+	})
+}
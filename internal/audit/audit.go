@@ -0,0 +1,264 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+// Transports of the audited changes
+const (
+	TransportHTTP   = "http"
+	TransportGRPC   = "grpc"
+	TransportFile   = "file"
+	TransportRemote = "remote"
+)
+
+// Results of the audited operations
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Actors of the changes made without API key
+const (
+	ActorAnonymous   = "anonymous"
+	ActorFileWatcher = "file-watcher"
+	ActorRemote      = "remote"
+)
+
+// DefaultQueryLimit is the number of entries returned by a query without limit
+const DefaultQueryLimit = 100
+
+// Entry is a line of the audit log
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`            // id of the API key, file-watcher or remote
+	Source    string    `json:"source,omitempty"` // client ip
+	Transport string    `json:"transport"`        // http, grpc, file, remote
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Change    *Change   `json:"change,omitempty"`
+	Result    string    `json:"result"`           // success, failure
+	Status    string    `json:"status,omitempty"` // HTTP status or gRPC code
+	Error     string    `json:"error,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// Filter selects the entries of a query, empty fields match any entry
+type Filter struct {
+	Actor  string
+	Action string
+	Target string // prefix of the target
+	Result string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// match reports whether the entry is selected by the filter
+func (f Filter) match(e Entry) bool {
+	if f.Actor != "" && e.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.Target != "" && !strings.HasPrefix(e.Target, f.Target) {
+		return false
+	}
+	if f.Result != "" && e.Result != f.Result {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Log appends the entries to a rotated JSON lines file, a nil Log records nothing
+type Log struct {
+	mu   sync.Mutex
+	file *lumberjack.Logger
+}
+
+// New opens the audit log of the settings, nil when it is disabled
+func New(cfg config.AuditLog) (*Log, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the audit log directory: %v", err)
+	}
+
+	return &Log{
+		file: &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+			LocalTime:  true,
+		},
+	}, nil
+}
+
+// Record appends the entry, the time defaults to now
+func (l *Log) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Actor == "" {
+		e.Actor = ActorAnonymous
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(line)
+	return err
+}
+
+// Query returns the entries selected by the filter, newest first.
+// The current file and the rotated ones are read.
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultQueryLimit
+	}
+
+	l.mu.Lock()
+	files, err := l.files()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	for _, name := range files {
+		fileEntries, err := readFile(name, f)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(fileEntries) - 1; i >= 0 && len(entries) < f.Limit; i-- {
+			entries = append(entries, fileEntries[i])
+		}
+		if len(entries) >= f.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Close closes the audit log file
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// files returns the current file and the rotated ones, newest first
+func (l *Log) files() ([]string, error) {
+	dir := filepath.Dir(l.file.Filename)
+	base := filepath.Base(l.file.Filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The rotated files are named by their rotation time, the names sort in time order
+	var backups []string
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasSuffix(name, ext) || strings.HasSuffix(name, ext+".gz") {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	files := make([]string, 0, len(backups)+1)
+	if _, err = os.Stat(l.file.Filename); err == nil {
+		files = append(files, l.file.Filename)
+	}
+	return append(files, backups...), nil
+}
+
+// readFile returns the entries of a file selected by the filter, oldest first
+func readFile(name string, f Filter) ([]Entry, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for scanner.Scan() {
+		var e Entry
+		// Lines cut by a crash are skipped
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if f.match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
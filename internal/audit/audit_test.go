@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+func TestLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit", "audit.log")
+	cfg := config.DefaultAuditLog()
+	cfg.File = file
+
+	l, err := New(cfg)
+	assert.NoError(t, err)
+	defer l.Close()
+
+	start := time.Now().Add(-time.Hour)
+	assert.NoError(t, l.Record(Entry{Time: start, Actor: "ops", Transport: TransportHTTP, Action: "upload-resource", Target: "bg/a.png", Result: ResultSuccess}))
+	assert.NoError(t, l.Record(Entry{Actor: "ops", Transport: TransportGRPC, Action: "delete-resource", Target: "bg/a.png", Result: ResultFailure}))
+	assert.NoError(t, l.Record(Entry{Transport: TransportHTTP, Action: "get-config", Result: ResultSuccess}))
+
+	// A rotated and compressed backup
+	backup, err := os.Create(filepath.Join(filepath.Dir(file), "audit-2025-04-04T00-00-00.000.log.gz"))
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(backup)
+	gz.Write([]byte(`{"time":"2025-04-04T00:00:00Z","actor":"file-watcher","transport":"file","action":"reload-config","result":"success"}` + "\n"))
+	gz.Close()
+	backup.Close()
+
+	entries, err := l.Query(Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 4) {
+		assert.Equal(t, "get-config", entries[0].Action)
+		assert.Equal(t, ActorAnonymous, entries[0].Actor)
+		assert.Equal(t, "reload-config", entries[3].Action)
+	}
+
+	entries, _ = l.Query(Filter{Actor: "ops"})
+	assert.Len(t, entries, 2)
+	entries, _ = l.Query(Filter{Target: "bg/", Result: ResultFailure})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "delete-resource", entries[0].Action)
+	}
+	entries, _ = l.Query(Filter{Since: start.Add(time.Minute)})
+	assert.Len(t, entries, 2)
+	entries, _ = l.Query(Filter{Until: start.Add(time.Minute)})
+	assert.Len(t, entries, 2)
+	entries, _ = l.Query(Filter{Limit: 1})
+	assert.Len(t, entries, 1)
+
+	// Disabled
+	l2, err := New(config.AuditLog{})
+	assert.NoError(t, err)
+	assert.Nil(t, l2)
+	assert.NoError(t, l2.Record(Entry{}))
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{"click": map[string]any{"version": 1, "size": 10}, "slide": 1}
+	after := map[string]any{"click": map[string]any{"version": 2, "size": 10}, "slide": 1, "rotate": true}
+
+	change := Diff(before, after)
+	assert.NotEqual(t, change.Before, change.After)
+	assert.Equal(t, []string{"click.version", "rotate"}, change.Fields)
+
+	change = Diff(before, before)
+	assert.Equal(t, change.Before, change.After)
+	assert.Empty(t, change.Fields)
+}
+
+func TestHolder(t *testing.T) {
+	// Without holder
+	SetTarget(context.Background(), "bg")
+	target, change := FromContext(context.Background())
+	assert.Empty(t, target)
+	assert.Nil(t, change)
+
+	ctx := WithHolder(context.Background())
+	SetTarget(ctx, "bg")
+	SetChange(ctx, &Change{Before: Hash([]byte("a"))})
+	target, change = FromContext(WithHolder(ctx))
+	assert.Equal(t, "bg", target)
+	assert.Equal(t, Hash([]byte("a")), change.Before)
+}
@@ -0,0 +1,163 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Change is the hash of the content before and after a change, with the changed fields of a config
+type Change struct {
+	Before string            `json:"before,omitempty"` // sha256 of the previous content
+	After  string            `json:"after,omitempty"`  // sha256 of the new content
+	Fields []string          `json:"fields,omitempty"` // changed fields of a config, dot separated
+	Files  map[string]string `json:"files,omitempty"`  // sha256 of the uploaded files
+}
+
+// Hash returns the sha256 of the data
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// HashFile returns the sha256 of the file, empty when it cannot be read
+func HashFile(name string) string {
+	file, err := os.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Hasher hashes the data written to it
+type Hasher struct {
+	h hash.Hash
+}
+
+// NewHasher returns a sha256 writer, Sum gives the hash in the format of Hash
+func NewHasher() *Hasher {
+	return &Hasher{h: sha256.New()}
+}
+
+// Write .
+func (h *Hasher) Write(p []byte) (int, error) {
+	return h.h.Write(p)
+}
+
+// Sum returns the sha256 of the data written
+func (h *Hasher) Sum() string {
+	return "sha256:" + hex.EncodeToString(h.h.Sum(nil))
+}
+
+// Diff returns the hashes of the JSON encodings of the configs and their changed fields
+func Diff(before, after any) *Change {
+	b, _ := json.Marshal(before)
+	a, _ := json.Marshal(after)
+
+	var bm, am any
+	_ = json.Unmarshal(b, &bm)
+	_ = json.Unmarshal(a, &am)
+
+	var fields []string
+	diffFields("", bm, am, &fields)
+	sort.Strings(fields)
+
+	return &Change{Before: Hash(b), After: Hash(a), Fields: fields}
+}
+
+// diffFields appends the paths of the leaves that differ
+func diffFields(prefix string, before, after any, fields *[]string) {
+	bm, bok := before.(map[string]any)
+	am, aok := after.(map[string]any)
+	if !bok || !aok {
+		if !reflect.DeepEqual(before, after) {
+			*fields = append(*fields, prefix)
+		}
+		return
+	}
+
+	keys := make(map[string]struct{}, len(bm)+len(am))
+	for k := range bm {
+		keys[k] = struct{}{}
+	}
+	for k := range am {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		diffFields(name, bm[k], am[k], fields)
+	}
+}
+
+// detailKey .
+type detailKey struct{}
+
+// detailHolder lets the audit middlewares read the target and the change set by the handlers
+type detailHolder struct {
+	mu     sync.Mutex
+	target string
+	change *Change
+}
+
+// WithHolder adds a holder for the target and the change of the operation to the context
+func WithHolder(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(detailKey{}).(*detailHolder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, detailKey{}, &detailHolder{})
+}
+
+// SetTarget records the target of the operation, it does nothing without a holder
+func SetTarget(ctx context.Context, target string) {
+	holder, ok := ctx.Value(detailKey{}).(*detailHolder)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	holder.target = target
+	holder.mu.Unlock()
+}
+
+// SetChange records the change made by the operation, it does nothing without a holder
+func SetChange(ctx context.Context, change *Change) {
+	holder, ok := ctx.Value(detailKey{}).(*detailHolder)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	holder.change = change
+	holder.mu.Unlock()
+}
+
+// FromContext returns the target and the change recorded for the operation
+func FromContext(ctx context.Context) (string, *Change) {
+	holder, ok := ctx.Value(detailKey{}).(*detailHolder)
+	if !ok {
+		return "", nil
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.target, holder.change
+}
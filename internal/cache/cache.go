@@ -28,9 +28,38 @@ type Cache interface {
 	GetCache(ctx context.Context, key string) (string, error)
 	SetCache(ctx context.Context, key, value string) error
 	DeleteCache(ctx context.Context, key string) error
+	// CompareAndSwap atomically replaces the value of the key when it still holds old, and reports whether it did
+	CompareAndSwap(ctx context.Context, key, old, new string) (bool, error)
 	Close() error
 }
 
+// RateLimitStore is implemented by the caches that can enforce a rate limit shared by all nodes.
+// AllowRate takes a token from every key atomically, nothing is consumed when one of them is empty.
+// The keys of one call must share the part before their last "|", the caches may keep them together by it.
+type RateLimitStore interface {
+	AllowRate(ctx context.Context, keys []string, qps float64, burst int) (retryAfter time.Duration, ok bool, err error)
+}
+
+// RateLimitKeyPrefix is prepended to the rate limit keys after the cache key prefix
+const RateLimitKeyPrefix = "RATE_LIMIT:"
+
+// NonceStore is implemented by the caches that can record request nonces to reject replays.
+// AddNonce stores the key for the ttl and reports false when it was already present.
+type NonceStore interface {
+	AddNonce(ctx context.Context, key string, ttl time.Duration) (added bool, err error)
+}
+
+// Every backend records the nonces, so the replays are rejected across the nodes sharing it
+var (
+	_ NonceStore = (*RedisClient)(nil)
+	_ NonceStore = (*EtcdClient)(nil)
+	_ NonceStore = (*MemcacheClient)(nil)
+	_ NonceStore = (*MemoryCache)(nil)
+)
+
+// NonceKeyPrefix is prepended to the nonce keys after the cache key prefix
+const NonceKeyPrefix = "NONCE:"
+
 // CaptCacheData ..
 type CaptCacheData struct {
 	Data   interface{} `json:"data"`
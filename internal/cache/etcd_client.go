@@ -9,11 +9,17 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 )
 
 // EtcdClient implements the Cache interface for etcd
@@ -21,6 +27,15 @@ type EtcdClient struct {
 	client *clientv3.Client
 	prefix string
 	ttl    time.Duration
+
+	leaseMu      sync.Mutex
+	windowLeases map[int64]etcdWindowLease
+}
+
+// etcdWindowLease is a lease shared by the rate limit and nonce keys written during its window
+type etcdWindowLease struct {
+	id      clientv3.LeaseID
+	expires time.Time
 }
 
 // NewEtcdClient ..
@@ -38,7 +53,11 @@ func NewEtcdClient(addrs, prefix string, ttl time.Duration, username, password s
 }
 
 // GetCache retrieves a value from etcd
-func (c *EtcdClient) GetCache(ctx context.Context, key string) (string, error) {
+func (c *EtcdClient) GetCache(ctx context.Context, key string) (val string, err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.GetCache", attribute.String("db.system", "etcd"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
 	key = c.prefix + key
 	resp, err := c.client.Get(ctx, key)
 	if err != nil {
@@ -51,7 +70,11 @@ func (c *EtcdClient) GetCache(ctx context.Context, key string) (string, error) {
 }
 
 // SetCache stores a value in etcd
-func (c *EtcdClient) SetCache(ctx context.Context, key, value string) error {
+func (c *EtcdClient) SetCache(ctx context.Context, key, value string) (err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.SetCache", attribute.String("db.system", "etcd"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
 	key = c.prefix + key
 	session, err := concurrency.NewSession(c.client, concurrency.WithTTL(int(c.ttl/time.Second)))
 	if err != nil {
@@ -77,16 +100,161 @@ func (c *EtcdClient) SetCache(ctx context.Context, key, value string) error {
 }
 
 // DeleteCache ..
-func (c *EtcdClient) DeleteCache(ctx context.Context, key string) error {
+func (c *EtcdClient) DeleteCache(ctx context.Context, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.DeleteCache", attribute.String("db.system", "etcd"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
 	key = c.prefix + key
-	_, err := c.client.Delete(ctx, key)
+	_, err = c.client.Delete(ctx, key)
 	if err != nil {
 		return fmt.Errorf("etcd delete error: %v", err)
 	}
 	return nil
 }
 
+// CompareAndSwap replaces the value in etcd when it still holds old, the key keeps its lease
+func (c *EtcdClient) CompareAndSwap(ctx context.Context, key, old, new string) (swapped bool, err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.CompareAndSwap", attribute.String("db.system", "etcd"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
+	key = c.prefix + key
+	resp, err := c.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", old)).
+		Then(clientv3.OpPut(key, new, clientv3.WithIgnoreLease())).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("etcd compare and swap error: %v", err)
+	}
+	return resp.Succeeded, nil
+}
+
+// AddNonce stores the nonce unless it is already present, the nonces share the windowed leases of the rate limit keys
+func (c *EtcdClient) AddNonce(ctx context.Context, key string, ttl time.Duration) (added bool, err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.AddNonce", attribute.String("db.system", "etcd"))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
+	key = c.prefix + NonceKeyPrefix + key
+	leaseTTL := int64((ttl + time.Second - 1) / time.Second)
+	leaseID, err := c.windowLease(ctx, leaseTTL, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("etcd grant lease error: %v", err)
+	}
+	resp, err := c.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, "1", clientv3.WithLease(leaseID))).
+		Commit()
+	if err != nil {
+		c.dropWindowLease(leaseTTL, leaseID)
+		return false, fmt.Errorf("etcd add nonce error: %v", err)
+	}
+	return resp.Succeeded, nil
+}
+
 // Close ..
 func (c *EtcdClient) Close() error {
 	return c.client.Close()
 }
+
+// etcdRateLimitRetries is the number of attempts when another node updated the same keys concurrently
+const etcdRateLimitRetries = 3
+
+// AllowRate takes a token from every key using GCRA with optimistic transactions, the local clock is used
+func (c *EtcdClient) AllowRate(ctx context.Context, keys []string, qps float64, burst int) (retryAfter time.Duration, ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "EtcdClient.AllowRate", attribute.String("db.system", "etcd"))
+	defer func() { tracing.End(span, err) }()
+	ctx = reqinfo.OutgoingContext(ctx)
+
+	if len(keys) == 0 || qps <= 0 {
+		return 0, true, nil
+	}
+
+	emission := time.Duration(float64(time.Second) / qps)
+	tolerance := emission * time.Duration(burst)
+
+	for attempt := 0; attempt < etcdRateLimitRetries; attempt++ {
+		now := time.Now()
+		var cmps []clientv3.Cmp
+		tats := make([]time.Time, len(keys))
+		retryAfter = 0
+
+		for i, key := range keys {
+			fullKey := c.prefix + RateLimitKeyPrefix + key
+			resp, err := c.client.Get(ctx, fullKey)
+			if err != nil {
+				return 0, false, err
+			}
+
+			tat := now
+			if len(resp.Kvs) > 0 {
+				cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(fullKey), "=", resp.Kvs[0].ModRevision))
+				if nanos, err := strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64); err == nil && time.Unix(0, nanos).After(now) {
+					tat = time.Unix(0, nanos)
+				}
+			} else {
+				cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(fullKey), "=", 0))
+			}
+
+			tats[i] = tat.Add(emission)
+			retryAfter = max(retryAfter, tats[i].Add(-tolerance).Sub(now))
+		}
+		if retryAfter > 0 {
+			return retryAfter, false, nil
+		}
+
+		ttl := int64((tolerance+emission)/time.Second) + 1
+		leaseID, err := c.windowLease(ctx, ttl, now)
+		if err != nil {
+			return 0, false, err
+		}
+		var puts []clientv3.Op
+		for i, key := range keys {
+			puts = append(puts, clientv3.OpPut(c.prefix+RateLimitKeyPrefix+key, strconv.FormatInt(tats[i].UnixNano(), 10), clientv3.WithLease(leaseID)))
+		}
+
+		txn, err := c.client.Txn(ctx).If(cmps...).Then(puts...).Commit()
+		if err != nil {
+			c.dropWindowLease(ttl, leaseID)
+			return 0, false, err
+		}
+		if txn.Succeeded {
+			return 0, true, nil
+		}
+	}
+
+	return 0, false, fmt.Errorf("etcd rate limit conflict after %d attempts", etcdRateLimitRetries)
+}
+
+// windowLease returns a lease keeping a key for at least ttl seconds from now. A lease lives for two ttl
+// windows and is shared by all the writes of the first one, so the leases expire by themselves instead
+// of one being granted per request.
+func (c *EtcdClient) windowLease(ctx context.Context, ttl int64, now time.Time) (clientv3.LeaseID, error) {
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+
+	if lease, ok := c.windowLeases[ttl]; ok && !lease.expires.Before(now.Add(time.Duration(ttl)*time.Second)) {
+		return lease.id, nil
+	}
+
+	resp, err := c.client.Grant(ctx, 2*ttl)
+	if err != nil {
+		return 0, err
+	}
+	if c.windowLeases == nil {
+		c.windowLeases = make(map[int64]etcdWindowLease)
+	}
+	c.windowLeases[ttl] = etcdWindowLease{id: resp.ID, expires: now.Add(time.Duration(resp.TTL) * time.Second)}
+	return resp.ID, nil
+}
+
+// dropWindowLease forgets a lease the server may no longer know so the next write grants a new one
+func (c *EtcdClient) dropWindowLease(ttl int64, id clientv3.LeaseID) {
+	c.leaseMu.Lock()
+	defer c.leaseMu.Unlock()
+
+	if lease, ok := c.windowLeases[ttl]; ok && lease.id == id {
+		delete(c.windowLeases, ttl)
+	}
+}
@@ -16,7 +16,7 @@ func TestEtcdClient(t *testing.T) {
 	assert.NoError(t, err)
 	defer etcd.Close()
 
-	client, err := NewEtcdClient("localhost:2379", "TEST_KEY:", 60*time.Second)
+	client, err := NewEtcdClient("localhost:2379", "TEST_KEY:", 60*time.Second, "", "")
 	assert.NoError(t, err)
 	defer client.Close()
 
@@ -34,4 +34,44 @@ func TestEtcdClient(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "", value)
 	})
+
+	t.Run("AllowRateLease", func(t *testing.T) {
+		for _, key := range []string{"a", "b", "c"} {
+			_, ok, err := client.AllowRate(context.Background(), []string{key}, 10, 5)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		leases, err := client.client.Leases(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, leases.Leases, 1)
+	})
+
+	t.Run("AddNonceLease", func(t *testing.T) {
+		before, err := client.client.Leases(context.Background())
+		assert.NoError(t, err)
+
+		for _, nonce := range []string{"n1", "n2", "n3"} {
+			added, err := client.AddNonce(context.Background(), nonce, 2*time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, added)
+		}
+		added, err := client.AddNonce(context.Background(), "n1", 2*time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, added)
+
+		after, err := client.client.Leases(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, after.Leases, len(before.Leases)+1)
+
+		resp, err := client.client.Get(context.Background(), "TEST_KEY:"+NonceKeyPrefix+"n2")
+		assert.NoError(t, err)
+		assert.Len(t, resp.Kvs, 1)
+	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		_, err := client.client.Put(context.Background(), "TEST_KEY:key2", "value2")
+		assert.NoError(t, err)
+		assertSingleSwap(t, client, "key2", "value2")
+	})
 }
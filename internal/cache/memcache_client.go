@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/memcachier/mc/v3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 )
 
 // MemcacheClient implements the Cache interface for Memcached
@@ -28,7 +31,10 @@ func NewMemcacheClient(addrs, prefix string, ttl time.Duration, username, passwo
 }
 
 // GetCache retrieves a value from Memcached
-func (c *MemcacheClient) GetCache(ctx context.Context, key string) (string, error) {
+func (c *MemcacheClient) GetCache(ctx context.Context, key string) (val string, err error) {
+	_, span := tracing.Start(ctx, "MemcacheClient.GetCache", attribute.String("db.system", "memcache"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
 	item, _, _, err := c.client.Get(key)
 	if err == mc.ErrNotFound {
@@ -41,22 +47,70 @@ func (c *MemcacheClient) GetCache(ctx context.Context, key string) (string, erro
 }
 
 // SetCache stores a value in Memcached
-func (c *MemcacheClient) SetCache(ctx context.Context, key, value string) error {
+func (c *MemcacheClient) SetCache(ctx context.Context, key, value string) (err error) {
+	_, span := tracing.Start(ctx, "MemcacheClient.SetCache", attribute.String("db.system", "memcache"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
-	_, err := c.client.Set(key, value, uint32(0), uint32(c.ttl/time.Second), uint64(0))
+	_, err = c.client.Set(key, value, uint32(0), uint32(c.ttl/time.Second), uint64(0))
 	return err
 }
 
 // DeleteCache ..
-func (c *MemcacheClient) DeleteCache(ctx context.Context, key string) error {
+func (c *MemcacheClient) DeleteCache(ctx context.Context, key string) (err error) {
+	_, span := tracing.Start(ctx, "MemcacheClient.DeleteCache", attribute.String("db.system", "memcache"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
-	err := c.client.Del(key)
+	err = c.client.Del(key)
 	if err != nil && err != mc.ErrNotFound {
 		return fmt.Errorf("memcache delete error: %v", err)
 	}
 	return nil
 }
 
+// CompareAndSwap replaces the value in Memcached when it still holds old, using the CAS token of the read
+func (c *MemcacheClient) CompareAndSwap(ctx context.Context, key, old, new string) (swapped bool, err error) {
+	_, span := tracing.Start(ctx, "MemcacheClient.CompareAndSwap", attribute.String("db.system", "memcache"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + key
+	val, _, cas, err := c.client.Get(key)
+	if err == mc.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if val != old {
+		return false, nil
+	}
+	_, err = c.client.Set(key, new, uint32(0), uint32(c.ttl/time.Second), cas)
+	if err == mc.ErrKeyExists || err == mc.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("memcache compare and swap error: %v", err)
+	}
+	return true, nil
+}
+
+// AddNonce stores the nonce unless it is already present
+func (c *MemcacheClient) AddNonce(ctx context.Context, key string, ttl time.Duration) (added bool, err error) {
+	_, span := tracing.Start(ctx, "MemcacheClient.AddNonce", attribute.String("db.system", "memcache"))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + NonceKeyPrefix + key
+	_, err = c.client.Add(key, "1", uint32(0), uint32((ttl+time.Second-1)/time.Second))
+	if err == mc.ErrKeyExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("memcache add nonce error: %v", err)
+	}
+	return true, nil
+}
+
 // Close ..
 func (c *MemcacheClient) Close() error {
 	return nil
@@ -10,6 +10,10 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 )
 
 // MemoryCache is an in-memory cache with TTL and cleanup
@@ -61,7 +65,10 @@ func (c *MemoryCache) startCleanup() {
 }
 
 // GetCache retrieves a value from memory cache
-func (c *MemoryCache) GetCache(ctx context.Context, key string) (string, error) {
+func (c *MemoryCache) GetCache(ctx context.Context, key string) (val string, err error) {
+	_, span := tracing.Start(ctx, "MemoryCache.GetCache", attribute.String("db.system", "memory"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -73,7 +80,10 @@ func (c *MemoryCache) GetCache(ctx context.Context, key string) (string, error)
 }
 
 // SetCache stores a value in memory cache
-func (c *MemoryCache) SetCache(ctx context.Context, key, value string) error {
+func (c *MemoryCache) SetCache(ctx context.Context, key, value string) (err error) {
+	_, span := tracing.Start(ctx, "MemoryCache.SetCache", attribute.String("db.system", "memory"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -85,7 +95,10 @@ func (c *MemoryCache) SetCache(ctx context.Context, key, value string) error {
 }
 
 // DeleteCache delete a value in memory cache
-func (c *MemoryCache) DeleteCache(ctx context.Context, key string) error {
+func (c *MemoryCache) DeleteCache(ctx context.Context, key string) (err error) {
+	_, span := tracing.Start(ctx, "MemoryCache.DeleteCache", attribute.String("db.system", "memory"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -93,6 +106,40 @@ func (c *MemoryCache) DeleteCache(ctx context.Context, key string) error {
 	return nil
 }
 
+// CompareAndSwap replaces the value in memory cache when it still holds old
+func (c *MemoryCache) CompareAndSwap(ctx context.Context, key, old, new string) (swapped bool, err error) {
+	_, span := tracing.Start(ctx, "MemoryCache.CompareAndSwap", attribute.String("db.system", "memory"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + key
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, exists := c.items[key]
+	if !exists || item.expiration <= time.Now().UnixNano() || item.value != old {
+		return false, nil
+	}
+	c.items[key] = cacheItem{
+		value:      new,
+		expiration: time.Now().Add(c.ttl).UnixNano(),
+	}
+	return true, nil
+}
+
+// AddNonce stores the nonce unless it is already present
+func (c *MemoryCache) AddNonce(ctx context.Context, key string, ttl time.Duration) (added bool, err error) {
+	_, span := tracing.Start(ctx, "MemoryCache.AddNonce", attribute.String("db.system", "memory"))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + NonceKeyPrefix + key
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if item, exists := c.items[key]; exists && item.expiration > time.Now().UnixNano() {
+		return false, nil
+	}
+	c.items[key] = cacheItem{expiration: time.Now().Add(ttl).UnixNano()}
+	return true, nil
+}
+
 // Close ..
 func (c *MemoryCache) Close() error {
 	c.Stop()
@@ -2,6 +2,9 @@ package cache
 
 import (
 	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -43,4 +46,33 @@ func TestMemoryCache(t *testing.T) {
 		cache.mu.RUnlock()
 		assert.False(t, exists)
 	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		err := cache.SetCache(context.Background(), "key4", "value4")
+		assert.NoError(t, err)
+		assertSingleSwap(t, cache, "key4", "value4")
+	})
+}
+
+// assertSingleSwap races swaps of the key and checks that exactly one of them wins
+func assertSingleSwap(t *testing.T, c Cache, key, old string) {
+	var wg sync.WaitGroup
+	var swapped atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := c.CompareAndSwap(context.Background(), key, old, "claimed-"+strconv.Itoa(i))
+			assert.NoError(t, err)
+			if ok {
+				swapped.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), swapped.Load())
+
+	ok, err := c.CompareAndSwap(context.Background(), "missing-"+key, old, "claimed")
+	assert.NoError(t, err)
+	assert.False(t, ok)
 }
@@ -0,0 +1,48 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package cache
+
+import "context"
+
+// PrefixCache namespaces the keys of a shared cache, closing it leaves the shared cache open
+type PrefixCache struct {
+	cache  Cache
+	prefix string
+}
+
+// NewPrefixCache returns the cache with the prefix prepended to every key, an empty prefix returns the cache itself
+func NewPrefixCache(c Cache, prefix string) Cache {
+	if prefix == "" {
+		return c
+	}
+	return &PrefixCache{cache: c, prefix: prefix}
+}
+
+// GetCache .
+func (c *PrefixCache) GetCache(ctx context.Context, key string) (string, error) {
+	return c.cache.GetCache(ctx, c.prefix+key)
+}
+
+// SetCache .
+func (c *PrefixCache) SetCache(ctx context.Context, key, value string) error {
+	return c.cache.SetCache(ctx, c.prefix+key, value)
+}
+
+// DeleteCache .
+func (c *PrefixCache) DeleteCache(ctx context.Context, key string) error {
+	return c.cache.DeleteCache(ctx, c.prefix+key)
+}
+
+// CompareAndSwap .
+func (c *PrefixCache) CompareAndSwap(ctx context.Context, key, old, new string) (bool, error) {
+	return c.cache.CompareAndSwap(ctx, c.prefix+key, old, new)
+}
+
+// Close .
+func (c *PrefixCache) Close() error {
+	return nil
+}
@@ -10,9 +10,13 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 )
 
 // RedisClient implements the Cache interface for Redis
@@ -43,9 +47,12 @@ func NewRedisClient(addrs, prefix string, ttl time.Duration, username, password,
 }
 
 // GetCache retrieves a value from Redis
-func (c *RedisClient) GetCache(ctx context.Context, key string) (string, error) {
+func (c *RedisClient) GetCache(ctx context.Context, key string) (val string, err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.GetCache", attribute.String("db.system", "redis"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
-	val, err := c.client.Get(ctx, key).Result()
+	val, err = c.client.Get(ctx, key).Result()
 	if err == redis.Nil {
 		return "", nil
 	}
@@ -53,22 +60,142 @@ func (c *RedisClient) GetCache(ctx context.Context, key string) (string, error)
 }
 
 // SetCache stores a value in Redis
-func (c *RedisClient) SetCache(ctx context.Context, key, value string) error {
+func (c *RedisClient) SetCache(ctx context.Context, key, value string) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.SetCache", attribute.String("db.system", "redis"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
 	return c.client.Set(ctx, key, value, c.ttl).Err()
 }
 
 // DeleteCache delete a value in Redis
-func (c *RedisClient) DeleteCache(ctx context.Context, key string) error {
+func (c *RedisClient) DeleteCache(ctx context.Context, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.DeleteCache", attribute.String("db.system", "redis"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	key = c.prefix + key
-	err := c.client.Del(ctx, key).Err()
+	err = c.client.Del(ctx, key).Err()
 	if err != nil && err != redis.Nil {
 		return fmt.Errorf("redis delete error: %v", err)
 	}
 	return nil
 }
 
+// compareAndSwapScript sets the key when it holds ARGV[1], ARGV[3] is the ttl in milliseconds
+var compareAndSwapScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap replaces the value in Redis when it still holds old
+func (c *RedisClient) CompareAndSwap(ctx context.Context, key, old, new string) (swapped bool, err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.CompareAndSwap", attribute.String("db.system", "redis"), attribute.String("cache.key", key))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + key
+	n, err := compareAndSwapScript.Run(ctx, c.client, []string{key}, old, new, c.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("redis compare and swap error: %v", err)
+	}
+	return n == 1, nil
+}
+
+// AddNonce stores the nonce unless it is already present
+func (c *RedisClient) AddNonce(ctx context.Context, key string, ttl time.Duration) (added bool, err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.AddNonce", attribute.String("db.system", "redis"))
+	defer func() { tracing.End(span, err) }()
+
+	key = c.prefix + NonceKeyPrefix + key
+	added, err = c.client.SetNX(ctx, key, "1", ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis add nonce error: %v", err)
+	}
+	return added, nil
+}
+
 // Close ..
 func (c *RedisClient) Close() error {
 	return c.client.Close()
 }
+
+// allowRateScript implements GCRA over all keys, the theoretical arrival times are only written when every key allows
+var allowRateScript = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local retry = 0
+local tats = {}
+for i, key in ipairs(KEYS) do
+	local tat = tonumber(redis.call('GET', key)) or now
+	if tat < now then
+		tat = now
+	end
+	local newTat = tat + emission
+	local wait = newTat - tolerance - now
+	if wait > retry then
+		retry = wait
+	end
+	tats[i] = newTat
+end
+if retry > 0 then
+	return math.ceil(retry)
+end
+for i, key in ipairs(KEYS) do
+	redis.call('SET', key, string.format('%d', tats[i]), 'PX', math.ceil((tats[i] - now) / 1000) + 1)
+end
+return 0
+`)
+
+// AllowRate takes a token from every key using GCRA, the clock of the Redis server is used.
+// The script touches all the keys, so they must share a Redis Cluster slot: the group of the keys is used as
+// their hash tag and the keys of one call must belong to the same group.
+func (c *RedisClient) AllowRate(ctx context.Context, keys []string, qps float64, burst int) (retryAfter time.Duration, ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "RedisClient.AllowRate", attribute.String("db.system", "redis"))
+	defer func() { tracing.End(span, err) }()
+
+	if len(keys) == 0 || qps <= 0 {
+		return 0, true, nil
+	}
+
+	group := rateLimitGroup(keys[0])
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		if rateLimitGroup(key) != group {
+			return 0, false, fmt.Errorf("redis rate limit keys %q and %q are in different groups", keys[0], key)
+		}
+		fullKeys[i] = c.prefix + RateLimitKeyPrefix + rateLimitHashTag(key)
+	}
+
+	emission := int64(float64(time.Second/time.Microsecond) / qps)
+	tolerance := emission * int64(burst)
+	wait, err := allowRateScript.Run(ctx, c.client, fullKeys, emission, tolerance).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("redis rate limit error: %v", err)
+	}
+	if wait > 0 {
+		return time.Duration(wait) * time.Microsecond, false, nil
+	}
+	return 0, true, nil
+}
+
+// rateLimitGroup returns the part of the key before its last "|", the tenant and scope of a client key
+func rateLimitGroup(key string) string {
+	if i := strings.LastIndex(key, "|"); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// rateLimitHashTag wraps the group of the key in braces so that Redis Cluster hashes it alone
+func rateLimitHashTag(key string) string {
+	group := rateLimitGroup(key)
+	return "{" + group + "}" + key[len(group):]
+}
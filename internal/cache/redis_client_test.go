@@ -14,7 +14,7 @@ func TestRedisClient(t *testing.T) {
 	assert.NoError(t, err)
 	defer mr.Close()
 
-	client, err := NewRedisClient(mr.Addr(), "TEST_KEY:", 60*time.Second)
+	client, err := NewRedisClient(mr.Addr(), "TEST_KEY:", 60*time.Second, "", "", "")
 	assert.NoError(t, err)
 	defer client.Close()
 
@@ -32,4 +32,28 @@ func TestRedisClient(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "", value)
 	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		err := client.SetCache(context.Background(), "key2", "value2")
+		assert.NoError(t, err)
+		assertSingleSwap(t, client, "key2", "value2")
+		assert.Greater(t, mr.TTL("TEST_KEY:key2"), time.Duration(0))
+	})
+
+	t.Run("AllowRateHashTag", func(t *testing.T) {
+		keys := []string{"tenant:t1|get_data|ip:1.2.3.4", "tenant:t1|get_data|api_key:k1"}
+		_, ok, err := client.AllowRate(context.Background(), keys, 10, 5)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, mr.Exists("TEST_KEY:RATE_LIMIT:{tenant:t1|get_data}|ip:1.2.3.4"))
+		assert.True(t, mr.Exists("TEST_KEY:RATE_LIMIT:{tenant:t1|get_data}|api_key:k1"))
+
+		_, ok, err = client.AllowRate(context.Background(), []string{"global"}, 10, 5)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, mr.Exists("TEST_KEY:RATE_LIMIT:{global}"))
+
+		_, _, err = client.AllowRate(context.Background(), []string{"get_data|ip:1.2.3.4", "check_data|ip:1.2.3.4"}, 10, 5)
+		assert.Error(t, err)
+	})
 }
@@ -7,9 +7,16 @@
 package common
 
 import (
+	"context"
+
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/health"
+	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/internal/webhook"
 	"go.uber.org/zap"
 )
 
@@ -19,9 +26,51 @@ type SvcContext struct {
 	DynamicConfig *config.DynamicConfig
 	Logger        *zap.Logger
 	Captcha       *gocaptcha.GoCaptcha
+	HealthChecker *health.Checker
+	Tenants       *tenant.Registry
+	Audit         *audit.Log
+	Webhooks      *webhook.Dispatcher
 }
 
 // NewSvcContext ..
 func NewSvcContext() *SvcContext {
 	return &SvcContext{}
 }
+
+// GetCaptcha returns the captcha instances of the request tenant, the default ones when it has no own config
+func (s *SvcContext) GetCaptcha(ctx context.Context) *gocaptcha.GoCaptcha {
+	if gc := s.Tenants.Captcha(tenant.FromContext(ctx)); gc != nil {
+		return gc
+	}
+	return s.Captcha
+}
+
+// GetWritableCaptcha returns the captcha instances whose config the request tenant may update,
+// nil when the tenant shares the default ones
+func (s *SvcContext) GetWritableCaptcha(ctx context.Context) *gocaptcha.GoCaptcha {
+	id := tenant.FromContext(ctx)
+	if id == "" {
+		return s.Captcha
+	}
+	return s.Tenants.Captcha(id)
+}
+
+// GetCache returns the cache in the key namespace of the request tenant
+func (s *SvcContext) GetCache(ctx context.Context) cache.Cache {
+	cfg := s.DynamicConfig.Get()
+	t, ok := cfg.GetTenant(tenant.FromContext(ctx))
+	if !ok {
+		return s.CacheMgr.GetCache()
+	}
+	return cache.NewPrefixCache(s.CacheMgr.GetCache(), t.CacheKeyPrefix)
+}
+
+// GetResourceDir returns the absolute resource directory of the request tenant
+func (s *SvcContext) GetResourceDir(ctx context.Context) string {
+	cfg := s.DynamicConfig.Get()
+	t, ok := cfg.GetTenant(tenant.FromContext(ctx))
+	if !ok {
+		return helper.GetResourceDirAbsPath()
+	}
+	return tenant.ResourceDir(t)
+}
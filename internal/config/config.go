@@ -1,17 +1,24 @@
 package config
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/listener"
 )
 
 // ServiceDiscovery .
@@ -22,6 +29,371 @@ const (
 	DiscoveryTypeNacos            = "nacos"
 )
 
+// Tracing exporter .
+const (
+	TracingExporterOTLPGrpc string = "otlp-grpc"
+	TracingExporterOTLPHttp        = "otlp-http"
+	TracingExporterStdout          = "stdout"
+	TracingExporterFile            = "file"
+)
+
+// Rate limit keys .
+const (
+	RateLimitKeyIP      string = "ip"
+	RateLimitKeyAPIKey         = "api_key"
+	RateLimitKeySiteKey        = "site_key"
+)
+
+// Rate limit modes .
+const (
+	RateLimitModeLocal       string = "local"
+	RateLimitModeDistributed        = "distributed"
+)
+
+// TLS client certificate modes .
+const (
+	TlsClientAuthNone   string = "none"
+	TlsClientAuthManage        = "manage"
+	TlsClientAuthAll           = "all"
+)
+
+// TLS versions .
+const (
+	TlsVersion12 string = "1.2"
+	TlsVersion13        = "1.3"
+)
+
+// DefaultSignatureMaxSkew is the accepted clock skew of the signed requests in seconds
+const DefaultSignatureMaxSkew = 300
+
+// Rate limit scopes .
+const (
+	RateLimitScopeGetData   string = "get_data"
+	RateLimitScopeCheckData        = "check_data"
+	RateLimitScopeManage           = "manage"
+	RateLimitScopeDefault          = "default"
+)
+
+// IP filter groups .
+const (
+	IPFilterGroupPublic string = "public"
+	IPFilterGroupManage        = "manage"
+)
+
+// API key scopes .
+const (
+	ScopeAll           string = "*"
+	ScopeCaptchaVerify        = "captcha:verify"
+	ScopeStatusRead           = "status:read"
+	ScopeStatusWrite          = "status:write"
+	ScopeResourceRead         = "resource:read"
+	ScopeResourceWrite        = "resource:write"
+	ScopeConfigRead           = "config:read"
+	ScopeConfigWrite          = "config:write"
+	ScopeDebug                = "debug"
+	ScopeAuditRead            = "audit:read"
+)
+
+// APIKey is an API key identified by its id, only the hash of the secret is stored.
+// The signing secret is used instead by the HMAC signed requests.
+type APIKey struct {
+	ID            string   `json:"id"`
+	SecretHash    string   `json:"secret_hash"`    // hex SHA-256 of the secret, optionally prefixed with "sha256:"
+	SigningSecret string   `json:"signing_secret"` // HMAC-SHA256 secret of the signed requests
+	Scopes        []string `json:"scopes"`
+	ExpiresAt     string   `json:"expires_at"` // RFC 3339, empty never expires
+	Description   string   `json:"description"`
+	Tenant        string   `json:"tenant"` // tenant id, empty is the default tenant
+}
+
+// HasScope .
+func (k APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+	}
+	return scope == ""
+}
+
+// Expired .
+func (k APIKey) Expired(now time.Time) bool {
+	if k.ExpiresAt == "" {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, k.ExpiresAt)
+	return err != nil || !now.Before(expiresAt)
+}
+
+// IsValidScope .
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopeAll, ScopeCaptchaVerify, ScopeStatusRead, ScopeStatusWrite,
+		ScopeResourceRead, ScopeResourceWrite, ScopeConfigRead, ScopeConfigWrite, ScopeDebug, ScopeAuditRead:
+		return true
+	}
+	return false
+}
+
+// HashAPIKeySecret returns the hash stored in the secret_hash field of an API key
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// JWTAuth is the bearer token authentication of the management APIs
+type JWTAuth struct {
+	Enable              bool                `json:"enable"`
+	Issuer              string              `json:"issuer"`
+	Audience            []string            `json:"audience"`              // any of them must be in the aud claim
+	JWKSFile            string              `json:"jwks_file"`             // local JWKS file, takes precedence over the url
+	JWKSURL             string              `json:"jwks_url"`              // JWKS endpoint of the identity provider
+	JWKSRefreshInterval int                 `json:"jwks_refresh_interval"` // seconds
+	Leeway              int                 `json:"leeway"`                // seconds of clock skew allowed for exp, nbf and iat
+	ScopeClaims         []string            `json:"scope_claims"`          // claims holding the permissions, default scope, scp, roles
+	ClaimScopes         map[string][]string `json:"claim_scopes"`          // maps claim values such as roles to API key scopes
+}
+
+// IPFilterRule is a list of allowed and denied IPs or CIDR blocks, an empty allow list allows every address not denied
+type IPFilterRule struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// CORSRule is the cross-origin policy of the browsers calling the service
+type CORSRule struct {
+	AllowedOrigins   []string `json:"allowed_origins"` // exact origins, "*" or wildcard subdomains such as https://*.example.com
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAge           int      `json:"max_age"` // seconds
+}
+
+// Tenant isolates the captcha configs, resources, cache keys and rate limits of a customer.
+// Requests are resolved to a tenant by their API key or site key.
+type Tenant struct {
+	SiteKeys          []string                 `json:"site_keys"`
+	CaptchaConfigFile string                   `json:"captcha_config_file"` // gocaptcha config of the tenant, empty shares the default one
+	ResourceDir       string                   `json:"resource_dir"`        // subdirectory of the resources directory, default the tenant id
+	CacheKeyPrefix    string                   `json:"cache_key_prefix"`    // prepended to the captcha keys, default "<id>:"
+	RateLimitRules    map[string]RateLimitRule `json:"rate_limit_rules"`    // replace the global rules of the same scope
+}
+
+// AdminListener serves the management and observability routes apart from the public captcha flow
+type AdminListener struct {
+	Enable        bool   `json:"enable"`
+	BindAddr      string `json:"bind_addr"` // empty binds all interfaces
+	HTTPPort      string `json:"http_port"`
+	GRPCPort      string `json:"grpc_port"`
+	HTTPAddr      string `json:"http_addr"` // tcp://host:port or unix:///path, takes precedence over bind_addr and http_port
+	GRPCAddr      string `json:"grpc_addr"` // tcp://host:port or unix:///path, takes precedence over bind_addr and grpc_port
+	TlsCertFile   string `json:"tls_cert_file"`
+	TlsKeyFile    string `json:"tls_key_file"`
+	TlsCaFile     string `json:"tls_ca_file"`     // every client must present a certificate of this CA when set
+	TlsMinVersion string `json:"tls_min_version"` // 1.2, 1.3
+}
+
+// IsTLSEnabled .
+func (a AdminListener) IsTLSEnabled() bool {
+	return a.TlsCertFile != "" && a.TlsKeyFile != ""
+}
+
+// GetHTTPAddr returns the listener address of the admin HTTP server
+func (a AdminListener) GetHTTPAddr() string {
+	if a.HTTPAddr != "" {
+		return a.HTTPAddr
+	}
+	return "tcp://" + net.JoinHostPort(a.BindAddr, a.HTTPPort)
+}
+
+// GetGRPCAddr returns the listener address of the admin gRPC server
+func (a AdminListener) GetGRPCAddr() string {
+	if a.GRPCAddr != "" {
+		return a.GRPCAddr
+	}
+	return "tcp://" + net.JoinHostPort(a.BindAddr, a.GRPCPort)
+}
+
+// ServerLimits are the timeouts and the size limits of the HTTP and gRPC servers, zero fields take the default values.
+// The body limits are hot reloaded, the others take effect on restart.
+type ServerLimits struct {
+	ReadHeaderTimeout int              `json:"read_header_timeout"` // seconds
+	ReadTimeout       int              `json:"read_timeout"`        // seconds
+	WriteTimeout      int              `json:"write_timeout"`       // seconds
+	IdleTimeout       int              `json:"idle_timeout"`        // seconds
+	MaxHeaderBytes    int              `json:"max_header_bytes"`
+	MaxBodyBytes      int64            `json:"max_body_bytes"`    // body limit of the routes without a route limit
+	RouteBodyLimits   map[string]int64 `json:"route_body_limits"` // bytes, keyed by the last segment of the route path such as check-data
+
+	GRPCMaxRecvMsgSize      int  `json:"grpc_max_recv_msg_size"`     // bytes
+	GRPCMaxSendMsgSize      int  `json:"grpc_max_send_msg_size"`     // bytes
+	GRPCKeepaliveTime       int  `json:"grpc_keepalive_time"`        // seconds, idle connections are pinged after this time
+	GRPCKeepaliveTimeout    int  `json:"grpc_keepalive_timeout"`     // seconds, connections not answering the ping are closed
+	GRPCKeepaliveMinTime    int  `json:"grpc_keepalive_min_time"`    // seconds, clients pinging more often are disconnected
+	GRPCPermitWithoutStream bool `json:"grpc_permit_without_stream"` // clients may ping without active calls
+}
+
+// BodyLimit returns the body limit of the route
+func (l ServerLimits) BodyLimit(route string) int64 {
+	if limit, ok := l.RouteBodyLimits[path.Base(route)]; ok {
+		return limit
+	}
+	return l.MaxBodyBytes
+}
+
+// Access log fields
+const (
+	AccessLogFieldMethod      = "method"
+	AccessLogFieldPath        = "path"
+	AccessLogFieldStatus      = "status"
+	AccessLogFieldClient      = "client"
+	AccessLogFieldRequestID   = "request_id"
+	AccessLogFieldKeyID       = "key_id"
+	AccessLogFieldCaptchaID   = "captcha_id"
+	AccessLogFieldCaptchaType = "captcha_type"
+	AccessLogFieldCaptchaKey  = "captcha_key"
+	AccessLogFieldBytes       = "bytes"
+	AccessLogFieldDuration    = "duration"
+	AccessLogFieldUserAgent   = "user_agent"
+	AccessLogFieldError       = "error"
+)
+
+// AccessLog is the access log of the HTTP and gRPC requests, zero fields take the default values
+type AccessLog struct {
+	Fields            []string `json:"fields"`
+	SuccessSampleRate float64  `json:"success_sample_rate"` // share of the successful requests logged, the failed ones are always logged
+	ExcludeHealth     bool     `json:"exclude_health"`      // skip the health check requests
+}
+
+// Has reports whether the field is logged
+func (l AccessLog) Has(field string) bool {
+	for _, f := range l.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugEndpoints are the pprof, runtime stats and profile capture endpoints, disabled by default.
+// They are served on the admin listener when it is enabled, otherwise to the API keys with the debug scope.
+type DebugEndpoints struct {
+	Enable            bool   `json:"enable"`
+	ProfileDir        string `json:"profile_dir"`         // directory of the captured profiles
+	MaxProfileSeconds int    `json:"max_profile_seconds"` // longest CPU profile capture
+}
+
+// AuditLog is the append-only JSON lines trail of the management operations and the config changes,
+// zero fields take the default values. The settings take effect on restart.
+type AuditLog struct {
+	Enable     bool   `json:"enable"`
+	File       string `json:"file"`        // path of the audit log file
+	MaxSize    int    `json:"max_size"`    // megabytes, the file is rotated when it grows larger
+	MaxBackups int    `json:"max_backups"` // rotated files kept
+	MaxAge     int    `json:"max_age"`     // days the rotated files are kept
+	Compress   bool   `json:"compress"`    // gzip the rotated files
+}
+
+// Webhook event types
+const (
+	WebhookEventAll              = "*"
+	WebhookEventCaptchaVerified  = "captcha.verified"
+	WebhookEventCaptchaFailed    = "captcha.failed"
+	WebhookEventCaptchaLocked    = "captcha.attempt_locked"
+	WebhookEventConfigUpdated    = "config.updated"
+	WebhookEventResourceUploaded = "resource.uploaded"
+	WebhookEventResourceDeleted  = "resource.deleted"
+)
+
+// WebhookEndpoint receives the events of its types as signed JSON POST requests
+type WebhookEndpoint struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"` // HMAC-SHA256 signing secret, empty sends unsigned requests
+	Events []string `json:"events"` // event types, empty or "*" receives every event
+}
+
+// Accepts reports whether the endpoint receives the event type
+func (e WebhookEndpoint) Accepts(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == WebhookEventAll || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhooks are the event notifications, zero fields take the default values.
+// The endpoints are hot reloaded, the queue settings take effect on restart.
+type Webhooks struct {
+	Endpoints      []WebhookEndpoint `json:"endpoints"`
+	QueueDir       string            `json:"queue_dir"`       // directory of the pending deliveries, kept across restarts
+	QueueSize      int               `json:"queue_size"`      // pending deliveries, the new events are dropped when it is full
+	MaxAttempts    int               `json:"max_attempts"`    // attempts of a delivery before it is given up
+	InitialBackoff int               `json:"initial_backoff"` // seconds before the first retry, doubled after each failure
+	MaxBackoff     int               `json:"max_backoff"`     // seconds, longest delay between the retries
+	Timeout        int               `json:"timeout"`         // seconds of a delivery request
+	Workers        int               `json:"workers"`         // concurrent deliveries
+}
+
+// Log levels
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+	LogLevelNone  = "none"
+)
+
+// Log components with their own level
+const (
+	LogComponentCache  = "cache"
+	LogComponentConfig = "config"
+	LogComponentHTTP   = "http"
+	LogComponentGRPC   = "grpc"
+)
+
+// Log encodings and outputs
+const (
+	LogEncodingJSON    = "json"
+	LogEncodingConsole = "console"
+
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
+
+// LogConfig is the encoding and the output of the logs, zero fields take the default values.
+// The levels are hot reloaded, the other fields take effect on restart.
+type LogConfig struct {
+	Encoding        string            `json:"encoding"`         // json, console
+	Output          string            `json:"output"`           // stdout, stderr, file
+	File            string            `json:"file"`             // path of the log file of the file output
+	MaxSize         int               `json:"max_size"`         // megabytes, the file is rotated when it grows larger
+	MaxBackups      int               `json:"max_backups"`      // rotated files kept
+	MaxAge          int               `json:"max_age"`          // days the rotated files are kept
+	Compress        bool              `json:"compress"`         // gzip the rotated files
+	RotateInterval  int               `json:"rotate_interval"`  // hours, the file is also rotated periodically, 0 disables it
+	Sampling        LogSampling       `json:"sampling"`         // sampling of the repeated entries
+	ComponentLevels map[string]string `json:"component_levels"` // keyed by cache, config, http, grpc, the other components follow log_level
+}
+
+// LogSampling logs the first entries with the same level and message of each second and every Thereafter-th one after
+type LogSampling struct {
+	Enable     bool `json:"enable"`
+	Initial    int  `json:"initial"`
+	Thereafter int  `json:"thereafter"`
+}
+
+// RateLimitRule is the token bucket quota of a rate limit scope
+type RateLimitRule struct {
+	QPS   float64 `json:"qps"`
+	Burst int     `json:"burst"`
+}
+
 // Config defines the configuration structure for the application
 type Config struct {
 	ServiceNode int64 `json:"service_node"`
@@ -40,9 +412,21 @@ type Config struct {
 	RateLimitQPS   int      `json:"rate_limit_qps"`
 	RateLimitBurst int      `json:"rate_limit_burst"`
 	EnableCors     bool     `json:"enable_cors"`
-	APIKeys        []string `json:"api_keys"`
+	APIKeys        []string `json:"api_keys"` // deprecated, plain secrets with every scope, use scoped_api_keys
 	AuthAPIs       []string `json:"auth_apis"`
-	LogLevel       string   `json:"log_level"` // error, debug, info, none
+	LogLevel       string   `json:"log_level"` // error, warn, debug, info, none
+
+	TlsCertFile   string `json:"tls_cert_file"`
+	TlsKeyFile    string `json:"tls_key_file"`
+	TlsCaFile     string `json:"tls_ca_file"`     // CA of the client certificates
+	TlsClientAuth string `json:"tls_client_auth"` // none, manage, all
+	TlsMinVersion string `json:"tls_min_version"` // 1.2, 1.3
+
+	HTTPAddr       string `json:"http_addr"`        // tcp://host:port or unix:///path, takes precedence over http_port
+	GRPCAddr       string `json:"grpc_addr"`        // tcp://host:port or unix:///path, takes precedence over grpc_port
+	UnixSocketMode string `json:"unix_socket_mode"` // octal file mode of the Unix domain sockets, default 0660
+	SinglePort     bool   `json:"single_port"`      // serves gRPC and gRPC-Web on the HTTP listeners
+	EnableGRPCWeb  bool   `json:"enable_grpc_web"`  // serves gRPC-Web on the HTTP listeners
 
 	EnableDynamicConfig         bool   `json:"enable_dynamic_config"`
 	DynamicConfigType           string `json:"dynamic_config_type"` // etcd, zookeeper, consul, nacos
@@ -73,24 +457,85 @@ type Config struct {
 	ServiceDiscoveryTlsCertFile    string `json:"service_discovery_tls_cert_file"`
 	ServiceDiscoveryTlsKeyFile     string `json:"service_discovery_tls_key_file"`
 	ServiceDiscoveryTlsCaFile      string `json:"service_discovery_tls_ca_file"`
+
+	EnableTracing      bool              `json:"enable_tracing"`
+	TracingExporter    string            `json:"tracing_exporter"` // otlp-grpc, otlp-http, stdout, file
+	TracingEndpoint    string            `json:"tracing_endpoint"`
+	TracingInsecure    bool              `json:"tracing_insecure"`
+	TracingHeaders     map[string]string `json:"tracing_headers"`
+	TracingSampleRatio *float64          `json:"tracing_sample_ratio"` // unset samples all traces
+	TracingFile        string            `json:"tracing_file"`
+
+	GenerateQueueSize       int     `json:"generate_queue_size"`
+	HealthCheckTimeout      int     `json:"health_check_timeout"` // seconds
+	ReadinessQueueThreshold float64 `json:"readiness_queue_threshold"`
+
+	RateLimitKeyBy          []string                 `json:"rate_limit_key_by"`       // ip, api_key, site_key
+	RateLimitRules          map[string]RateLimitRule `json:"rate_limit_rules"`        // get_data, check_data, manage, default
+	RateLimitIdleTimeout    int                      `json:"rate_limit_idle_timeout"` // seconds
+	RateLimitMaxKeys        int                      `json:"rate_limit_max_keys"`
+	RateLimitMode           string                   `json:"rate_limit_mode"`            // local, distributed
+	RateLimitBackendTimeout int                      `json:"rate_limit_backend_timeout"` // milliseconds
+
+	TrustedProxies  []string                `json:"trusted_proxies"`
+	IPFilter        IPFilterRule            `json:"ip_filter"`
+	IPFilterGroups  map[string]IPFilterRule `json:"ip_filter_groups"`   // public, manage
+	IPFilterAPIKeys map[string]IPFilterRule `json:"ip_filter_api_keys"` // keyed by API key id
+
+	CORS      CORSRule            `json:"cors"`
+	CORSSites map[string]CORSRule `json:"cors_sites"` // keyed by site key
+
+	ScopedAPIKeys    []APIKey `json:"scoped_api_keys"`
+	SignatureMaxSkew int      `json:"signature_max_skew"` // seconds, 0 takes the default
+
+	JWTAuth JWTAuth `json:"jwt_auth"`
+
+	Tenants map[string]Tenant `json:"tenants"` // keyed by tenant id
+
+	Admin AdminListener `json:"admin"`
+
+	Limits ServerLimits `json:"limits"`
+
+	AccessLog AccessLog `json:"access_log"`
+
+	Log LogConfig `json:"log"`
+
+	Debug DebugEndpoints `json:"debug"`
+
+	Audit AuditLog `json:"audit"`
+
+	Webhooks Webhooks `json:"webhooks"`
 }
 
-// GetAuthAPIs ..
+// GetAuthAPIs returns the auth APIs, the configured ones extend the default ones
 func (cfg *Config) GetAuthAPIs() map[string]struct{} {
 	apisMap := make(map[string]struct{})
 
-	authApis := getDefaultAuthAPIs()
-	if len(cfg.AuthAPIs) > 0 {
-		authApis = cfg.AuthAPIs
+	for _, key := range getDefaultAuthAPIs() {
+		apisMap[key] = struct{}{}
 	}
-
-	for _, key := range authApis {
+	for _, key := range cfg.AuthAPIs {
 		apisMap[key] = struct{}{}
 	}
 
 	return apisMap
 }
 
+// IsAuthAPI reports whether the HTTP path or gRPC full method requires authentication.
+// The manage routes, the manage service and the rate limit control always do, whatever auth_apis lists.
+func (cfg *Config) IsAuthAPI(api string) bool {
+	if api == AuthRateLimitPath {
+		return true
+	}
+	for _, prefix := range authAPIPrefixes {
+		if strings.HasPrefix(api, prefix) {
+			return true
+		}
+	}
+	_, exists := cfg.GetAuthAPIs()[api]
+	return exists
+}
+
 // GetAPIKeys ..
 func (cfg *Config) GetAPIKeys() map[string]struct{} {
 	apiKeyMap := make(map[string]struct{})
@@ -101,6 +546,326 @@ func (cfg *Config) GetAPIKeys() map[string]struct{} {
 	return apiKeyMap
 }
 
+// HasAPIKeys reports whether any API key is configured
+func (cfg *Config) HasAPIKeys() bool {
+	return len(cfg.APIKeys) > 0 || len(cfg.ScopedAPIKeys) > 0
+}
+
+// LookupAPIKey finds the API key of the secret, the plain api_keys are granted every scope
+func (cfg *Config) LookupAPIKey(secret string) (APIKey, bool) {
+	if secret == "" {
+		return APIKey{}, false
+	}
+
+	hash := HashAPIKeySecret(secret)
+	for _, key := range cfg.ScopedAPIKeys {
+		if key.SecretHash != "" && subtle.ConstantTimeCompare([]byte(strings.ToLower(strings.TrimPrefix(key.SecretHash, "sha256:"))), []byte(hash)) == 1 {
+			return key, true
+		}
+	}
+
+	for i, key := range cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(secret)) == 1 {
+			return APIKey{ID: fmt.Sprintf("api_keys[%d]", i), Scopes: []string{ScopeAll}}, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// HasAPIKeyID reports whether an API key has the id, the plain api_keys are named api_keys[i]
+func (cfg *Config) HasAPIKeyID(id string) bool {
+	for _, key := range cfg.ScopedAPIKeys {
+		if key.ID == id {
+			return true
+		}
+	}
+	for i := range cfg.APIKeys {
+		if id == fmt.Sprintf("api_keys[%d]", i) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSigningKey returns the API key of the id that can sign requests
+func (cfg *Config) GetSigningKey(id string) (APIKey, bool) {
+	for _, key := range cfg.ScopedAPIKeys {
+		if key.ID == id && key.SigningSecret != "" {
+			return key, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// GetCORSRule returns the cross-origin policy of the site, a site rule replaces the global one.
+// Empty fields take the default values.
+func (cfg *Config) GetCORSRule(siteKey string) CORSRule {
+	rule := cfg.CORS
+	if siteRule, ok := cfg.CORSSites[siteKey]; ok && siteKey != "" {
+		rule = siteRule
+	}
+
+	def := DefaultCORSRule()
+	if len(rule.AllowedOrigins) == 0 {
+		rule.AllowedOrigins = def.AllowedOrigins
+	}
+	if len(rule.AllowedMethods) == 0 {
+		rule.AllowedMethods = def.AllowedMethods
+	}
+	if len(rule.AllowedHeaders) == 0 {
+		rule.AllowedHeaders = def.AllowedHeaders
+	}
+	if rule.MaxAge == 0 {
+		rule.MaxAge = def.MaxAge
+	}
+	return rule
+}
+
+// GetServerLimits returns the server limits, empty fields take the default values and the route body limits extend the default ones
+func (cfg *Config) GetServerLimits() ServerLimits {
+	limits := cfg.Limits
+	def := DefaultServerLimits()
+	if limits.ReadHeaderTimeout == 0 {
+		limits.ReadHeaderTimeout = def.ReadHeaderTimeout
+	}
+	if limits.ReadTimeout == 0 {
+		limits.ReadTimeout = def.ReadTimeout
+	}
+	if limits.WriteTimeout == 0 {
+		limits.WriteTimeout = def.WriteTimeout
+	}
+	if limits.IdleTimeout == 0 {
+		limits.IdleTimeout = def.IdleTimeout
+	}
+	if limits.MaxHeaderBytes == 0 {
+		limits.MaxHeaderBytes = def.MaxHeaderBytes
+	}
+	if limits.MaxBodyBytes == 0 {
+		limits.MaxBodyBytes = def.MaxBodyBytes
+	}
+	routeLimits := def.RouteBodyLimits
+	for route, limit := range limits.RouteBodyLimits {
+		routeLimits[route] = limit
+	}
+	limits.RouteBodyLimits = routeLimits
+	if limits.GRPCMaxRecvMsgSize == 0 {
+		limits.GRPCMaxRecvMsgSize = def.GRPCMaxRecvMsgSize
+	}
+	if limits.GRPCMaxSendMsgSize == 0 {
+		limits.GRPCMaxSendMsgSize = def.GRPCMaxSendMsgSize
+	}
+	if limits.GRPCKeepaliveTime == 0 {
+		limits.GRPCKeepaliveTime = def.GRPCKeepaliveTime
+	}
+	if limits.GRPCKeepaliveTimeout == 0 {
+		limits.GRPCKeepaliveTimeout = def.GRPCKeepaliveTimeout
+	}
+	if limits.GRPCKeepaliveMinTime == 0 {
+		limits.GRPCKeepaliveMinTime = def.GRPCKeepaliveMinTime
+	}
+	return limits
+}
+
+// GetAccessLog returns the access log settings, empty fields take the default values
+func (cfg *Config) GetAccessLog() AccessLog {
+	accessLog := cfg.AccessLog
+	def := DefaultAccessLog()
+	if len(accessLog.Fields) == 0 {
+		accessLog.Fields = def.Fields
+	}
+	if accessLog.SuccessSampleRate == 0 {
+		accessLog.SuccessSampleRate = def.SuccessSampleRate
+	}
+	return accessLog
+}
+
+// GetLogConfig returns the log settings, empty fields take the default values
+func (cfg *Config) GetLogConfig() LogConfig {
+	logCfg := cfg.Log
+	def := DefaultLogConfig()
+	if logCfg.Encoding == "" {
+		logCfg.Encoding = def.Encoding
+	}
+	if logCfg.Output == "" {
+		logCfg.Output = def.Output
+	}
+	if logCfg.MaxSize == 0 {
+		logCfg.MaxSize = def.MaxSize
+	}
+	if logCfg.MaxBackups == 0 {
+		logCfg.MaxBackups = def.MaxBackups
+	}
+	if logCfg.MaxAge == 0 {
+		logCfg.MaxAge = def.MaxAge
+	}
+	if logCfg.Sampling.Initial == 0 {
+		logCfg.Sampling.Initial = def.Sampling.Initial
+	}
+	if logCfg.Sampling.Thereafter == 0 {
+		logCfg.Sampling.Thereafter = def.Sampling.Thereafter
+	}
+	return logCfg
+}
+
+// GetDebugEndpoints returns the debug endpoint settings, empty fields take the default values
+func (cfg *Config) GetDebugEndpoints() DebugEndpoints {
+	debug := cfg.Debug
+	def := DefaultDebugEndpoints()
+	if debug.ProfileDir == "" {
+		debug.ProfileDir = def.ProfileDir
+	}
+	if debug.MaxProfileSeconds == 0 {
+		debug.MaxProfileSeconds = def.MaxProfileSeconds
+	}
+	return debug
+}
+
+// GetSignatureMaxSkew returns the accepted clock skew of the signed requests
+func (cfg *Config) GetSignatureMaxSkew() time.Duration {
+	if cfg.SignatureMaxSkew <= 0 {
+		return DefaultSignatureMaxSkew * time.Second
+	}
+	return time.Duration(cfg.SignatureMaxSkew) * time.Second
+}
+
+// GetTracingSampleRatio returns the ratio of traces to sample, 1 when unset
+func (cfg *Config) GetTracingSampleRatio() float64 {
+	if cfg.TracingSampleRatio == nil {
+		return 1
+	}
+	return *cfg.TracingSampleRatio
+}
+
+// GetAuditLog returns the audit log settings, empty fields take the default values
+func (cfg *Config) GetAuditLog() AuditLog {
+	audit := cfg.Audit
+	def := DefaultAuditLog()
+	if audit.File == "" {
+		audit.File = def.File
+	}
+	if audit.MaxSize == 0 {
+		audit.MaxSize = def.MaxSize
+	}
+	if audit.MaxBackups == 0 {
+		audit.MaxBackups = def.MaxBackups
+	}
+	if audit.MaxAge == 0 {
+		audit.MaxAge = def.MaxAge
+	}
+	return audit
+}
+
+// GetWebhooks returns the webhook settings, empty fields take the default values
+func (cfg *Config) GetWebhooks() Webhooks {
+	webhooks := cfg.Webhooks
+	def := DefaultWebhooks()
+	if webhooks.QueueDir == "" {
+		webhooks.QueueDir = def.QueueDir
+	}
+	if webhooks.QueueSize == 0 {
+		webhooks.QueueSize = def.QueueSize
+	}
+	if webhooks.MaxAttempts == 0 {
+		webhooks.MaxAttempts = def.MaxAttempts
+	}
+	if webhooks.InitialBackoff == 0 {
+		webhooks.InitialBackoff = def.InitialBackoff
+	}
+	if webhooks.MaxBackoff == 0 {
+		webhooks.MaxBackoff = def.MaxBackoff
+	}
+	if webhooks.Timeout == 0 {
+		webhooks.Timeout = def.Timeout
+	}
+	if webhooks.Workers == 0 {
+		webhooks.Workers = def.Workers
+	}
+	return webhooks
+}
+
+// GetTenant returns the tenant of the id, empty fields take the default values
+func (cfg *Config) GetTenant(id string) (Tenant, bool) {
+	t, ok := cfg.Tenants[id]
+	if !ok || id == "" {
+		return Tenant{}, false
+	}
+	if t.ResourceDir == "" {
+		t.ResourceDir = id
+	}
+	if t.CacheKeyPrefix == "" {
+		t.CacheKeyPrefix = id + ":"
+	}
+	return t, true
+}
+
+// ResolveTenant returns the tenant of a request by the id of its authenticated key, its API key secret or its site key.
+// Keys without tenant are not bound to one, empty is the default tenant.
+func (cfg *Config) ResolveTenant(keyID, apiKey, siteKey string) string {
+	if len(cfg.Tenants) == 0 {
+		return ""
+	}
+
+	if keyID != "" {
+		for _, key := range cfg.ScopedAPIKeys {
+			if key.ID == keyID && key.Tenant != "" {
+				return key.Tenant
+			}
+		}
+	} else if key, ok := cfg.LookupAPIKey(apiKey); ok && key.Tenant != "" {
+		return key.Tenant
+	}
+
+	if siteKey != "" {
+		for id, t := range cfg.Tenants {
+			for _, sk := range t.SiteKeys {
+				if sk == siteKey {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// IsTLSEnabled reports whether the HTTP and gRPC servers serve TLS
+func (cfg *Config) IsTLSEnabled() bool {
+	return cfg.TlsCertFile != "" && cfg.TlsKeyFile != ""
+}
+
+// GetHTTPAddr returns the listener address of the HTTP server, http_port on all interfaces when http_addr is empty
+func (cfg *Config) GetHTTPAddr() string {
+	if cfg.HTTPAddr != "" {
+		return cfg.HTTPAddr
+	}
+	return "tcp://:" + cfg.HTTPPort
+}
+
+// GetGRPCAddr returns the listener address of the gRPC server, grpc_port on all interfaces when grpc_addr is empty.
+// The HTTP listener serves gRPC in the single port mode.
+func (cfg *Config) GetGRPCAddr() string {
+	if cfg.SinglePort {
+		return cfg.GetHTTPAddr()
+	}
+	if cfg.GRPCAddr != "" {
+		return cfg.GRPCAddr
+	}
+	return "tcp://:" + cfg.GRPCPort
+}
+
+// GetUnixSocketMode .
+func (cfg *Config) GetUnixSocketMode() os.FileMode {
+	mode, err := listener.ParseSocketMode(cfg.UnixSocketMode)
+	if err != nil {
+		return listener.DefaultUnixSocketMode
+	}
+	return mode
+}
+
+// GetTrustedProxies returns the proxies whose forwarded headers are trusted
+func (cfg *Config) GetTrustedProxies() []string {
+	return cfg.TrustedProxies
+}
+
 // DynamicConfig .
 type DynamicConfig struct {
 	Config       Config
@@ -237,8 +1002,13 @@ func (dc *DynamicConfig) HotUpdate(cfg Config) error {
 	// Update config fields
 	dc.Config.ConfigVersion = cfg.ConfigVersion
 	dc.Config.APIKeys = cfg.APIKeys
+	dc.Config.ScopedAPIKeys = cfg.ScopedAPIKeys
+	dc.Config.SignatureMaxSkew = cfg.SignatureMaxSkew
+	dc.Config.JWTAuth = cfg.JWTAuth
+	dc.Config.Tenants = cfg.Tenants
 	dc.Config.AuthAPIs = cfg.AuthAPIs
 	dc.Config.LogLevel = cfg.LogLevel
+	dc.Config.Log.ComponentLevels = cfg.Log.ComponentLevels
 	dc.Config.CacheAddrs = cfg.CacheAddrs
 	dc.Config.CacheUsername = cfg.CacheUsername
 	dc.Config.CachePassword = cfg.CachePassword
@@ -253,6 +1023,36 @@ func (dc *DynamicConfig) HotUpdate(cfg Config) error {
 	if cfg.RateLimitBurst > 0 {
 		dc.Config.RateLimitBurst = cfg.RateLimitBurst
 	}
+	if len(cfg.RateLimitKeyBy) > 0 {
+		dc.Config.RateLimitKeyBy = cfg.RateLimitKeyBy
+	}
+	if cfg.RateLimitRules != nil {
+		dc.Config.RateLimitRules = cfg.RateLimitRules
+	}
+	if cfg.RateLimitIdleTimeout > 0 {
+		dc.Config.RateLimitIdleTimeout = cfg.RateLimitIdleTimeout
+	}
+	if cfg.RateLimitMaxKeys > 0 {
+		dc.Config.RateLimitMaxKeys = cfg.RateLimitMaxKeys
+	}
+	if cfg.RateLimitMode != "" {
+		dc.Config.RateLimitMode = cfg.RateLimitMode
+	}
+	dc.Config.TrustedProxies = cfg.TrustedProxies
+	dc.Config.IPFilter = cfg.IPFilter
+	dc.Config.IPFilterGroups = cfg.IPFilterGroups
+	dc.Config.IPFilterAPIKeys = cfg.IPFilterAPIKeys
+	dc.Config.EnableCors = cfg.EnableCors
+	dc.Config.CORS = cfg.CORS
+	dc.Config.CORSSites = cfg.CORSSites
+	if cfg.RateLimitBackendTimeout > 0 {
+		dc.Config.RateLimitBackendTimeout = cfg.RateLimitBackendTimeout
+	}
+	dc.Config.Limits.MaxBodyBytes = cfg.Limits.MaxBodyBytes
+	dc.Config.Limits.RouteBodyLimits = cfg.Limits.RouteBodyLimits
+	dc.Config.AccessLog = cfg.AccessLog
+	dc.Config.Debug = cfg.Debug
+	dc.Config.Webhooks.Endpoints = cfg.Webhooks.Endpoints
 
 	return nil
 }
@@ -322,12 +1122,31 @@ func Load(file string) (Config, error) {
 
 // Validate checks the configuration for validity
 func Validate(config Config) error {
-	if !isValidPort(config.HTTPPort) {
+	if config.HTTPAddr == "" && !isValidPort(config.HTTPPort) {
 		return fmt.Errorf("invalid http_port: %s", config.HTTPPort)
 	}
-	if !isValidPort(config.GRPCPort) {
+	if config.GRPCAddr == "" && !config.SinglePort && !isValidPort(config.GRPCPort) {
 		return fmt.Errorf("invalid grpc_port: %s", config.GRPCPort)
 	}
+	if err := validateListenAddrs(config); err != nil {
+		return err
+	}
+
+	if (config.TlsCertFile == "") != (config.TlsKeyFile == "") {
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	}
+	switch config.TlsClientAuth {
+	case "", TlsClientAuthNone:
+	case TlsClientAuthManage, TlsClientAuthAll:
+		if !config.IsTLSEnabled() || config.TlsCaFile == "" {
+			return fmt.Errorf("tls_client_auth %s requires tls_cert_file, tls_key_file and tls_ca_file", config.TlsClientAuth)
+		}
+	default:
+		return fmt.Errorf("invalid tls_client_auth: %s, must be none, manage or all", config.TlsClientAuth)
+	}
+	if config.TlsMinVersion != "" && config.TlsMinVersion != TlsVersion12 && config.TlsMinVersion != TlsVersion13 {
+		return fmt.Errorf("invalid tls_min_version: %s, must be 1.2 or 1.3", config.TlsMinVersion)
+	}
 
 	validCacheTypes := map[string]bool{
 		"redis":    true,
@@ -369,6 +1188,34 @@ func Validate(config Config) error {
 		return fmt.Errorf("invalid dynamic_config_addrs: %s", config.DynamicConfigAddrs)
 	}
 
+	if config.EnableTracing {
+		validTracingExporters := map[string]bool{
+			TracingExporterOTLPGrpc: true,
+			TracingExporterOTLPHttp: true,
+			TracingExporterStdout:   true,
+			TracingExporterFile:     true,
+		}
+		if !validTracingExporters[config.TracingExporter] {
+			return fmt.Errorf("invalid tracing_exporter: %s, must be otlp-grpc, otlp-http, stdout, or file", config.TracingExporter)
+		}
+		if (config.TracingExporter == TracingExporterOTLPGrpc || config.TracingExporter == TracingExporterOTLPHttp) && config.TracingEndpoint == "" {
+			return fmt.Errorf("tracing_endpoint is required for the %s exporter", config.TracingExporter)
+		}
+		if config.TracingExporter == TracingExporterFile && config.TracingFile == "" {
+			return fmt.Errorf("tracing_file is required for the file exporter")
+		}
+	}
+	if r := config.TracingSampleRatio; r != nil && (*r < 0 || *r > 1) {
+		return fmt.Errorf("tracing_sample_ratio must be between 0 and 1: %v", *r)
+	}
+
+	if config.GenerateQueueSize < 0 {
+		return fmt.Errorf("generate_queue_size must not be negative: %d", config.GenerateQueueSize)
+	}
+	if config.ReadinessQueueThreshold < 0 {
+		return fmt.Errorf("readiness_queue_threshold must not be negative: %v", config.ReadinessQueueThreshold)
+	}
+
 	if config.RateLimitQPS <= 0 {
 		return fmt.Errorf("rate_limit_qps must be positive: %d", config.RateLimitQPS)
 	}
@@ -376,6 +1223,75 @@ func Validate(config Config) error {
 		return fmt.Errorf("rate_limit_burst must be positive: %d", config.RateLimitBurst)
 	}
 
+	validRateLimitKeys := map[string]bool{
+		RateLimitKeyIP:      true,
+		RateLimitKeyAPIKey:  true,
+		RateLimitKeySiteKey: true,
+	}
+	for _, key := range config.RateLimitKeyBy {
+		if !validRateLimitKeys[key] {
+			return fmt.Errorf("invalid rate_limit_key_by: %s, must be ip, api_key, or site_key", key)
+		}
+	}
+	if err := validateRateLimitRules("rate_limit_rules", config.RateLimitRules); err != nil {
+		return err
+	}
+	for _, proxy := range config.TrustedProxies {
+		if !isValidIPOrCIDR(proxy) {
+			return fmt.Errorf("invalid trusted_proxies: %s", proxy)
+		}
+	}
+	if config.RateLimitIdleTimeout < 0 {
+		return fmt.Errorf("rate_limit_idle_timeout must not be negative: %d", config.RateLimitIdleTimeout)
+	}
+	if config.RateLimitMaxKeys < 0 {
+		return fmt.Errorf("rate_limit_max_keys must not be negative: %d", config.RateLimitMaxKeys)
+	}
+	switch config.RateLimitMode {
+	case "", RateLimitModeLocal:
+	case RateLimitModeDistributed:
+		if config.CacheType != "redis" && config.CacheType != "etcd" {
+			return fmt.Errorf("rate_limit_mode distributed requires the redis or etcd cache_type: %s", config.CacheType)
+		}
+	default:
+		return fmt.Errorf("invalid rate_limit_mode: %s, must be local or distributed", config.RateLimitMode)
+	}
+	if config.RateLimitBackendTimeout < 0 {
+		return fmt.Errorf("rate_limit_backend_timeout must not be negative: %d", config.RateLimitBackendTimeout)
+	}
+
+	if err := validateIPFilterRule("ip_filter", config.IPFilter); err != nil {
+		return err
+	}
+	for group, rule := range config.IPFilterGroups {
+		if group != IPFilterGroupPublic && group != IPFilterGroupManage {
+			return fmt.Errorf("invalid ip_filter_groups group: %s, must be public or manage", group)
+		}
+		if err := validateIPFilterRule("ip_filter_groups."+group, rule); err != nil {
+			return err
+		}
+	}
+	for key, rule := range config.IPFilterAPIKeys {
+		if err := validateIPFilterRule("ip_filter_api_keys", rule); err != nil {
+			return err
+		}
+		if key == "" {
+			return fmt.Errorf("ip_filter_api_keys contain empty key")
+		}
+		if !config.HasAPIKeyID(key) {
+			return fmt.Errorf("ip_filter_api_keys contain unknown API key id: %s", key)
+		}
+	}
+
+	if err := validateCORSRule("cors", config.CORS); err != nil {
+		return err
+	}
+	for site, rule := range config.CORSSites {
+		if err := validateCORSRule("cors_sites."+site, rule); err != nil {
+			return err
+		}
+	}
+
 	if len(config.APIKeys) > 0 {
 		for _, key := range config.APIKeys {
 			if key == "" {
@@ -384,6 +1300,359 @@ func Validate(config Config) error {
 		}
 	}
 
+	if err := validateAPIKeys(config.ScopedAPIKeys); err != nil {
+		return err
+	}
+	if config.SignatureMaxSkew < 0 {
+		return fmt.Errorf("signature_max_skew must not be negative: %d", config.SignatureMaxSkew)
+	}
+	if err := validateJWTAuth(config.JWTAuth); err != nil {
+		return err
+	}
+	if err := validateTenants(config.Tenants, config.ScopedAPIKeys); err != nil {
+		return err
+	}
+	if err := validateAdminListener(config); err != nil {
+		return err
+	}
+	if err := validateServerLimits(config.Limits); err != nil {
+		return err
+	}
+	if err := validateAccessLog(config.AccessLog); err != nil {
+		return err
+	}
+	if err := validateLog(config); err != nil {
+		return err
+	}
+	if config.Debug.MaxProfileSeconds < 0 {
+		return fmt.Errorf("debug.max_profile_seconds must not be negative: %d", config.Debug.MaxProfileSeconds)
+	}
+	for name, v := range map[string]int{
+		"max_size":    config.Audit.MaxSize,
+		"max_backups": config.Audit.MaxBackups,
+		"max_age":     config.Audit.MaxAge,
+	} {
+		if v < 0 {
+			return fmt.Errorf("audit.%s must not be negative: %d", name, v)
+		}
+	}
+	if err := validateWebhooks(config.Webhooks); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateServerLimits checks the timeouts and the size limits of the servers
+func validateServerLimits(limits ServerLimits) error {
+	for name, v := range map[string]int{
+		"read_header_timeout":     limits.ReadHeaderTimeout,
+		"read_timeout":            limits.ReadTimeout,
+		"write_timeout":           limits.WriteTimeout,
+		"idle_timeout":            limits.IdleTimeout,
+		"max_header_bytes":        limits.MaxHeaderBytes,
+		"grpc_max_recv_msg_size":  limits.GRPCMaxRecvMsgSize,
+		"grpc_max_send_msg_size":  limits.GRPCMaxSendMsgSize,
+		"grpc_keepalive_time":     limits.GRPCKeepaliveTime,
+		"grpc_keepalive_timeout":  limits.GRPCKeepaliveTimeout,
+		"grpc_keepalive_min_time": limits.GRPCKeepaliveMinTime,
+	} {
+		if v < 0 {
+			return fmt.Errorf("limits.%s must not be negative: %d", name, v)
+		}
+	}
+	if limits.MaxBodyBytes < 0 {
+		return fmt.Errorf("limits.max_body_bytes must not be negative: %d", limits.MaxBodyBytes)
+	}
+	for route, limit := range limits.RouteBodyLimits {
+		if route == "" || strings.Contains(route, "/") {
+			return fmt.Errorf("invalid limits.route_body_limits route: %q, must be the last segment of the route path such as check-data", route)
+		}
+		if limit <= 0 {
+			return fmt.Errorf("limits.route_body_limits.%s must be positive: %d", route, limit)
+		}
+	}
+	return nil
+}
+
+// validateAccessLog checks the access log fields and the sample rate
+func validateAccessLog(accessLog AccessLog) error {
+	for _, field := range accessLog.Fields {
+		switch field {
+		case AccessLogFieldMethod, AccessLogFieldPath, AccessLogFieldStatus, AccessLogFieldClient, AccessLogFieldRequestID,
+			AccessLogFieldKeyID, AccessLogFieldCaptchaID, AccessLogFieldCaptchaType, AccessLogFieldCaptchaKey,
+			AccessLogFieldBytes, AccessLogFieldDuration, AccessLogFieldUserAgent, AccessLogFieldError:
+		default:
+			return fmt.Errorf("invalid access_log.fields field: %s", field)
+		}
+	}
+	if accessLog.SuccessSampleRate < 0 || accessLog.SuccessSampleRate > 1 {
+		return fmt.Errorf("access_log.success_sample_rate must be between 0 and 1: %v", accessLog.SuccessSampleRate)
+	}
+	return nil
+}
+
+// validateWebhooks checks the webhook endpoints and the queue settings
+func validateWebhooks(webhooks Webhooks) error {
+	ids := make(map[string]struct{}, len(webhooks.Endpoints))
+	for _, endpoint := range webhooks.Endpoints {
+		if endpoint.ID == "" {
+			return fmt.Errorf("webhooks.endpoints id is required")
+		}
+		if _, ok := ids[endpoint.ID]; ok {
+			return fmt.Errorf("duplicate webhooks.endpoints id: %s", endpoint.ID)
+		}
+		ids[endpoint.ID] = struct{}{}
+		if !strings.HasPrefix(endpoint.URL, "https://") && !strings.HasPrefix(endpoint.URL, "http://") {
+			return fmt.Errorf("invalid webhooks.endpoints url of %s: %s", endpoint.ID, endpoint.URL)
+		}
+		for _, event := range endpoint.Events {
+			switch event {
+			case WebhookEventAll, WebhookEventCaptchaVerified, WebhookEventCaptchaFailed, WebhookEventCaptchaLocked,
+				WebhookEventConfigUpdated, WebhookEventResourceUploaded, WebhookEventResourceDeleted:
+			default:
+				return fmt.Errorf("invalid webhooks.endpoints event of %s: %s", endpoint.ID, event)
+			}
+		}
+	}
+
+	for name, v := range map[string]int{
+		"queue_size":      webhooks.QueueSize,
+		"max_attempts":    webhooks.MaxAttempts,
+		"initial_backoff": webhooks.InitialBackoff,
+		"max_backoff":     webhooks.MaxBackoff,
+		"timeout":         webhooks.Timeout,
+		"workers":         webhooks.Workers,
+	} {
+		if v < 0 {
+			return fmt.Errorf("webhooks.%s must not be negative: %d", name, v)
+		}
+	}
+	return nil
+}
+
+// validateLog checks the log levels and the log output
+func validateLog(config Config) error {
+	if config.LogLevel != "" && !isValidLogLevel(config.LogLevel) {
+		return fmt.Errorf("invalid log_level: %s, must be debug, info, warn, error or none", config.LogLevel)
+	}
+	for component, level := range config.Log.ComponentLevels {
+		switch component {
+		case LogComponentCache, LogComponentConfig, LogComponentHTTP, LogComponentGRPC:
+		default:
+			return fmt.Errorf("invalid log.component_levels component: %s, must be cache, config, http or grpc", component)
+		}
+		if !isValidLogLevel(level) {
+			return fmt.Errorf("invalid log.component_levels.%s level: %s, must be debug, info, warn, error or none", component, level)
+		}
+	}
+
+	logCfg := config.Log
+	if logCfg.Encoding != "" && logCfg.Encoding != LogEncodingJSON && logCfg.Encoding != LogEncodingConsole {
+		return fmt.Errorf("invalid log.encoding: %s, must be json or console", logCfg.Encoding)
+	}
+	switch logCfg.Output {
+	case "", LogOutputStdout, LogOutputStderr:
+	case LogOutputFile:
+		if logCfg.File == "" {
+			return fmt.Errorf("log.file is required by the file output")
+		}
+	default:
+		return fmt.Errorf("invalid log.output: %s, must be stdout, stderr or file", logCfg.Output)
+	}
+	for name, v := range map[string]int{
+		"max_size":            logCfg.MaxSize,
+		"max_backups":         logCfg.MaxBackups,
+		"max_age":             logCfg.MaxAge,
+		"rotate_interval":     logCfg.RotateInterval,
+		"sampling.initial":    logCfg.Sampling.Initial,
+		"sampling.thereafter": logCfg.Sampling.Thereafter,
+	} {
+		if v < 0 {
+			return fmt.Errorf("log.%s must not be negative: %d", name, v)
+		}
+	}
+	return nil
+}
+
+// isValidLogLevel .
+func isValidLogLevel(level string) bool {
+	switch level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError, LogLevelNone:
+		return true
+	}
+	return false
+}
+
+// validateListenAddrs checks the listener addresses of the servers, they must differ from each other
+func validateListenAddrs(config Config) error {
+	addrs := map[string]string{"http_addr": config.GetHTTPAddr()}
+	if !config.SinglePort {
+		addrs["grpc_addr"] = config.GetGRPCAddr()
+	}
+	if config.Admin.Enable {
+		addrs["admin.http_addr"] = config.Admin.GetHTTPAddr()
+		if !config.SinglePort {
+			addrs["admin.grpc_addr"] = config.Admin.GetGRPCAddr()
+		}
+	}
+
+	type bound struct{ name, network, host, port string }
+	var used []bound
+	for name, addr := range addrs {
+		network, address, err := listener.Parse(addr)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+		b := bound{name: name, network: network, host: address}
+		if network == listener.SchemeTCP {
+			b.host, b.port, _ = net.SplitHostPort(address)
+		}
+		for _, other := range used {
+			if other.network != b.network || other.port != b.port {
+				continue
+			}
+			// A listener on all interfaces conflicts with the others of its port
+			if other.host == b.host || isWildcardHost(other.host) || isWildcardHost(b.host) {
+				return fmt.Errorf("%s and %s listen on the same address", other.name, name)
+			}
+		}
+		used = append(used, b)
+	}
+
+	if _, err := listener.ParseSocketMode(config.UnixSocketMode); err != nil {
+		return fmt.Errorf("invalid unix_socket_mode: %v", err)
+	}
+	return nil
+}
+
+// isWildcardHost .
+func isWildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+// validateAdminListener checks the ports and the TLS files of the admin listener
+func validateAdminListener(config Config) error {
+	admin := config.Admin
+	if !admin.Enable {
+		return nil
+	}
+	if admin.HTTPAddr == "" && !isValidPort(admin.HTTPPort) {
+		return fmt.Errorf("invalid admin.http_port: %s", admin.HTTPPort)
+	}
+	if admin.GRPCAddr == "" && !config.SinglePort && !isValidPort(admin.GRPCPort) {
+		return fmt.Errorf("invalid admin.grpc_port: %s", admin.GRPCPort)
+	}
+	if (admin.TlsCertFile == "") != (admin.TlsKeyFile == "") {
+		return fmt.Errorf("admin.tls_cert_file and admin.tls_key_file must be set together")
+	}
+	if admin.TlsCaFile != "" && !admin.IsTLSEnabled() {
+		return fmt.Errorf("admin.tls_ca_file requires admin.tls_cert_file and admin.tls_key_file")
+	}
+	if admin.TlsMinVersion != "" && admin.TlsMinVersion != TlsVersion12 && admin.TlsMinVersion != TlsVersion13 {
+		return fmt.Errorf("invalid admin.tls_min_version: %s, must be 1.2 or 1.3", admin.TlsMinVersion)
+	}
+	return nil
+}
+
+// validateRateLimitRules .
+func validateRateLimitRules(name string, rules map[string]RateLimitRule) error {
+	for scope, rule := range rules {
+		switch scope {
+		case RateLimitScopeGetData, RateLimitScopeCheckData, RateLimitScopeManage, RateLimitScopeDefault:
+		default:
+			return fmt.Errorf("invalid %s scope: %s, must be get_data, check_data, manage, or default", name, scope)
+		}
+		if rule.QPS < 0 || rule.Burst < 0 || (rule.QPS > 0 && rule.Burst == 0) {
+			return fmt.Errorf("invalid %s.%s: qps must not be negative and burst must be positive", name, scope)
+		}
+	}
+	return nil
+}
+
+// validateTenants checks the ids, site keys, resource directories and rate limits of the tenants
+func validateTenants(tenants map[string]Tenant, keys []APIKey) error {
+	idRegex := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	siteKeys := make(map[string]string)
+	for id, t := range tenants {
+		if !idRegex.MatchString(id) {
+			return fmt.Errorf("invalid tenants id: %s, must contain only letters, digits, _ and -", id)
+		}
+		for _, sk := range t.SiteKeys {
+			if other, ok := siteKeys[sk]; ok && other != id {
+				return fmt.Errorf("site key %s belongs to tenants %s and %s", sk, other, id)
+			}
+			siteKeys[sk] = id
+		}
+		if dir := filepath.Clean(t.ResourceDir); t.ResourceDir != "" && (filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator))) {
+			return fmt.Errorf("invalid tenants.%s.resource_dir: %s, must be a subdirectory of the resources directory", id, t.ResourceDir)
+		}
+		if err := validateRateLimitRules("tenants."+id+".rate_limit_rules", t.RateLimitRules); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range keys {
+		if _, ok := tenants[key.Tenant]; key.Tenant != "" && !ok {
+			return fmt.Errorf("scoped_api_keys.%s.tenant %s does not exist", key.ID, key.Tenant)
+		}
+	}
+	return nil
+}
+
+// validateAPIKeys checks the ids, secret hashes, scopes and expiry of the API keys
+func validateAPIKeys(keys []APIKey) error {
+	ids := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return fmt.Errorf("scoped_api_keys contain a key without id")
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("duplicate scoped_api_keys id: %s", key.ID)
+		}
+		ids[key.ID] = true
+
+		if key.SecretHash == "" && key.SigningSecret == "" {
+			return fmt.Errorf("scoped_api_keys.%s needs a secret_hash or a signing_secret", key.ID)
+		}
+		hash := strings.TrimPrefix(key.SecretHash, "sha256:")
+		if _, err := hex.DecodeString(hash); key.SecretHash != "" && (err != nil || len(hash) != sha256.Size*2) {
+			return fmt.Errorf("invalid scoped_api_keys.%s.secret_hash, must be a hex SHA-256", key.ID)
+		}
+		for _, scope := range key.Scopes {
+			if !IsValidScope(scope) {
+				return fmt.Errorf("invalid scoped_api_keys.%s.scopes: %s", key.ID, scope)
+			}
+		}
+		if key.ExpiresAt != "" {
+			if _, err := time.Parse(time.RFC3339, key.ExpiresAt); err != nil {
+				return fmt.Errorf("invalid scoped_api_keys.%s.expires_at: %s, must be RFC 3339", key.ID, key.ExpiresAt)
+			}
+		}
+	}
+	return nil
+}
+
+// validateJWTAuth checks the bearer token authentication
+func validateJWTAuth(auth JWTAuth) error {
+	if !auth.Enable {
+		return nil
+	}
+	if auth.Issuer == "" {
+		return fmt.Errorf("jwt_auth.issuer is required")
+	}
+	if len(auth.Audience) == 0 {
+		return fmt.Errorf("jwt_auth.audience is required")
+	}
+	if auth.JWKSFile == "" && auth.JWKSURL == "" {
+		return fmt.Errorf("jwt_auth needs a jwks_file or a jwks_url")
+	}
+	if auth.JWKSURL != "" && !strings.HasPrefix(auth.JWKSURL, "https://") && !strings.HasPrefix(auth.JWKSURL, "http://") {
+		return fmt.Errorf("invalid jwt_auth.jwks_url: %s", auth.JWKSURL)
+	}
+	if auth.JWKSRefreshInterval < 0 || auth.Leeway < 0 {
+		return fmt.Errorf("jwt_auth.jwks_refresh_interval and jwt_auth.leeway must not be negative")
+	}
 	return nil
 }
 
@@ -402,6 +1671,43 @@ func isValidAddrs(addrs string) bool {
 	return addrRegex.MatchString(addrs)
 }
 
+// validateIPFilterRule checks the addresses of an ip filter rule
+func validateIPFilterRule(name string, rule IPFilterRule) error {
+	for _, value := range append(append([]string{}, rule.Allow...), rule.Deny...) {
+		if !isValidIPOrCIDR(value) {
+			return fmt.Errorf("invalid %s address: %s", name, value)
+		}
+	}
+	return nil
+}
+
+// validateCORSRule checks a cross-origin policy
+func validateCORSRule(name string, rule CORSRule) error {
+	for _, origin := range rule.AllowedOrigins {
+		if origin == "*" {
+			if rule.AllowCredentials {
+				return fmt.Errorf("%s: allow_credentials cannot be used with the \"*\" origin", name)
+			}
+			continue
+		}
+		if strings.Count(origin, "*") > 1 || (strings.Contains(origin, "*") && !strings.Contains(origin, "://*.")) {
+			return fmt.Errorf("invalid %s.allowed_origins: %s, wildcards are only allowed as a subdomain such as https://*.example.com", name, origin)
+		}
+	}
+	if rule.MaxAge < 0 {
+		return fmt.Errorf("%s.max_age must not be negative: %d", name, rule.MaxAge)
+	}
+	return nil
+}
+
+// isValidIPOrCIDR checks if the value is an IP address or a CIDR block
+func isValidIPOrCIDR(value string) bool {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return true
+	}
+	return net.ParseIP(value) != nil
+}
+
 // MergeWithFlags merges command-line flags into the configuration
 func MergeWithFlags(config Config, flags map[string]interface{}) Config {
 	if v, ok := flags["http-port"].(string); ok && v != "" {
@@ -416,6 +1722,21 @@ func MergeWithFlags(config Config, flags map[string]interface{}) Config {
 	if v, ok := flags["grpc-port"].(string); ok && v != "" {
 		config.GRPCPort = v
 	}
+	if v, ok := flags["http-addr"].(string); ok && v != "" {
+		config.HTTPAddr = v
+	}
+	if v, ok := flags["grpc-addr"].(string); ok && v != "" {
+		config.GRPCAddr = v
+	}
+	if v, ok := flags["unix-socket-mode"].(string); ok && v != "" {
+		config.UnixSocketMode = v
+	}
+	if v, ok := flags["single-port"].(string); ok && !config.SinglePort {
+		config.SinglePort = v == "true"
+	}
+	if v, ok := flags["enable-grpc-web"].(string); ok && !config.EnableGRPCWeb {
+		config.EnableGRPCWeb = v == "true"
+	}
 	if v, ok := flags["cache-addrs"].(string); ok && v != "" {
 		config.CacheAddrs = v
 	}
@@ -533,6 +1854,21 @@ func MergeWithFlags(config Config, flags map[string]interface{}) Config {
 	if v, ok := flags["rate-limit-burst"].(int); ok && v != 0 {
 		config.RateLimitBurst = v
 	}
+	if v, ok := flags["rate-limit-key-by"].(string); ok && v != "" {
+		config.RateLimitKeyBy = strings.Split(v, ",")
+	}
+	if v, ok := flags["trusted-proxies"].(string); ok && v != "" {
+		config.TrustedProxies = strings.Split(v, ",")
+	}
+	if v, ok := flags["ip-allow"].(string); ok && v != "" {
+		config.IPFilter.Allow = strings.Split(v, ",")
+	}
+	if v, ok := flags["ip-deny"].(string); ok && v != "" {
+		config.IPFilter.Deny = strings.Split(v, ",")
+	}
+	if v, ok := flags["rate-limit-mode"].(string); ok && v != "" {
+		config.RateLimitMode = v
+	}
 	if v, ok := flags["api-keys"].(string); ok && v != "" {
 		config.APIKeys = strings.Split(v, ",")
 	}
@@ -543,35 +1879,216 @@ func MergeWithFlags(config Config, flags map[string]interface{}) Config {
 		config.LogLevel = v
 	}
 
+	if v, ok := flags["tls-cert-file"].(string); ok && v != "" {
+		config.TlsCertFile = v
+	}
+	if v, ok := flags["tls-key-file"].(string); ok && v != "" {
+		config.TlsKeyFile = v
+	}
+	if v, ok := flags["tls-ca-file"].(string); ok && v != "" {
+		config.TlsCaFile = v
+	}
+	if v, ok := flags["tls-client-auth"].(string); ok && v != "" {
+		config.TlsClientAuth = v
+	}
+	if v, ok := flags["tls-min-version"].(string); ok && v != "" {
+		config.TlsMinVersion = v
+	}
+
+	if v, ok := flags["enable-admin"].(string); ok && !config.Admin.Enable {
+		config.Admin.Enable = v == "true"
+	}
+	if v, ok := flags["admin-bind-addr"].(string); ok && v != "" {
+		config.Admin.BindAddr = v
+	}
+	if v, ok := flags["admin-http-port"].(string); ok && v != "" {
+		config.Admin.HTTPPort = v
+	}
+	if v, ok := flags["admin-grpc-port"].(string); ok && v != "" {
+		config.Admin.GRPCPort = v
+	}
+
 	if v, ok := flags["enable-cors"].(string); ok {
 		config.EnableCors = v == "true"
 	}
+	if v, ok := flags["cors-allowed-origins"].(string); ok && v != "" {
+		config.CORS.AllowedOrigins = strings.Split(v, ",")
+	}
+
+	///////
+	if v, ok := flags["enable-tracing"].(string); ok && !config.EnableTracing {
+		config.EnableTracing = v == "true"
+	}
+	if v, ok := flags["tracing-exporter"].(string); ok && v != "" {
+		config.TracingExporter = v
+	}
+	if v, ok := flags["tracing-endpoint"].(string); ok && v != "" {
+		config.TracingEndpoint = v
+	}
+	if v, ok := flags["tracing-sample-ratio"].(float64); ok && v >= 0 {
+		config.TracingSampleRatio = &v
+	}
+	if v, ok := flags["tracing-file"].(string); ok && v != "" {
+		config.TracingFile = v
+	}
 	return config
 }
 
 func DefaultConfig() Config {
 	return Config{
-		ServiceName:            "go-captcha-service",
-		ServiceNode:            1,
-		HTTPPort:               "8080",
-		GRPCPort:               "50051",
-		CacheType:              "memory",
-		CacheAddrs:             "",
-		CacheDB:                "0",
-		CacheTTL:               60,
-		CacheKeyPrefix:         "GO_CAPTCHA_DATA:",
-		EnableDynamicConfig:    false,
-		EnableServiceDiscovery: false,
-		RateLimitQPS:           1024,
-		RateLimitBurst:         1024,
-		EnableCors:             true,
-		APIKeys:                make([]string, 0),
-		AuthAPIs:               getDefaultAuthAPIs(),
-		LogLevel:               "info",
+		ServiceName:             "go-captcha-service",
+		ServiceNode:             1,
+		HTTPPort:                "8080",
+		GRPCPort:                "50051",
+		TlsClientAuth:           TlsClientAuthNone,
+		TlsMinVersion:           TlsVersion12,
+		CacheType:               "memory",
+		CacheAddrs:              "",
+		CacheDB:                 "0",
+		CacheTTL:                60,
+		CacheKeyPrefix:          "GO_CAPTCHA_DATA:",
+		EnableDynamicConfig:     false,
+		EnableServiceDiscovery:  false,
+		RateLimitQPS:            1024,
+		RateLimitBurst:          1024,
+		EnableCors:              true,
+		APIKeys:                 make([]string, 0),
+		ScopedAPIKeys:           make([]APIKey, 0),
+		SignatureMaxSkew:        DefaultSignatureMaxSkew,
+		AuthAPIs:                getDefaultAuthAPIs(),
+		LogLevel:                "info",
+		TracingExporter:         TracingExporterOTLPGrpc,
+		TracingEndpoint:         "localhost:4317",
+		TracingInsecure:         true,
+		GenerateQueueSize:       256,
+		HealthCheckTimeout:      3,
+		ReadinessQueueThreshold: 0.9,
+		RateLimitKeyBy:          []string{RateLimitKeyIP},
+		RateLimitRules: map[string]RateLimitRule{
+			RateLimitScopeGetData:   {QPS: 20, Burst: 40},
+			RateLimitScopeCheckData: {QPS: 20, Burst: 40},
+			RateLimitScopeManage:    {QPS: 10, Burst: 20},
+		},
+		RateLimitIdleTimeout:    600,
+		RateLimitMaxKeys:        100000,
+		RateLimitMode:           RateLimitModeLocal,
+		CORS:                    DefaultCORSRule(),
+		RateLimitBackendTimeout: 100,
+		Admin: AdminListener{
+			BindAddr:      "127.0.0.1",
+			HTTPPort:      "8081",
+			GRPCPort:      "50052",
+			TlsMinVersion: TlsVersion12,
+		},
+		Limits:    DefaultServerLimits(),
+		AccessLog: DefaultAccessLog(),
+		Log:       DefaultLogConfig(),
+		Debug:     DefaultDebugEndpoints(),
+		Audit:     DefaultAuditLog(),
+		Webhooks:  DefaultWebhooks(),
+	}
+}
+
+// DefaultServerLimits guards against slow clients and large requests,
+// uploads get a large body limit and the captcha verification a small one
+func DefaultServerLimits() ServerLimits {
+	return ServerLimits{
+		ReadHeaderTimeout: 5,
+		ReadTimeout:       30,
+		WriteTimeout:      30,
+		IdleTimeout:       120,
+		MaxHeaderBytes:    1 << 20,
+		MaxBodyBytes:      1 << 20,
+		RouteBodyLimits: map[string]int64{
+			"check-data":      64 << 10,
+			"upload-resource": 10 << 20,
+		},
+		GRPCMaxRecvMsgSize:   4 << 20,
+		GRPCMaxSendMsgSize:   16 << 20,
+		GRPCKeepaliveTime:    120,
+		GRPCKeepaliveTimeout: 20,
+		GRPCKeepaliveMinTime: 10,
+	}
+}
+
+// DefaultAccessLog logs every request with all the fields but the user agent
+func DefaultAccessLog() AccessLog {
+	return AccessLog{
+		Fields: []string{
+			AccessLogFieldMethod, AccessLogFieldPath, AccessLogFieldStatus, AccessLogFieldClient, AccessLogFieldRequestID,
+			AccessLogFieldKeyID, AccessLogFieldCaptchaID, AccessLogFieldCaptchaType, AccessLogFieldCaptchaKey,
+			AccessLogFieldBytes, AccessLogFieldDuration, AccessLogFieldError,
+		},
+		SuccessSampleRate: 1,
+	}
+}
+
+// DefaultLogConfig writes JSON logs to stdout, the log files are rotated at 100 MB and kept for 30 days
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Encoding:   LogEncodingJSON,
+		Output:     LogOutputStdout,
+		MaxSize:    100,
+		MaxBackups: 7,
+		MaxAge:     30,
+		Sampling:   LogSampling{Initial: 100, Thereafter: 100},
+	}
+}
+
+// DefaultDebugEndpoints keeps the debug endpoints disabled, the profiles are captured for 60 seconds at most
+func DefaultDebugEndpoints() DebugEndpoints {
+	return DebugEndpoints{
+		ProfileDir:        "profiles",
+		MaxProfileSeconds: 60,
+	}
+}
+
+// DefaultAuditLog records the management operations to audit/audit.log, rotated at 100 MB and kept for 180 days
+func DefaultAuditLog() AuditLog {
+	return AuditLog{
+		Enable:     true,
+		File:       "audit/audit.log",
+		MaxSize:    100,
+		MaxBackups: 30,
+		MaxAge:     180,
+		Compress:   true,
+	}
+}
+
+// DefaultWebhooks queues up to 1000 deliveries in the webhook directory,
+// a delivery is retried 8 times with a backoff from 1 second to 5 minutes
+func DefaultWebhooks() Webhooks {
+	return Webhooks{
+		QueueDir:       "webhook",
+		QueueSize:      1000,
+		MaxAttempts:    8,
+		InitialBackoff: 1,
+		MaxBackoff:     300,
+		Timeout:        5,
+		Workers:        4,
+	}
+}
+
+// DefaultCORSRule .
+func DefaultCORSRule() CORSRule {
+	return CORSRule{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Site-Key", "X-Request-ID", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"},
+		MaxAge:         86400,
 	}
 }
 
 // getDefaultAuthAPIs ..
+// AuthRateLimitPath is the global rate limit control, it always requires authentication
+const AuthRateLimitPath = "/rate-limit"
+
+// authAPIPrefixes are the route prefixes that always require authentication
+var authAPIPrefixes = []string{
+	"/api/v1/manage/",
+	"/gocaptcha.GoCaptchaManageService/",
+}
+
 func getDefaultAuthAPIs() []string {
 	return []string{
 		// http
@@ -582,8 +2099,19 @@ func getDefaultAuthAPIs() []string {
 		"/api/v1/manage/get-resource-list",
 		"/api/v1/manage/get-config",
 		"/api/v1/manage/update-hot-config",
+		"/api/v1/manage/get-tenant-stats",
+		"/api/v1/manage/get-audit-log",
+		"/api/v1/manage/get-webhook-status",
+		"/api/v1/manage/rpc/get-status-info",
+		"/api/v1/manage/rpc/del-status-info",
+		AuthRateLimitPath,
 		// grpc
 		"/gocaptcha.GoCaptchaService/GetStatusInfo",
 		"/gocaptcha.GoCaptchaService/DelStatusInfo",
+		"/gocaptcha.GoCaptchaManageService/UploadResource",
+		"/gocaptcha.GoCaptchaManageService/DeleteResource",
+		"/gocaptcha.GoCaptchaManageService/GetResourceList",
+		"/gocaptcha.GoCaptchaManageService/GetConfig",
+		"/gocaptcha.GoCaptchaManageService/UpdateHotConfig",
 	}
 }
@@ -15,3 +15,20 @@ const (
 	GoCaptchaTypeDrag       = 4
 	GoCaptchaTypeRotate     = 5
 )
+
+// GoCaptchaTypeName returns the name of the captcha type
+func GoCaptchaTypeName(ttype int) string {
+	switch ttype {
+	case GoCaptchaTypeClick:
+		return "click"
+	case GoCaptchaTypeClickShape:
+		return "click_shape"
+	case GoCaptchaTypeSlide:
+		return "slide"
+	case GoCaptchaTypeDrag:
+		return "drag"
+	case GoCaptchaTypeRotate:
+		return "rotate"
+	}
+	return "unknown"
+}
@@ -0,0 +1,194 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wenlng/go-captcha-service/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is set as the domain of the gRPC error info detail
+const Domain = "gocaptcha"
+
+// Reason is the stable machine-readable code of an error, it matches the name of proto.ErrorReason
+type Reason string
+
+// Reasons .
+const (
+	ReasonInvalidArgument        Reason = "INVALID_ARGUMENT"
+	ReasonMethodNotAllowed       Reason = "METHOD_NOT_ALLOWED"
+	ReasonUnauthenticated        Reason = "UNAUTHENTICATED"
+	ReasonRateLimited            Reason = "RATE_LIMITED"
+	ReasonServiceUnavailable     Reason = "SERVICE_UNAVAILABLE"
+	ReasonInternal               Reason = "INTERNAL"
+	ReasonCaptchaTypeNotFound    Reason = "CAPTCHA_TYPE_NOT_FOUND"
+	ReasonCaptchaNotFound        Reason = "CAPTCHA_NOT_FOUND"
+	ReasonCaptchaExpired         Reason = "CAPTCHA_EXPIRED"
+	ReasonCaptchaAlreadyUsed     Reason = "CAPTCHA_ALREADY_USED"
+	ReasonCaptchaAnswerIncorrect Reason = "CAPTCHA_ANSWER_INCORRECT"
+	ReasonCaptchaGenerateFailed  Reason = "CAPTCHA_GENERATE_FAILED"
+	ReasonCaptchaGenerateBusy    Reason = "CAPTCHA_GENERATE_BUSY"
+	ReasonCacheUnavailable       Reason = "CACHE_UNAVAILABLE"
+	ReasonResourceInvalidPath    Reason = "RESOURCE_INVALID_PATH"
+	ReasonResourceTooLarge       Reason = "RESOURCE_TOO_LARGE"
+	ReasonConfigInvalid          Reason = "CONFIG_INVALID"
+	ReasonForbidden              Reason = "FORBIDDEN"
+	ReasonNotFound               Reason = "NOT_FOUND"
+)
+
+// Error is an entry of the error catalogue
+type Error struct {
+	Reason     Reason
+	Message    string
+	HTTPStatus int
+	GRPCCode   codes.Code
+
+	cause      error
+	retryAfter time.Duration
+}
+
+// Catalogue .
+var (
+	ErrInvalidArgument        = newError(ReasonInvalidArgument, "invalid argument", http.StatusBadRequest, codes.InvalidArgument)
+	ErrMethodNotAllowed       = newError(ReasonMethodNotAllowed, "method not allowed", http.StatusMethodNotAllowed, codes.Unimplemented)
+	ErrUnauthenticated        = newError(ReasonUnauthenticated, "unauthenticated", http.StatusUnauthorized, codes.Unauthenticated)
+	ErrRateLimited            = newError(ReasonRateLimited, "rate limit exceeded", http.StatusTooManyRequests, codes.ResourceExhausted)
+	ErrServiceUnavailable     = newError(ReasonServiceUnavailable, "service unavailable", http.StatusServiceUnavailable, codes.Unavailable)
+	ErrInternal               = newError(ReasonInternal, "internal server error", http.StatusInternalServerError, codes.Internal)
+	ErrCaptchaTypeNotFound    = newError(ReasonCaptchaTypeNotFound, "captcha type not found", http.StatusNotFound, codes.NotFound)
+	ErrCaptchaNotFound        = newError(ReasonCaptchaNotFound, "captcha not found", http.StatusNotFound, codes.NotFound)
+	ErrCaptchaExpired         = newError(ReasonCaptchaExpired, "captcha expired", http.StatusGone, codes.NotFound)
+	ErrCaptchaAlreadyUsed     = newError(ReasonCaptchaAlreadyUsed, "captcha already used", http.StatusConflict, codes.FailedPrecondition)
+	ErrCaptchaAnswerIncorrect = newError(ReasonCaptchaAnswerIncorrect, "captcha answer incorrect", http.StatusBadRequest, codes.InvalidArgument)
+	ErrCaptchaGenerateFailed  = newError(ReasonCaptchaGenerateFailed, "failed to generate captcha", http.StatusInternalServerError, codes.Internal)
+	ErrCaptchaGenerateBusy    = newError(ReasonCaptchaGenerateBusy, "captcha generation is busy", http.StatusServiceUnavailable, codes.ResourceExhausted)
+	ErrCacheUnavailable       = newError(ReasonCacheUnavailable, "cache unavailable", http.StatusServiceUnavailable, codes.Unavailable)
+	ErrResourceInvalidPath    = newError(ReasonResourceInvalidPath, "invalid resource path", http.StatusBadRequest, codes.InvalidArgument)
+	ErrResourceTooLarge       = newError(ReasonResourceTooLarge, "resource too large", http.StatusRequestEntityTooLarge, codes.ResourceExhausted)
+	ErrConfigInvalid          = newError(ReasonConfigInvalid, "invalid config", http.StatusBadRequest, codes.InvalidArgument)
+	ErrForbidden              = newError(ReasonForbidden, "forbidden", http.StatusForbidden, codes.PermissionDenied)
+	ErrNotFound               = newError(ReasonNotFound, "not found", http.StatusNotFound, codes.NotFound)
+)
+
+// newError .
+func newError(reason Reason, message string, httpStatus int, grpcCode codes.Code) *Error {
+	return &Error{Reason: reason, Message: message, HTTPStatus: httpStatus, GRPCCode: grpcCode}
+}
+
+// Error .
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Reason, e.Message, e.cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, e.Message)
+}
+
+// Unwrap .
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is an error with the same reason
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Reason == e.Reason
+}
+
+// WithMessage returns a copy of the error with the client facing message replaced
+func (e *Error) WithMessage(message string) *Error {
+	ne := *e
+	ne.Message = message
+	return &ne
+}
+
+// Wrap returns a copy of the error carrying the internal cause, the cause is never exposed to clients
+func (e *Error) Wrap(cause error) *Error {
+	ne := *e
+	ne.cause = cause
+	return &ne
+}
+
+// Wrapf .
+func (e *Error) Wrapf(format string, args ...interface{}) *Error {
+	return e.Wrap(fmt.Errorf(format, args...))
+}
+
+// WithRetryAfter returns a copy of the error telling the client when to retry
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	ne := *e
+	ne.retryAfter = d
+	return &ne
+}
+
+// RetryAfter returns the retry delay, zero when not set
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// ProtoReason returns the proto enum value of the reason
+func (e *Error) ProtoReason() proto.ErrorReason {
+	return ProtoReason(e.Reason)
+}
+
+// GRPCStatus returns the gRPC status with the error info detail, it is used by status.FromError
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.GRPCCode, e.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: string(e.Reason), Domain: Domain}}
+	if e.retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.retryAfter)})
+	}
+	if ds, err := st.WithDetails(details...); err == nil {
+		return ds
+	}
+	return st
+}
+
+// ProtoReason .
+func ProtoReason(reason Reason) proto.ErrorReason {
+	return proto.ErrorReason(proto.ErrorReason_value[string(reason)])
+}
+
+// FromError returns the catalogue error of err, unknown errors are reported as internal errors
+func FromError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// ReasonOf returns the reason of err, empty for nil
+func ReasonOf(err error) Reason {
+	if err == nil {
+		return ""
+	}
+	return FromError(err).Reason
+}
+
+// IsVerifyFailure reports whether err is a failed captcha verification rather than a request or backend error
+func IsVerifyFailure(err error) bool {
+	switch ReasonOf(err) {
+	case ReasonCaptchaNotFound, ReasonCaptchaExpired, ReasonCaptchaAlreadyUsed, ReasonCaptchaAnswerIncorrect:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wenlng/go-captcha-service/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("check: %w", ErrCaptchaExpired.Wrap(errors.New("cache miss")))
+	assert.True(t, errors.Is(err, ErrCaptchaExpired))
+	assert.False(t, errors.Is(err, ErrCaptchaNotFound))
+	assert.Equal(t, ReasonCaptchaExpired, ReasonOf(err))
+	assert.True(t, IsVerifyFailure(err))
+}
+
+func TestFromError(t *testing.T) {
+	assert.Nil(t, FromError(nil))
+
+	e := FromError(errors.New("boom"))
+	assert.Equal(t, ReasonInternal, e.Reason)
+	assert.Equal(t, http.StatusInternalServerError, e.HTTPStatus)
+	assert.Equal(t, "internal server error", e.Message)
+}
+
+func TestGRPCStatus(t *testing.T) {
+	st, ok := status.FromError(ErrCaptchaAlreadyUsed)
+	assert.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		info, ok := details[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, string(ReasonCaptchaAlreadyUsed), info.GetReason())
+		assert.Equal(t, Domain, info.GetDomain())
+	}
+}
+
+func TestProtoReason(t *testing.T) {
+	for _, e := range []*Error{
+		ErrInvalidArgument, ErrMethodNotAllowed, ErrUnauthenticated, ErrRateLimited, ErrServiceUnavailable,
+		ErrInternal, ErrCaptchaTypeNotFound, ErrCaptchaNotFound, ErrCaptchaExpired, ErrCaptchaAlreadyUsed,
+		ErrCaptchaAnswerIncorrect, ErrCaptchaGenerateFailed, ErrCaptchaGenerateBusy, ErrCacheUnavailable,
+		ErrResourceInvalidPath, ErrResourceTooLarge, ErrConfigInvalid, ErrForbidden, ErrNotFound,
+	} {
+		assert.NotEqual(t, proto.ErrorReason_ERROR_REASON_UNSPECIFIED, e.ProtoReason(), e.Reason)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := ErrRateLimited.WithRetryAfter(2 * time.Second)
+	assert.Equal(t, 2*time.Second, err.RetryAfter())
+	assert.Zero(t, ErrRateLimited.RetryAfter())
+
+	st := status.Convert(err)
+	assert.Len(t, st.Details(), 2)
+}
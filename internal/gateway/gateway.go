@@ -0,0 +1,60 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewServeMux creates the HTTP/JSON transcoding mux of the google.api.http annotations in the protos.
+// The JSON bodies use the proto field names, enums are encoded as their names and timestamps as RFC 3339,
+// the errors have the same shape as the ones of the HTTP handlers.
+func NewServeMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithErrorHandler(errorHandler),
+		runtime.WithRoutingErrorHandler(routingErrorHandler),
+	)
+}
+
+// errorHandler writes the errors of the services and of the request decoding
+func errorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	middleware.WriteAppError(w, fromError(err))
+}
+
+// routingErrorHandler answers the unknown paths like http.ServeMux
+func routingErrorHandler(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, httpStatus int) {
+	if httpStatus == http.StatusMethodNotAllowed {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+	http.Error(w, http.StatusText(httpStatus), httpStatus)
+}
+
+// fromError returns the catalogue error of err, the gateway reports the malformed requests as gRPC statuses
+func fromError(err error) *errcode.Error {
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+		return errcode.ErrInvalidArgument.WithMessage("invalid request: " + st.Message())
+	}
+	return errcode.FromError(err)
+}
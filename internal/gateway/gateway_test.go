@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/middleware"
+	protov2 "github.com/wenlng/go-captcha-service/proto/v2"
+)
+
+type fakeService struct {
+	protov2.UnimplementedGoCaptchaServiceServer
+}
+
+func (s *fakeService) GetData(_ context.Context, req *protov2.GetDataRequest) (*protov2.GetDataResponse, error) {
+	if req.GetId() == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
+	}
+	return &protov2.GetDataResponse{Id: req.GetId(), CaptchaKey: "key", Type: protov2.CaptchaType_CAPTCHA_TYPE_CLICK}, nil
+}
+
+func (s *fakeService) CheckData(_ context.Context, req *protov2.CheckDataRequest) (*protov2.CheckDataResponse, error) {
+	return &protov2.CheckDataResponse{Ok: len(req.GetClick().GetPoints()) == 2}, nil
+}
+
+func serve(mux http.Handler, method, target, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader(body)))
+	var out map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &out)
+	return rr, out
+}
+
+func TestServeMux(t *testing.T) {
+	mux := NewServeMux()
+	assert.NoError(t, protov2.RegisterGoCaptchaServiceHandlerServer(context.Background(), mux, &fakeService{}))
+
+	rr, out := serve(mux, http.MethodGet, "/api/v2/public/get-data?id=click-default-ch", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "key", out["captcha_key"])
+	assert.Equal(t, "CAPTCHA_TYPE_CLICK", out["type"])
+	assert.Contains(t, out, "thumb_size")
+
+	rr, out = serve(mux, http.MethodPost, "/api/v2/public/check-data", `{"id":"click-default-ch","captcha_key":"key","click":{"points":[{"x":1,"y":2},{"x":3,"y":4}]},"unknown":1}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, true, out["ok"])
+
+	// The errors have the shape of the HTTP handlers
+	rr, _ = serve(mux, http.MethodGet, "/api/v2/public/get-data", "")
+	var errResp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &errResp))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "missing id parameter", errResp.Message)
+	assert.Equal(t, string(errcode.ReasonInvalidArgument), errResp.Reason)
+
+	rr, out = serve(mux, http.MethodPost, "/api/v2/public/check-data", "{invalid")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, string(errcode.ReasonInvalidArgument), out["reason"])
+
+	rr, out = serve(mux, http.MethodPost, "/api/v2/public/get-data", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, string(errcode.ReasonMethodNotAllowed), out["reason"])
+
+	rr, _ = serve(mux, http.MethodGet, "/api/v2/public/unknown", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
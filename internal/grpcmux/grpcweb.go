@@ -0,0 +1,174 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package grpcmux
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	contentTypeGRPC     = "application/grpc"
+	contentTypeGRPCWeb  = "application/grpc-web"
+	contentTypeGRPCText = "application/grpc-web-text"
+
+	// trailerFrameFlag marks the frame carrying the trailers at the end of a gRPC-Web response body
+	trailerFrameFlag byte = 0x80
+)
+
+// IsGRPCWebRequest .
+func IsGRPCWebRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), contentTypeGRPCWeb)
+}
+
+// GRPCWebHandler translates the gRPC-Web requests of browsers into gRPC requests of the server,
+// the trailers are sent in the response body as the browsers cannot read HTTP trailers
+func GRPCWebHandler(grpcHandler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !IsGRPCWebRequest(r) {
+			http.Error(w, "gRPC-Web requests must be POST with an application/grpc-web content type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		text := strings.HasPrefix(contentType, contentTypeGRPCText)
+
+		req := r.Clone(r.Context())
+		req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2.0"
+		req.Header.Set("Content-Type", contentTypeGRPC+strings.TrimPrefix(strings.TrimPrefix(contentType, contentTypeGRPCText), contentTypeGRPCWeb))
+		req.Header.Del("Content-Length")
+		req.ContentLength = -1
+		if text {
+			body, err := decodeBase64Body(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		gw := &grpcWebResponseWriter{w: w, header: make(http.Header), contentType: contentType, text: text}
+		grpcHandler.ServeHTTP(gw, req)
+		gw.finish()
+	}
+}
+
+// decodeBase64Body decodes a gRPC-Web text body, the clients may concatenate padded base64 chunks
+func decodeBase64Body(body io.Reader) ([]byte, error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.Join(bytes.Fields(raw), nil)
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("invalid gRPC-Web text body length: %d", len(raw))
+	}
+
+	out := make([]byte, 0, len(raw)/4*3)
+	buf := make([]byte, 3)
+	for i := 0; i < len(raw); i += 4 {
+		n, err := base64.StdEncoding.Decode(buf, raw[i:i+4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid gRPC-Web text body: %v", err)
+		}
+		out = append(out, buf[:n]...)
+	}
+	return out, nil
+}
+
+// grpcWebResponseWriter keeps the headers of the gRPC server apart, the ones set after the first write become the trailer frame
+type grpcWebResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	contentType string
+	text        bool
+	wroteHeader bool
+	sentHeader  http.Header
+}
+
+// Header .
+func (gw *grpcWebResponseWriter) Header() http.Header {
+	return gw.header
+}
+
+// WriteHeader .
+func (gw *grpcWebResponseWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.wroteHeader = true
+
+	gw.sentHeader = gw.header.Clone()
+	h := gw.w.Header()
+	for k, vv := range gw.header {
+		if k == "Trailer" || strings.HasPrefix(k, http2.TrailerPrefix) {
+			continue
+		}
+		h[k] = vv
+	}
+	h.Set("Content-Type", gw.contentType)
+	gw.w.WriteHeader(code)
+}
+
+// Write .
+func (gw *grpcWebResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.text {
+		if _, err := gw.w.Write([]byte(base64.StdEncoding.EncodeToString(b))); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return gw.w.Write(b)
+}
+
+// Flush .
+func (gw *grpcWebResponseWriter) Flush() {
+	if !gw.wroteHeader {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := gw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailer frame with the status of the call
+func (gw *grpcWebResponseWriter) finish() {
+	if !gw.wroteHeader {
+		gw.WriteHeader(http.StatusOK)
+	}
+
+	var trailers bytes.Buffer
+	for k, vv := range gw.header {
+		name := strings.TrimPrefix(k, http2.TrailerPrefix)
+		if name == k {
+			// Headers already sent are not trailers
+			if _, sent := gw.sentHeader[k]; sent || k == "Trailer" {
+				continue
+			}
+		}
+		for _, v := range vv {
+			trailers.WriteString(strings.ToLower(name) + ": " + v + "\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+trailers.Len())
+	frame[0] = trailerFrameFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(trailers.Len()))
+	frame = append(frame, trailers.Bytes()...)
+	_, _ = gw.Write(frame)
+	gw.Flush()
+}
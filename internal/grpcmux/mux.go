@@ -0,0 +1,77 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package grpcmux
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// Mux serves gRPC, gRPC-Web and the HTTP routes on one listener, the requests are told apart by their protocol and content type
+type Mux struct {
+	grpcServer  *grpc.Server
+	webHandler  http.Handler
+	httpHandler http.Handler
+	services    map[string]bool
+	webOnly     bool
+}
+
+// NewMux creates the multiplexer of the gRPC server, the gRPC-Web handler and the HTTP routes
+func NewMux(grpcServer *grpc.Server, webHandler, httpHandler http.Handler) *Mux {
+	services := make(map[string]bool)
+	for name := range grpcServer.GetServiceInfo() {
+		services[name] = true
+	}
+	return &Mux{
+		grpcServer:  grpcServer,
+		webHandler:  webHandler,
+		httpHandler: httpHandler,
+		services:    services,
+	}
+}
+
+// NewWebMux creates the multiplexer of the gRPC-Web handler and the HTTP routes, native gRPC is left to the gRPC listener
+func NewWebMux(grpcServer *grpc.Server, webHandler, httpHandler http.Handler) *Mux {
+	m := NewMux(grpcServer, webHandler, httpHandler)
+	m.webOnly = true
+	return m
+}
+
+// IsGRPCRequest reports whether the request is a native gRPC call over HTTP/2
+func IsGRPCRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 && strings.HasPrefix(contentType, contentTypeGRPC) && !strings.HasPrefix(contentType, contentTypeGRPCWeb)
+}
+
+// ServeHTTP .
+func (m *Mux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !m.webOnly && IsGRPCRequest(r) {
+		m.grpcServer.ServeHTTP(w, r)
+		return
+	}
+	if m.isServicePath(r.URL.Path) {
+		// gRPC-Web calls and their CORS preflight
+		m.webHandler.ServeHTTP(w, r)
+		return
+	}
+	m.httpHandler.ServeHTTP(w, r)
+}
+
+// isServicePath reports whether the path is a method of the gRPC services, such as /gocaptcha.GoCaptchaService/GetData
+func (m *Mux) isServicePath(path string) bool {
+	service, _, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return ok && m.services[service]
+}
+
+// H2C accepts HTTP/2 without TLS, the prior knowledge of the gRPC clients and the upgrade from HTTP/1.1
+func H2C(handler http.Handler) http.Handler {
+	return h2c.NewHandler(handler, &http2.Server{})
+}
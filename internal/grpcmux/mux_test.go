@@ -0,0 +1,104 @@
+package grpcmux
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	protoutil "google.golang.org/protobuf/proto"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+
+	rest := http.NewServeMux()
+	rest.HandleFunc("/status/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(H2C(NewMux(grpcServer, GRPCWebHandler(grpcServer), rest)))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// grpcWebFrame frames a message of a gRPC-Web request
+func grpcWebFrame(t *testing.T, msg protoutil.Message) []byte {
+	data, err := protoutil.Marshal(msg)
+	assert.NoError(t, err)
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+func TestMux(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/status/live")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+
+	// Native gRPC over h2c
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+	// gRPC-Web over HTTP/1.1
+	frame := grpcWebFrame(t, &healthpb.HealthCheckRequest{})
+	resp, err = http.Post(srv.URL+"/grpc.health.v1.Health/Check", "application/grpc-web+proto", bytes.NewReader(frame))
+	assert.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "application/grpc-web+proto", resp.Header.Get("Content-Type"))
+	assert.True(t, len(body) > 5)
+	n := binary.BigEndian.Uint32(body[1:5])
+	var out healthpb.HealthCheckResponse
+	assert.NoError(t, protoutil.Unmarshal(body[5:5+n], &out))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, out.GetStatus())
+	trailer := body[5+n:]
+	assert.Equal(t, trailerFrameFlag, trailer[0])
+	assert.Contains(t, string(trailer[5:]), "grpc-status: 0\r\n")
+
+	// gRPC-Web text of an unknown service
+	resp, err = http.Post(srv.URL+"/grpc.health.v1.Health/Unknown", "application/grpc-web-text", strings.NewReader(base64.StdEncoding.EncodeToString(frame)))
+	assert.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	decoded, err := decodeBase64Body(bytes.NewReader(body))
+	assert.NoError(t, err)
+	assert.Contains(t, string(decoded), "grpc-status: 12\r\n")
+}
+
+func TestWebMux(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+	srv := httptest.NewServer(H2C(NewWebMux(grpcServer, GRPCWebHandler(grpcServer), http.NotFoundHandler())))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/grpc.health.v1.Health/Check", "application/grpc-web+proto", bytes.NewReader(grpcWebFrame(t, &healthpb.HealthCheckRequest{})))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Native gRPC is left to the gRPC listener
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
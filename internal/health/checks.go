@@ -0,0 +1,119 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/wenlng/go-captcha-service/internal/cache"
+	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+)
+
+const healthCheckKeyPrefix = "HEALTH_CHECK:"
+
+// CacheRoundTripCheck writes, reads back and deletes a probe key in the cache backend
+func CacheRoundTripCheck(cacheMgr *cache.CacheManager) CheckFunc {
+	return func(ctx context.Context) error {
+		c := cacheMgr.GetCache()
+		if c == nil {
+			return fmt.Errorf("cache is not initialized")
+		}
+
+		key := healthCheckKeyPrefix + helper.GenerateID()
+		value := helper.GenerateID()
+
+		if err := c.SetCache(ctx, key, value); err != nil {
+			return fmt.Errorf("failed to write cache: %v", err)
+		}
+		defer c.DeleteCache(context.WithoutCancel(ctx), key)
+
+		got, err := c.GetCache(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %v", err)
+		}
+		if got != value {
+			return fmt.Errorf("cache returned an unexpected value")
+		}
+		return nil
+	}
+}
+
+// CaptchaInstancesCheck checks that the captcha instances are loaded
+func CaptchaInstancesCheck(captcha *gocaptcha.GoCaptcha) CheckFunc {
+	return func(ctx context.Context) error {
+		if captcha == nil {
+			return fmt.Errorf("captcha is not initialized")
+		}
+		if captcha.InstanceCount() == 0 {
+			return fmt.Errorf("no captcha instances are loaded")
+		}
+		return nil
+	}
+}
+
+// Messages of the health checks logged by the dynamic config manager
+const (
+	configHealthFailed = "[ConfigManager] Health check failed"
+	configHealthPassed = "[ConfigManager] Health check passed"
+	configHealthCached = "[ConfigManager] Using cached health status"
+)
+
+// ConfigManagerStatus holds the result of the last health check of the dynamic config manager.
+// The manager does not expose the status of its provider, the results are read from its log output.
+type ConfigManagerStatus struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// Observe updates the status from a message logged by the config manager
+func (s *ConfigManagerStatus) Observe(message string) {
+	var err error
+	switch {
+	case strings.HasPrefix(message, configHealthFailed):
+		err = errors.New(message)
+		if i := strings.LastIndex(message, "err: "); i >= 0 {
+			err = errors.New(message[i+len("err: "):])
+		}
+	case strings.HasPrefix(message, configHealthPassed), strings.HasPrefix(message, configHealthCached):
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// ConfigManagerCheck fails when the last health check of the dynamic config manager failed
+func ConfigManagerCheck(s *ConfigManagerStatus) CheckFunc {
+	return func(ctx context.Context) error {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		if s.err != nil {
+			return fmt.Errorf("dynamic config provider is unhealthy: %v", s.err)
+		}
+		return nil
+	}
+}
+
+// GenerateQueueCheck fails when the generation queue usage reaches the threshold
+func GenerateQueueCheck(queue *gocaptcha.GenerateQueue, threshold float64) CheckFunc {
+	return func(ctx context.Context) error {
+		if queue == nil || threshold <= 0 {
+			return nil
+		}
+		if saturation := queue.Saturation(); saturation >= threshold {
+			return fmt.Errorf("generate queue is saturated: %.2f", saturation)
+		}
+		return nil
+	}
+}
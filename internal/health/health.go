@@ -0,0 +1,125 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status .
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckFunc returns an error when the dependency is not healthy
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult ..
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report ..
+type Report struct {
+	Status string         `json:"status"`
+	Checks []*CheckResult `json:"checks"`
+}
+
+// Healthy ..
+func (r *Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker runs the registered liveness and readiness checks
+type Checker struct {
+	mu              sync.RWMutex
+	livenessChecks  []namedCheck
+	readinessChecks []namedCheck
+	timeout         time.Duration
+}
+
+// NewChecker ..
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &Checker{timeout: timeout}
+}
+
+// AddLivenessCheck registers a check that decides whether the process should be restarted
+func (c *Checker) AddLivenessCheck(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.livenessChecks = append(c.livenessChecks, namedCheck{name: name, fn: fn})
+}
+
+// AddReadinessCheck registers a check that decides whether the instance can receive traffic
+func (c *Checker) AddReadinessCheck(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readinessChecks = append(c.readinessChecks, namedCheck{name: name, fn: fn})
+}
+
+// Liveness ..
+func (c *Checker) Liveness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := c.livenessChecks
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// Readiness ..
+func (c *Checker) Readiness(ctx context.Context) *Report {
+	c.mu.RLock()
+	checks := c.readinessChecks
+	c.mu.RUnlock()
+	return c.run(ctx, checks)
+}
+
+// run executes the checks concurrently and aggregates the results
+func (c *Checker) run(ctx context.Context, checks []namedCheck) *Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := &Report{Status: StatusOK, Checks: make([]*CheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check namedCheck) {
+			defer wg.Done()
+			start := time.Now()
+			res := &CheckResult{Name: check.name, Status: StatusOK}
+			if err := check.fn(ctx); err != nil {
+				res.Status = StatusFail
+				res.Error = err.Error()
+			}
+			res.Duration = time.Since(start).String()
+			report.Checks[i] = res
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, res := range report.Checks {
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+)
+
+func TestCheckerReadiness(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddReadinessCheck("ok", func(ctx context.Context) error {
+		return nil
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.True(t, report.Healthy())
+	assert.Len(t, report.Checks, 1)
+
+	checker.AddReadinessCheck("fail", func(ctx context.Context) error {
+		return errors.New("unavailable")
+	})
+
+	report = checker.Readiness(context.Background())
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusFail, report.Checks[1].Status)
+	assert.Equal(t, "unavailable", report.Checks[1].Error)
+
+	assert.True(t, checker.Liveness(context.Background()).Healthy())
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	checker := NewChecker(50 * time.Millisecond)
+	checker.AddReadinessCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Readiness(context.Background())
+	assert.False(t, report.Healthy())
+}
+
+func TestGenerateQueueCheck(t *testing.T) {
+	queue := gocaptcha.NewGenerateQueue(1)
+	check := GenerateQueueCheck(queue, 0.5)
+	assert.NoError(t, check(context.Background()))
+
+	assert.NoError(t, queue.Acquire(context.Background()))
+	assert.Error(t, check(context.Background()))
+
+	queue.Release()
+	assert.NoError(t, check(context.Background()))
+}
+
+func TestConfigManagerCheck(t *testing.T) {
+	status := &ConfigManagerStatus{}
+	check := ConfigManagerCheck(status)
+	assert.NoError(t, check(context.Background()))
+
+	status.Observe("[ConfigManager] Health check failed, mertice: map[], err: context deadline exceeded")
+	err := check(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context deadline exceeded")
+	}
+
+	status.Observe("[ConfigManager] Resync failed after reconnect, err: connection refused")
+	assert.Error(t, check(context.Background()))
+
+	status.Observe("[ConfigManager] Health check passed, metrics: map[]")
+	assert.NoError(t, check(context.Background()))
+}
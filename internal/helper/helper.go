@@ -18,6 +18,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/google/uuid"
@@ -26,6 +27,8 @@ import (
 
 var idNode, _ = snowflake.NewNode(1)
 
+var minIDTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
 // GenerateID ..
 func GenerateID() string {
 	return idNode.Generate().String()
@@ -44,6 +47,21 @@ func GenerateIDWithNode(node int64) (string, error) {
 	return n.Generate().String(), nil
 }
 
+// ParseIDTime returns the time an id was generated by GenerateID or GenerateIDWithNode
+func ParseIDTime(id string) (time.Time, bool) {
+	sid, err := snowflake.ParseString(id)
+	if err != nil || sid <= 0 {
+		return time.Time{}, false
+	}
+
+	// Ids before the service existed are not generated by it
+	t := time.UnixMilli(sid.Time())
+	if t.Before(minIDTime) || t.After(time.Now().Add(time.Minute)) {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
 // GenUniqueId .
 func GenUniqueId() (string, error) {
 	uid, err := uuid.NewUUID()
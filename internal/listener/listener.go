@@ -0,0 +1,107 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Address schemes .
+const (
+	SchemeTCP  string = "tcp"
+	SchemeUnix        = "unix"
+)
+
+// DefaultUnixSocketMode is the file mode of the Unix domain sockets
+const DefaultUnixSocketMode os.FileMode = 0660
+
+// Parse splits a listener address such as tcp://127.0.0.1:8080, tcp://[::1]:8080 or unix:///run/captcha.sock
+// into the network and the address of net.Listen
+func Parse(addr string) (network string, address string, err error) {
+	scheme, rest, ok := strings.Cut(addr, "://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid listener address: %s, must be tcp://host:port or unix:///path", addr)
+	}
+
+	switch scheme {
+	case SchemeTCP:
+		_, port, err := net.SplitHostPort(rest)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid listener address: %s, %v", addr, err)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return "", "", fmt.Errorf("invalid listener address: %s, invalid port", addr)
+		}
+		return SchemeTCP, rest, nil
+	case SchemeUnix:
+		if !strings.HasPrefix(rest, "/") {
+			return "", "", fmt.Errorf("invalid listener address: %s, the socket path must be absolute", addr)
+		}
+		return SchemeUnix, rest, nil
+	}
+	return "", "", fmt.Errorf("invalid listener address: %s, the scheme must be tcp or unix", addr)
+}
+
+// Port returns the port of a tcp address, empty for the Unix domain sockets
+func Port(addr string) string {
+	network, address, err := Parse(addr)
+	if err != nil || network != SchemeTCP {
+		return ""
+	}
+	_, port, _ := net.SplitHostPort(address)
+	return port
+}
+
+// ParseSocketMode parses an octal file mode such as 0660, empty is the default mode
+func ParseSocketMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return DefaultUnixSocketMode, nil
+	}
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("invalid socket mode: %s, must be octal such as 0660", mode)
+	}
+	return os.FileMode(m), nil
+}
+
+// Listen listens on the address. A stale Unix domain socket is removed first and the new one gets the file mode.
+func Listen(addr string, mode os.FileMode) (net.Listener, error) {
+	network, address, err := Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == SchemeTCP {
+		return net.Listen(network, address)
+	}
+
+	if fi, err := os.Lstat(address); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("failed to listen: %s exists and is not a socket", address)
+		}
+		if conn, err := net.Dial(SchemeUnix, address); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to listen: %s is in use", address)
+		}
+		if err = os.Remove(address); err != nil {
+			return nil, fmt.Errorf("failed to remove the stale socket: %v", err)
+		}
+	}
+
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(address, mode); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("failed to set the socket mode: %v", err)
+	}
+	return lis, nil
+}
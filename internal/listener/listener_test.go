@@ -0,0 +1,66 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		addr    string
+		network string
+		address string
+	}{
+		{"tcp://:8080", SchemeTCP, ":8080"},
+		{"tcp://127.0.0.1:8080", SchemeTCP, "127.0.0.1:8080"},
+		{"tcp://[::1]:8080", SchemeTCP, "[::1]:8080"},
+		{"unix:///run/captcha.sock", SchemeUnix, "/run/captcha.sock"},
+	}
+	for _, tt := range tests {
+		network, address, err := Parse(tt.addr)
+		assert.NoError(t, err, tt.addr)
+		assert.Equal(t, tt.network, network)
+		assert.Equal(t, tt.address, address)
+	}
+
+	for _, addr := range []string{"8080", ":8080", "tcp://127.0.0.1", "tcp://::1:8080", "tcp://:0", "unix://run/captcha.sock", "udp://:8080"} {
+		_, _, err := Parse(addr)
+		assert.Error(t, err, addr)
+	}
+
+	assert.Equal(t, "8080", Port("tcp://[::1]:8080"))
+	assert.Equal(t, "", Port("unix:///run/captcha.sock"))
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "captcha.sock")
+	mode, err := ParseSocketMode("0600")
+	assert.NoError(t, err)
+
+	lis, err := Listen("unix://"+path, mode)
+	assert.NoError(t, err)
+	fi, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// A socket in use is kept
+	_, err = Listen("unix://"+path, mode)
+	assert.Error(t, err)
+	lis.Close()
+
+	// A stale socket left by a crash is replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	assert.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	lis, err = Listen("unix://"+path, mode)
+	assert.NoError(t, err)
+	lis.Close()
+
+	_, err = ParseSocketMode("0999")
+	assert.Error(t, err)
+}
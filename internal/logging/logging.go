@@ -0,0 +1,209 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+// disabledLevel is above every level, loggers at this level write nothing
+const disabledLevel = zapcore.FatalLevel + 1
+
+// Logger is the application logger, its level and the component levels can be changed at runtime
+type Logger struct {
+	*zap.Logger
+
+	core       zapcore.Core
+	level      zap.AtomicLevel
+	components map[string]*componentLevel
+	file       *lumberjack.Logger
+	stop       chan struct{}
+}
+
+// componentLevel follows the root level unless the component has its own level
+type componentLevel struct {
+	root     zap.AtomicLevel
+	level    zap.AtomicLevel
+	override atomic.Bool
+}
+
+// Enabled .
+func (c *componentLevel) Enabled(l zapcore.Level) bool {
+	if c.override.Load() {
+		return c.level.Enabled(l)
+	}
+	return c.root.Enabled(l)
+}
+
+// levelCore filters the entries of the shared core with its own level
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+// Enabled .
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.enabler.Enabled(l)
+}
+
+// With .
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+// Check .
+func (c *levelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabler.Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// New creates the logger of the log settings and the log levels of cfg
+func New(cfg config.Config) (*Logger, error) {
+	logCfg := cfg.GetLogConfig()
+	l := &Logger{
+		level:      zap.NewAtomicLevel(),
+		components: make(map[string]*componentLevel),
+		stop:       make(chan struct{}),
+	}
+
+	encoderCfg := zap.NewProductionEncoderConfig()
+	var encoder zapcore.Encoder
+	if logCfg.Encoding == config.LogEncodingConsole {
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	}
+
+	var sink zapcore.WriteSyncer
+	switch logCfg.Output {
+	case config.LogOutputFile:
+		if err := os.MkdirAll(filepath.Dir(logCfg.File), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create the log directory: %v", err)
+		}
+		l.file = &lumberjack.Logger{
+			Filename:   logCfg.File,
+			MaxSize:    logCfg.MaxSize,
+			MaxBackups: logCfg.MaxBackups,
+			MaxAge:     logCfg.MaxAge,
+			Compress:   logCfg.Compress,
+			LocalTime:  true,
+		}
+		sink = zapcore.AddSync(l.file)
+	case config.LogOutputStderr:
+		sink = zapcore.Lock(os.Stderr)
+	default:
+		sink = zapcore.Lock(os.Stdout)
+	}
+
+	// The shared core writes every level, the loggers filter the entries with their own level
+	l.core = zapcore.NewCore(encoder, sink, zapcore.DebugLevel)
+	if logCfg.Sampling.Enable {
+		l.core = zapcore.NewSamplerWithOptions(l.core, time.Second, logCfg.Sampling.Initial, logCfg.Sampling.Thereafter)
+	}
+
+	for _, name := range []string{config.LogComponentCache, config.LogComponentConfig, config.LogComponentHTTP, config.LogComponentGRPC} {
+		l.components[name] = &componentLevel{root: l.level, level: zap.NewAtomicLevel()}
+	}
+	l.Logger = l.newLogger(l.level)
+	l.Update(cfg)
+
+	if l.file != nil && logCfg.RotateInterval > 0 {
+		go l.rotate(time.Duration(logCfg.RotateInterval) * time.Hour)
+	}
+	return l, nil
+}
+
+// newLogger creates a logger of the shared core with the options of zap.NewProduction
+func (l *Logger) newLogger(enabler zapcore.LevelEnabler) *zap.Logger {
+	return zap.New(&levelCore{Core: l.core, enabler: enabler},
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+}
+
+// Component returns the logger of the component, it follows the root level unless log.component_levels sets one
+func (l *Logger) Component(name string) *zap.Logger {
+	level, ok := l.components[name]
+	if !ok {
+		return l.Logger.Named(name)
+	}
+	return l.newLogger(level).Named(name)
+}
+
+// Update applies the log_level and the log.component_levels of cfg
+func (l *Logger) Update(cfg config.Config) {
+	l.level.SetLevel(ParseLevel(cfg.LogLevel))
+	for name, level := range l.components {
+		if v, ok := cfg.Log.ComponentLevels[name]; ok && v != "" {
+			level.level.SetLevel(ParseLevel(v))
+			level.override.Store(true)
+		} else {
+			level.override.Store(false)
+		}
+	}
+}
+
+// Level returns the root level
+func (l *Logger) Level() zapcore.Level {
+	return l.level.Level()
+}
+
+// rotate rotates the log file periodically
+func (l *Logger) rotate(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.file.Rotate(); err != nil {
+				l.Error("[Logging] Failed to rotate the log file", zap.Error(err))
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// Close flushes the logs and closes the log file
+func (l *Logger) Close() error {
+	close(l.stop)
+	_ = l.Sync()
+	if l.file != nil {
+		return l.file.Close()
+	}
+	return nil
+}
+
+// ParseLevel returns the zap level of the config level, empty is info and none disables the logs
+func ParseLevel(level string) zapcore.Level {
+	switch level {
+	case config.LogLevelDebug:
+		return zapcore.DebugLevel
+	case config.LogLevelWarn:
+		return zapcore.WarnLevel
+	case config.LogLevelError:
+		return zapcore.ErrorLevel
+	case config.LogLevelNone:
+		return disabledLevel
+	}
+	return zapcore.InfoLevel
+}
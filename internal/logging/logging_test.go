@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+func TestLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "service.log")
+	cfg := config.DefaultConfig()
+	cfg.Log.Output = config.LogOutputFile
+	cfg.Log.File = file
+	cfg.Log.ComponentLevels = map[string]string{config.LogComponentCache: config.LogLevelError}
+
+	l, err := New(cfg)
+	assert.NoError(t, err)
+	cacheLogger := l.Component(config.LogComponentCache)
+	httpLogger := l.Component(config.LogComponentHTTP)
+
+	l.Debug("root debug")
+	l.Info("root info")
+	cacheLogger.Warn("cache warn")
+	cacheLogger.Error("cache error")
+	httpLogger.Info("http info")
+
+	// The levels are changed at runtime
+	cfg.LogLevel = config.LogLevelDebug
+	cfg.Log.ComponentLevels = nil
+	l.Update(cfg)
+	assert.Equal(t, zapcore.DebugLevel, l.Level())
+	l.Debug("root debug after update")
+	cacheLogger.Warn("cache warn after update")
+
+	cfg.LogLevel = config.LogLevelNone
+	l.Update(cfg)
+	l.Error("root error when disabled")
+	httpLogger.Error("http error when disabled")
+	assert.NoError(t, l.Close())
+
+	b, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	out := string(b)
+	for _, msg := range []string{"root info", "cache error", "http info", "root debug after update", "cache warn after update"} {
+		assert.Contains(t, out, `"msg":"`+msg+`"`)
+	}
+	for _, msg := range []string{`"root debug"`, `"cache warn"`, "when disabled"} {
+		assert.NotContains(t, out, msg)
+	}
+	assert.Contains(t, out, `"logger":"cache"`)
+	assert.Equal(t, 5, strings.Count(out, "\n"))
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, zapcore.InfoLevel, ParseLevel(""))
+	assert.Equal(t, zapcore.WarnLevel, ParseLevel(config.LogLevelWarn))
+	assert.False(t, ParseLevel(config.LogLevelNone).Enabled(zapcore.FatalLevel))
+}
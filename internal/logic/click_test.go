@@ -3,8 +3,11 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
 	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
 	"github.com/wenlng/go-captcha/v2/click"
@@ -26,8 +30,14 @@ func TestCacheLogic(t *testing.T) {
 
 	ttl := time.Duration(10) * time.Second
 	cleanInt := time.Duration(30) * time.Second
-	cacheClient := cache.NewMemoryCache("TEST_CAPTCHA_DATA:", ttl, cleanInt)
-	defer cacheClient.Close()
+	cacheMgr, err := cache.NewCacheManager(&cache.CacheMgrParams{
+		Type:      cache.CacheTypeMemory,
+		KeyPrefix: "TEST_CAPTCHA_DATA:",
+		Ttl:       ttl,
+		CleanInt:  cleanInt,
+	})
+	assert.NoError(t, err)
+	defer cacheMgr.Close()
 
 	dc := &config.DynamicConfig{Config: config.DefaultConfig()}
 	cdc := &config2.DynamicCaptchaConfig{Config: config2.DefaultConfig()}
@@ -39,7 +49,7 @@ func TestCacheLogic(t *testing.T) {
 	assert.NoError(t, err)
 
 	svcCtx := &common.SvcContext{
-		CacheMgr:      cacheClient,
+		CacheMgr:      cacheMgr,
 		DynamicConfig: dc,
 		Logger:        logger,
 		Captcha:       captcha,
@@ -47,7 +57,7 @@ func TestCacheLogic(t *testing.T) {
 	logic := NewClickCaptLogic(svcCtx)
 
 	t.Run("GetData", func(t *testing.T) {
-		_, err := logic.GetData(context.Background(), "dd")
+		_, err := logic.GetData(context.Background(), "click-default-ch")
 		assert.NoError(t, err)
 	})
 
@@ -56,17 +66,25 @@ func TestCacheLogic(t *testing.T) {
 		assert.Error(t, err)
 	})
 
-	t.Run("CheckData", func(t *testing.T) {
-		data, err := logic.GetData(context.Background(), "dd")
+	// loadDots returns the cached dots of the captcha
+	loadDots := func(t *testing.T, key string) map[int]*click.Dot {
+		cacheData, err := svcCtx.CacheMgr.GetCache().GetCache(context.Background(), key)
 		assert.NoError(t, err)
 
-		cacheData, err := svcCtx.CacheMgr.GetCache(context.Background(), data.CaptchaKey)
+		var captData struct {
+			Data map[int]*click.Dot `json:"data"`
+		}
+		err = json.Unmarshal([]byte(cacheData), &captData)
 		assert.NoError(t, err)
+		return captData.Data
+	}
 
-		var dct map[int]*click.Dot
-		err = json.Unmarshal([]byte(cacheData), &dct)
+	t.Run("CheckData", func(t *testing.T) {
+		data, err := logic.GetData(context.Background(), "click-default-ch")
 		assert.NoError(t, err)
 
+		dct := loadDots(t, data.CaptchaKey)
+
 		var dots []string
 		for i := 0; i < len(dct); i++ {
 			dot := dct[i]
@@ -80,14 +98,7 @@ func TestCacheLogic(t *testing.T) {
 	})
 
 	t.Run("CheckData_MISS", func(t *testing.T) {
-		data, err := logic.GetData(context.Background(), "dd")
-		assert.NoError(t, err)
-
-		cacheData, err := svcCtx.CacheMgr.GetCache(context.Background(), data.CaptchaKey)
-		assert.NoError(t, err)
-
-		var dct map[int]*click.Dot
-		err = json.Unmarshal([]byte(cacheData), &dct)
+		data, err := logic.GetData(context.Background(), "click-default-ch")
 		assert.NoError(t, err)
 
 		var dots = []string{
@@ -96,7 +107,39 @@ func TestCacheLogic(t *testing.T) {
 		}
 		dotStr := strings.Join(dots, ",")
 		result, err := logic.CheckData(context.Background(), data.CaptchaKey, dotStr)
-		assert.NoError(t, err)
+		assert.ErrorIs(t, err, errcode.ErrCaptchaAnswerIncorrect)
 		assert.Equal(t, false, result)
 	})
+
+	t.Run("CheckData_Concurrent", func(t *testing.T) {
+		data, err := logic.GetData(context.Background(), "click-default-ch")
+		assert.NoError(t, err)
+
+		dct := loadDots(t, data.CaptchaKey)
+		var dots []string
+		for i := 0; i < len(dct); i++ {
+			dots = append(dots, strconv.Itoa(dct[i].X), strconv.Itoa(dct[i].Y))
+		}
+		dotStr := strings.Join(dots, ",")
+
+		// The same correct answer replayed in parallel verifies the captcha once
+		var wg sync.WaitGroup
+		var verified, locked atomic.Int32
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := logic.CheckData(context.Background(), data.CaptchaKey, dotStr)
+				if result {
+					verified.Add(1)
+				}
+				if errors.Is(err, errcode.ErrCaptchaAlreadyUsed) {
+					locked.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), verified.Load())
+		assert.Equal(t, int32(19), locked.Load())
+	})
 }
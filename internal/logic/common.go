@@ -9,12 +9,20 @@ package logic
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"time"
 
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -41,23 +49,16 @@ func NewCommonLogic(svcCtx *common.SvcContext) *CommonLogic {
 
 // CheckStatus .
 func (cl *CommonLogic) CheckStatus(ctx context.Context, key string) (ret bool, err error) {
-	if key == "" {
-		return false, fmt.Errorf("invalid key")
-	}
+	ctx, span := tracing.Start(ctx, "CommonLogic.CheckStatus", attribute.String("captcha.key", key))
+	defer func() { tracing.End(span, err) }()
 
-	cacheData, err := cl.cacheMgr.GetCache().GetCache(ctx, key)
+	captData, _, err := loadCaptCacheData(ctx, cl.svcCtx.GetCache(ctx), cl.dynamicCfg, key)
 	if err != nil {
-		return false, fmt.Errorf("failed to get cache: %v", err)
-	}
-
-	if cacheData == "" {
-		return false, nil
+		return false, err
 	}
 
-	var captData *cache.CaptCacheData
-	err = json.Unmarshal([]byte(cacheData), &captData)
-	if err != nil {
-		return false, fmt.Errorf("failed to json unmarshal: %v", err)
+	if captData.Status == 2 {
+		return false, errcode.ErrCaptchaAnswerIncorrect
 	}
 
 	return captData.Status == 1, nil
@@ -65,40 +66,133 @@ func (cl *CommonLogic) CheckStatus(ctx context.Context, key string) (ret bool, e
 
 // GetStatusInfo .
 func (cl *CommonLogic) GetStatusInfo(ctx context.Context, key string) (data *cache.CaptCacheData, err error) {
+	ctx, span := tracing.Start(ctx, "CommonLogic.GetStatusInfo", attribute.String("captcha.key", key))
+	defer func() { tracing.End(span, err) }()
+
+	audit.SetTarget(ctx, key)
+
+	data, _, err = loadCaptCacheData(ctx, cl.svcCtx.GetCache(ctx), cl.dynamicCfg, key)
+	return data, err
+}
+
+// DelStatusInfo .
+func (cl *CommonLogic) DelStatusInfo(ctx context.Context, key string) (ret bool, err error) {
+	ctx, span := tracing.Start(ctx, "CommonLogic.DelStatusInfo", attribute.String("captcha.key", key))
+	defer func() { tracing.End(span, err) }()
+
 	if key == "" {
-		return nil, fmt.Errorf("invalid key")
+		return false, errcode.ErrInvalidArgument.WithMessage("captchaKey is required")
+	}
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{Key: key})
+	audit.SetTarget(ctx, key)
+
+	err = cl.svcCtx.GetCache(ctx).DeleteCache(ctx, key)
+	if err != nil {
+		return false, errcode.ErrCacheUnavailable.Wrapf("failed to delete cache: %v", err)
 	}
 
-	captData := &cache.CaptCacheData{}
+	return true, nil
+}
+
+// ExpiresAt returns the expiry time of the captcha key
+func (cl *CommonLogic) ExpiresAt(key string) (time.Time, bool) {
+	t, ok := helper.ParseIDTime(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	return t.Add(time.Duration(cl.dynamicCfg.Get().CacheTTL) * time.Second), true
+}
 
-	cacheData, err := cl.cacheMgr.GetCache().GetCache(ctx, key)
+// loadCaptCacheData loads the cached captcha data of the key, with the raw cached value
+func loadCaptCacheData(ctx context.Context, c cache.Cache, dc *config.DynamicConfig, key string) (*cache.CaptCacheData, string, error) {
+	if key == "" {
+		return nil, "", errcode.ErrInvalidArgument.WithMessage("captchaKey is required")
+	}
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{Key: key})
+
+	cacheData, err := c.GetCache(ctx, key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cache: %v", err)
+		return nil, "", errcode.ErrCacheUnavailable.Wrapf("failed to get cache: %v", err)
 	}
 
 	if cacheData == "" {
-		captData.Data = struct{}{}
-		return captData, nil
+		return nil, "", missingCaptError(key, dc.Get().CacheTTL)
 	}
 
+	var captData *cache.CaptCacheData
 	err = json.Unmarshal([]byte(cacheData), &captData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to json unmarshal: %v", err)
+		return nil, "", errcode.ErrInternal.Wrapf("failed to json unmarshal: %v", err)
 	}
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{Type: consts.GoCaptchaTypeName(captData.Type)})
 
-	return captData, nil
+	return captData, cacheData, nil
 }
 
-// DelStatusInfo .
-func (cl *CommonLogic) DelStatusInfo(ctx context.Context, key string) (ret bool, err error) {
-	if key == "" {
-		return false, fmt.Errorf("invalid key")
+// claimCaptCacheData stores the verification result in place of the cached value that was read.
+// The swap is atomic, when concurrent checks race on a key only the first one is stored and the others are locked out.
+func claimCaptCacheData(ctx context.Context, svcCtx *common.SvcContext, key, cacheData string, captData *cache.CaptCacheData) error {
+	cacheDataByte, err := json.Marshal(captData)
+	if err != nil {
+		return errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 	}
 
-	err = cl.cacheMgr.GetCache().DeleteCache(ctx, key)
+	c := svcCtx.GetCache(ctx)
+	swapped, err := c.CompareAndSwap(ctx, key, cacheData, string(cacheDataByte))
 	if err != nil {
-		return false, fmt.Errorf("failed to delete cache: %v", err)
+		return errcode.ErrCacheUnavailable.Wrapf("failed to update cache: %v", err)
+	}
+	if swapped {
+		return nil
 	}
 
-	return true, nil
+	// Another check claimed the captcha first, or it expired meanwhile
+	current, _, err := loadCaptCacheData(ctx, c, svcCtx.DynamicConfig, key)
+	if err != nil {
+		return err
+	}
+	notifyAttemptLocked(ctx, svcCtx, key, current.Status)
+	return errcode.ErrCaptchaAlreadyUsed
+}
+
+// recordCheckStat counts the verification result of the request tenant and notifies the webhooks
+func recordCheckStat(ctx context.Context, svcCtx *common.SvcContext, key string, ok bool) {
+	event, eventType := tenant.StatFailed, config.WebhookEventCaptchaFailed
+	if ok {
+		event, eventType = tenant.StatVerified, config.WebhookEventCaptchaVerified
+	}
+	svcCtx.Tenants.Record(tenant.FromContext(ctx), event)
+	notifyEvent(ctx, svcCtx, eventType, map[string]any{
+		"captcha_key":  key,
+		"captcha_type": reqinfo.CaptchaFromContext(ctx).Type,
+	})
+}
+
+// notifyAttemptLocked notifies the webhooks of a verification rejected because the captcha had its attempt
+func notifyAttemptLocked(ctx context.Context, svcCtx *common.SvcContext, key string, status int) {
+	previous := "failed"
+	if status == 1 {
+		previous = "verified"
+	}
+	notifyEvent(ctx, svcCtx, config.WebhookEventCaptchaLocked, map[string]any{
+		"captcha_key":     key,
+		"captcha_type":    reqinfo.CaptchaFromContext(ctx).Type,
+		"previous_result": previous,
+	})
+}
+
+// notifyEvent sends the event of the request tenant to the webhooks, the request id is added to the data
+func notifyEvent(ctx context.Context, svcCtx *common.SvcContext, eventType string, data map[string]any) {
+	if id := reqinfo.RequestID(ctx); id != "" {
+		data["request_id"] = id
+	}
+	svcCtx.Webhooks.Emit(eventType, tenant.FromContext(ctx), data)
+}
+
+// missingCaptError tells an expired captcha from an unknown one by the generation time in the key
+func missingCaptError(key string, cacheTTL int) *errcode.Error {
+	if t, ok := helper.ParseIDTime(key); ok && time.Since(t) >= time.Duration(cacheTTL)*time.Second {
+		return errcode.ErrCaptchaExpired
+	}
+	return errcode.ErrCaptchaNotFound
 }
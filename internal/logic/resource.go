@@ -8,18 +8,21 @@ package logic
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path"
 	"path/filepath"
 
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -46,15 +49,14 @@ func NewResourceLogic(svcCtx *common.SvcContext) *ResourceLogic {
 
 // SaveResource .
 func (cl *ResourceLogic) SaveResource(ctx context.Context, dirname string, files []*multipart.FileHeader) (ret, allDone bool, err error) {
-	resourcePath := helper.GetResourceDirAbsPath()
-	dirPath := filepath.Join(resourcePath, dirname)
-	dirPath = filepath.Clean(dirPath)
+	ctx, span := tracing.Start(ctx, "ResourceLogic.SaveResource", attribute.String("resource.dirname", dirname))
+	defer func() { tracing.End(span, err) }()
 
-	if !helper.IsSubPath(resourcePath, dirPath) {
-		return false, false, fmt.Errorf("invalid dirpath")
-	}
+	audit.SetTarget(ctx, dirname)
+	saved := make(map[string]string, len(files))
+	defer func() { audit.SetChange(ctx, &audit.Change{Files: saved}) }()
 
-	err = helper.EnsureDir(dirPath)
+	dirPath, err := ensureResourceDir(cl.svcCtx.GetResourceDir(ctx), dirname)
 	if err != nil {
 		return false, false, err
 	}
@@ -63,13 +65,13 @@ func (cl *ResourceLogic) SaveResource(ctx context.Context, dirname string, files
 	for _, fileHeader := range files {
 		file, err := fileHeader.Open()
 		if err != nil {
-			return false, false, fmt.Errorf("failed to open file %s: %v", fileHeader.Filename, err)
+			return false, false, errcode.ErrInternal.Wrapf("failed to open file %s: %v", fileHeader.Filename, err)
 		}
 		defer file.Close()
 
 		filename := filepath.Base(fileHeader.Filename)
 		if filename == "" {
-			return false, false, fmt.Errorf("invalid filename")
+			return false, false, errcode.ErrInvalidArgument.WithMessage("invalid filename")
 		}
 
 		dstPath := filepath.Join(dirPath, filename)
@@ -81,30 +83,88 @@ func (cl *ResourceLogic) SaveResource(ctx context.Context, dirname string, files
 
 		dst, err := os.Create(dstPath)
 		if err != nil {
-			return false, false, fmt.Errorf("failed to create file %s: %v", filename, err)
+			return false, false, errcode.ErrInternal.Wrapf("failed to create file %s: %v", filename, err)
 		}
 		defer dst.Close()
 
-		if _, err := io.Copy(dst, file); err != nil {
-			return false, false, fmt.Errorf("failed to save file %s: %v", filename, err)
+		hasher := audit.NewHasher()
+		if _, err := io.Copy(io.MultiWriter(dst, hasher), file); err != nil {
+			return false, false, errcode.ErrInternal.Wrapf("failed to save file %s: %v", filename, err)
 		}
+		saved[filename] = hasher.Sum()
+	}
+
+	cl.NotifyUploaded(ctx, dirname, saved)
+	return true, !hasSkipFileSave, nil
+}
+
+// NotifyUploaded notifies the webhooks of the files saved in the directory, keyed by name with their hash
+func (cl *ResourceLogic) NotifyUploaded(ctx context.Context, dirname string, files map[string]string) {
+	if len(files) > 0 {
+		notifyEvent(ctx, cl.svcCtx, config.WebhookEventResourceUploaded, map[string]any{"dirname": dirname, "files": files})
+	}
+}
+
+// CreateResourceFile creates a resource file for writing, exists reports that the file has already been saved
+func (cl *ResourceLogic) CreateResourceFile(ctx context.Context, dirname, filename string) (dst *os.File, exists bool, err error) {
+	_, span := tracing.Start(ctx, "ResourceLogic.CreateResourceFile",
+		attribute.String("resource.dirname", dirname),
+		attribute.String("resource.filename", filename),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	dirPath, err := ensureResourceDir(cl.svcCtx.GetResourceDir(ctx), dirname)
+	if err != nil {
+		return nil, false, err
+	}
+
+	filename = filepath.Base(filename)
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		return nil, false, errcode.ErrInvalidArgument.WithMessage("invalid filename")
+	}
+
+	dstPath := filepath.Join(dirPath, filename)
+	if helper.FileExists(dstPath) {
+		return nil, true, nil
+	}
+
+	dst, err = os.Create(dstPath)
+	if err != nil {
+		return nil, false, errcode.ErrInternal.Wrapf("failed to create file %s: %v", filename, err)
+	}
+
+	return dst, false, nil
+}
+
+// ensureResourceDir resolves the directory under the resource path and makes sure it exists
+func ensureResourceDir(resourcePath, dirname string) (string, error) {
+	dirPath := filepath.Join(resourcePath, dirname)
+	dirPath = filepath.Clean(dirPath)
+
+	if !helper.IsSubPath(resourcePath, dirPath) {
+		return "", errcode.ErrResourceInvalidPath
 	}
 
-	if hasSkipFileSave {
-		return true, false, fmt.Errorf("some files failed to be uploaded. check if they already exist")
+	if err := helper.EnsureDir(dirPath); err != nil {
+		return "", errcode.ErrInternal.Wrap(err)
 	}
 
-	return true, true, nil
+	return dirPath, nil
 }
 
 // GetResourceList .
 func (cl *ResourceLogic) GetResourceList(ctx context.Context, filepath string) ([]string, error) {
-	resourcePath := helper.GetResourceDirAbsPath()
+	_, span := tracing.Start(ctx, "ResourceLogic.GetResourceList", attribute.String("resource.path", filepath))
+	defer span.End()
+
+	audit.SetTarget(ctx, filepath)
+
+	resourcePath := cl.svcCtx.GetResourceDir(ctx)
 	filepath = path.Join(resourcePath, filepath)
 	filepath = path.Clean(filepath)
 
 	if !helper.IsSubPath(resourcePath, path.Dir(filepath)) {
-		return nil, fmt.Errorf("invalid filepath")
+		return nil, errcode.ErrResourceInvalidPath
 	}
 
 	fileList, err := helper.TraverseDir(filepath, resourcePath)
@@ -117,20 +177,29 @@ func (cl *ResourceLogic) GetResourceList(ctx context.Context, filepath string) (
 
 // DelResource .
 func (cl *ResourceLogic) DelResource(ctx context.Context, filepath string) (ret bool, err error) {
-	resourcePath := helper.GetResourceDirAbsPath()
+	_, span := tracing.Start(ctx, "ResourceLogic.DelResource", attribute.String("resource.path", filepath))
+	defer func() { tracing.End(span, err) }()
+
+	audit.SetTarget(ctx, filepath)
+	target := filepath
+
+	resourcePath := cl.svcCtx.GetResourceDir(ctx)
 	filepath = path.Join(resourcePath, filepath)
 	filepath = path.Clean(filepath)
 
 	if !helper.IsSubPath(resourcePath, path.Dir(filepath)) {
-		return false, fmt.Errorf("invalid filepath")
+		return false, errcode.ErrResourceInvalidPath
 	}
 
 	if helper.FileExists(filepath) {
+		hash := audit.HashFile(filepath)
+		audit.SetChange(ctx, &audit.Change{Before: hash})
 		err = helper.DeleteFile(filepath)
 		if err != nil {
 			cl.logger.Error("failed to delete resource, err: ", zap.Error(err))
-			return false, err
+			return false, errcode.ErrInternal.Wrap(err)
 		}
+		notifyEvent(ctx, cl.svcCtx, config.WebhookEventResourceDeleted, map[string]any{"path": target, "hash": hash})
 	} else {
 		return false, nil
 	}
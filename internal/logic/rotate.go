@@ -9,16 +9,21 @@ package logic
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"time"
 
 	"github.com/wenlng/go-captcha-service/internal/adapt"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
 	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 	"github.com/wenlng/go-captcha/v2/rotate"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -45,41 +50,53 @@ func NewRotateCaptLogic(svcCtx *common.SvcContext) *RotateCaptLogic {
 
 // GetData .
 func (cl *RotateCaptLogic) GetData(ctx context.Context, id string) (res *adapt.CaptData, err error) {
+	ctx, span := tracing.Start(ctx, "RotateCaptLogic.GetData", attribute.String("captcha.id", id))
+	defer func() { tracing.End(span, err) }()
+
 	res = &adapt.CaptData{}
 
 	if id == "" {
-		return nil, fmt.Errorf("missing id parameter")
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
 	}
 
 	var capt *gocaptcha.RotateCaptInstance
-	ttype := cl.svcCtx.Captcha.GetCaptTypeWithKey(id)
+	captcha := cl.svcCtx.GetCaptcha(ctx)
+	ttype := captcha.GetCaptTypeWithKey(id)
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{ID: id, Type: consts.GoCaptchaTypeName(ttype)})
 	switch ttype {
 	case consts.GoCaptchaTypeRotate:
-		capt = cl.svcCtx.Captcha.GetRotateInstanceWithKey(id)
+		capt = captcha.GetRotateInstanceWithKey(id)
 		break
 	}
 	if capt == nil || capt.Instance == nil {
-		return nil, fmt.Errorf("missing captcha type")
+		return nil, errcode.ErrCaptchaTypeNotFound
 	}
 
-	captData, err := capt.Instance.Generate()
+	if err = captcha.GenerateQueue.Acquire(ctx); err != nil {
+		return nil, errcode.ErrCaptchaGenerateBusy.Wrapf("failed to acquire generate queue: %v", err)
+	}
+	captData, err := capt.Generate(ctx)
+	captcha.GenerateQueue.Release()
 	if err != nil {
-		return nil, fmt.Errorf("generate captcha data failed: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("generate captcha data failed: %v", err)
 	}
 
 	data := captData.GetData()
 	if data == nil {
-		return nil, fmt.Errorf("generate captcha data failed: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("generate captcha data failed: %v", err)
 	}
 
+	_, encodeSpan := tracing.Start(ctx, "RotateCaptLogic.EncodeBase64")
 	res.MasterImageBase64, err = captData.GetMasterImage().ToBase64()
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert base64 encoding: %v", err)
+		tracing.End(encodeSpan, err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("failed to convert base64 encoding: %v", err)
 	}
 
 	res.ThumbImageBase64, err = captData.GetThumbImage().ToBase64()
+	tracing.End(encodeSpan, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert base64 encoding: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("failed to convert base64 encoding: %v", err)
 	}
 
 	cacheData := &cache.CaptCacheData{
@@ -89,18 +106,21 @@ func (cl *RotateCaptLogic) GetData(ctx context.Context, id string) (res *adapt.C
 	}
 	cacheDataByte, err := json.Marshal(cacheData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to json marshal: %v", err)
+		return nil, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 	}
 
 	key, err := helper.GenerateIDWithNode(cl.dynamicCfg.Get().ServiceNode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate id: %v", err)
+		return nil, errcode.ErrInternal.Wrapf("failed to generate id: %v", err)
 	}
 
-	err = cl.cacheMgr.GetCache().SetCache(ctx, key, string(cacheDataByte))
+	err = cl.svcCtx.GetCache(ctx).SetCache(ctx, key, string(cacheDataByte))
 	if err != nil {
-		return res, fmt.Errorf("failed to write cache:: %v", err)
+		return nil, errcode.ErrCacheUnavailable.Wrapf("failed to write cache: %v", err)
 	}
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{Key: key})
+	cl.svcCtx.Tenants.Record(tenant.FromContext(ctx), tenant.StatGenerated)
+	res.ExpiresAt = time.Now().Add(time.Duration(cl.dynamicCfg.Get().CacheTTL) * time.Second)
 
 	opts := capt.Instance.GetOptions()
 	res.MasterWidth = int32(opts.GetImageSize())
@@ -113,38 +133,28 @@ func (cl *RotateCaptLogic) GetData(ctx context.Context, id string) (res *adapt.C
 }
 
 // CheckData .
-func (cl *RotateCaptLogic) CheckData(ctx context.Context, key string, angle int) (bool, error) {
-	if key == "" {
-		return false, fmt.Errorf("invalid key")
-	}
+func (cl *RotateCaptLogic) CheckData(ctx context.Context, key string, angle int) (ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "RotateCaptLogic.CheckData", attribute.String("captcha.key", key))
+	defer func() { tracing.End(span, err) }()
 
-	cacheData, err := cl.cacheMgr.GetCache().GetCache(ctx, key)
+	cacheCaptData, cacheData, err := loadCaptCacheData(ctx, cl.svcCtx.GetCache(ctx), cl.dynamicCfg, key)
 	if err != nil {
-		return false, fmt.Errorf("failed to get cache: %v", err)
+		return false, err
 	}
-
-	if cacheData == "" {
-		return false, nil
-	}
-
-	var cacheCaptData *cache.CaptCacheData
-	err = json.Unmarshal([]byte(cacheData), &cacheCaptData)
-	if err != nil {
-		return false, fmt.Errorf("failed to json unmarshal: %v", err)
+	// A captcha can only be verified once
+	if cacheCaptData.Status != 0 {
+		notifyAttemptLocked(ctx, cl.svcCtx, key, cacheCaptData.Status)
+		return false, errcode.ErrCaptchaAlreadyUsed
 	}
 
 	var dct *rotate.Block
 	captDataStr, err := json.Marshal(cacheCaptData.Data)
 	if err != nil {
-		return false, fmt.Errorf("failed to json marshal: %v", err)
+		return false, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 	}
 	err = json.Unmarshal(captDataStr, &dct)
 	if err != nil {
-		return false, fmt.Errorf("failed to json unmarshal: %v", err)
-	}
-
-	if cacheCaptData.Status == 2 {
-		return false, nil
+		return false, errcode.ErrInternal.Wrapf("failed to json unmarshal: %v", err)
 	}
 
 	ret := rotate.Validate(angle, dct.Angle, 2)
@@ -155,15 +165,13 @@ func (cl *RotateCaptLogic) CheckData(ctx context.Context, key string, angle int)
 		cacheCaptData.Status = 2
 	}
 
-	cacheDataByte, err := json.Marshal(cacheCaptData)
-	if err != nil {
-		return ret, fmt.Errorf("failed to json marshal: %v", err)
+	if err = claimCaptCacheData(ctx, cl.svcCtx, key, cacheData, cacheCaptData); err != nil {
+		return false, err
 	}
+	recordCheckStat(ctx, cl.svcCtx, key, ret)
 
-	err = cl.cacheMgr.GetCache().SetCache(ctx, key, string(cacheDataByte))
-	if err != nil {
-		return ret, fmt.Errorf("failed to update cache:: %v", err)
+	if !ret {
+		return false, errcode.ErrCaptchaAnswerIncorrect
 	}
-
-	return ret, nil
+	return true, nil
 }
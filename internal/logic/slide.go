@@ -9,18 +9,23 @@ package logic
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/wenlng/go-captcha-service/internal/adapt"
 	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
 	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 	"github.com/wenlng/go-captcha/v2/slide"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -47,44 +52,56 @@ func NewSlideCaptLogic(svcCtx *common.SvcContext) *SlideCaptLogic {
 
 // GetData .
 func (cl *SlideCaptLogic) GetData(ctx context.Context, id string) (res *adapt.CaptData, err error) {
+	ctx, span := tracing.Start(ctx, "SlideCaptLogic.GetData", attribute.String("captcha.id", id))
+	defer func() { tracing.End(span, err) }()
+
 	res = &adapt.CaptData{}
 
 	if id == "" {
-		return nil, fmt.Errorf("missing id parameter")
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
 	}
 
 	var capt *gocaptcha.SlideCaptInstance
-	ttype := cl.svcCtx.Captcha.GetCaptTypeWithKey(id)
+	captcha := cl.svcCtx.GetCaptcha(ctx)
+	ttype := captcha.GetCaptTypeWithKey(id)
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{ID: id, Type: consts.GoCaptchaTypeName(ttype)})
 	switch ttype {
 	case consts.GoCaptchaTypeSlide:
-		capt = cl.svcCtx.Captcha.GetSlideInstanceWithKey(id)
+		capt = captcha.GetSlideInstanceWithKey(id)
 		break
 	case consts.GoCaptchaTypeDrag:
-		capt = cl.svcCtx.Captcha.GetDragInstanceWithKey(id)
+		capt = captcha.GetDragInstanceWithKey(id)
 		break
 	}
 	if capt == nil || capt.Instance == nil {
-		return nil, fmt.Errorf("missing captcha type")
+		return nil, errcode.ErrCaptchaTypeNotFound
 	}
 
-	captData, err := capt.Instance.Generate()
+	if err = captcha.GenerateQueue.Acquire(ctx); err != nil {
+		return nil, errcode.ErrCaptchaGenerateBusy.Wrapf("failed to acquire generate queue: %v", err)
+	}
+	captData, err := capt.Generate(ctx)
+	captcha.GenerateQueue.Release()
 	if err != nil {
-		return nil, fmt.Errorf("generate captcha data failed: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("generate captcha data failed: %v", err)
 	}
 
 	data := captData.GetData()
 	if data == nil {
-		return nil, fmt.Errorf("generate captcha data failed: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("generate captcha data failed: %v", err)
 	}
 
+	_, encodeSpan := tracing.Start(ctx, "SlideCaptLogic.EncodeBase64")
 	res.MasterImageBase64, err = captData.GetMasterImage().ToBase64()
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert base64 encoding: %v", err)
+		tracing.End(encodeSpan, err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("failed to convert base64 encoding: %v", err)
 	}
 
 	res.ThumbImageBase64, err = captData.GetTileImage().ToBase64()
+	tracing.End(encodeSpan, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert base64 encoding: %v", err)
+		return nil, errcode.ErrCaptchaGenerateFailed.Wrapf("failed to convert base64 encoding: %v", err)
 	}
 
 	cacheData := &cache.CaptCacheData{
@@ -94,18 +111,21 @@ func (cl *SlideCaptLogic) GetData(ctx context.Context, id string) (res *adapt.Ca
 	}
 	cacheDataByte, err := json.Marshal(cacheData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to json marshal: %v", err)
+		return nil, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 	}
 
 	key, err := helper.GenerateIDWithNode(cl.dynamicCfg.Get().ServiceNode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate id: %v", err)
+		return nil, errcode.ErrInternal.Wrapf("failed to generate id: %v", err)
 	}
 
-	err = cl.cacheMgr.GetCache().SetCache(ctx, key, string(cacheDataByte))
+	err = cl.svcCtx.GetCache(ctx).SetCache(ctx, key, string(cacheDataByte))
 	if err != nil {
-		return res, fmt.Errorf("failed to write cache:: %v", err)
+		return nil, errcode.ErrCacheUnavailable.Wrapf("failed to write cache: %v", err)
 	}
+	reqinfo.SetCaptcha(ctx, reqinfo.Captcha{Key: key})
+	cl.svcCtx.Tenants.Record(tenant.FromContext(ctx), tenant.StatGenerated)
+	res.ExpiresAt = time.Now().Add(time.Duration(cl.dynamicCfg.Get().CacheTTL) * time.Second)
 
 	opts := capt.Instance.GetOptions()
 	res.MasterWidth = int32(opts.GetImageSize().Width)
@@ -119,48 +139,49 @@ func (cl *SlideCaptLogic) GetData(ctx context.Context, id string) (res *adapt.Ca
 	return res, nil
 }
 
-// CheckData .
+// CheckData verifies the comma-separated "x,y" point
 func (cl *SlideCaptLogic) CheckData(ctx context.Context, key string, dots string) (bool, error) {
-	if key == "" {
-		return false, fmt.Errorf("invalid key")
+	var answer *adapt.SlideAnswer
+	if src := strings.Split(dots, ","); len(src) == 2 {
+		sx, _ := strconv.Atoi(src[0])
+		sy, _ := strconv.Atoi(src[1])
+		answer = &adapt.SlideAnswer{Point: adapt.Point{X: int32(sx), Y: int32(sy)}}
 	}
 
-	cacheData, err := cl.cacheMgr.GetCache().GetCache(ctx, key)
-	if err != nil {
-		return false, fmt.Errorf("failed to get cache: %v", err)
-	}
+	return cl.CheckAnswer(ctx, key, answer)
+}
 
-	if cacheData == "" {
-		return false, nil
+// CheckAnswer verifies the final point of the slide, the track is recorded for tracing only
+func (cl *SlideCaptLogic) CheckAnswer(ctx context.Context, key string, answer *adapt.SlideAnswer) (ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "SlideCaptLogic.CheckAnswer", attribute.String("captcha.key", key))
+	defer func() { tracing.End(span, err) }()
+	if answer != nil {
+		span.SetAttributes(attribute.Int("captcha.track_points", len(answer.Track)))
 	}
 
-	src := strings.Split(dots, ",")
-
-	var cacheCaptData *cache.CaptCacheData
-	err = json.Unmarshal([]byte(cacheData), &cacheCaptData)
+	cacheCaptData, cacheData, err := loadCaptCacheData(ctx, cl.svcCtx.GetCache(ctx), cl.dynamicCfg, key)
 	if err != nil {
-		return false, fmt.Errorf("failed to json unmarshal: %v", err)
+		return false, err
+	}
+	// A captcha can only be verified once
+	if cacheCaptData.Status != 0 {
+		notifyAttemptLocked(ctx, cl.svcCtx, key, cacheCaptData.Status)
+		return false, errcode.ErrCaptchaAlreadyUsed
 	}
 
 	var dct *slide.Block
 	captDataStr, err := json.Marshal(cacheCaptData.Data)
 	if err != nil {
-		return false, fmt.Errorf("failed to json marshal: %v", err)
+		return false, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 	}
 	err = json.Unmarshal(captDataStr, &dct)
 	if err != nil {
-		return false, fmt.Errorf("failed to json unmarshal: %v", err)
-	}
-
-	if cacheCaptData.Status == 2 {
-		return false, nil
+		return false, errcode.ErrInternal.Wrapf("failed to json unmarshal: %v", err)
 	}
 
 	ret := false
-	if 2 == len(src) {
-		sx, _ := strconv.Atoi(src[0])
-		sy, _ := strconv.Atoi(src[1])
-		ret = slide.Validate(sx, sy, dct.X, dct.Y, 4)
+	if answer != nil {
+		ret = slide.Validate(int(answer.Point.X), int(answer.Point.Y), dct.X, dct.Y, 4)
 	}
 
 	if ret {
@@ -169,15 +190,13 @@ func (cl *SlideCaptLogic) CheckData(ctx context.Context, key string, dots string
 		cacheCaptData.Status = 2
 	}
 
-	cacheDataByte, err := json.Marshal(cacheCaptData)
-	if err != nil {
-		return ret, fmt.Errorf("failed to json marshal: %v", err)
+	if err = claimCaptCacheData(ctx, cl.svcCtx, key, cacheData, cacheCaptData); err != nil {
+		return false, err
 	}
+	recordCheckStat(ctx, cl.svcCtx, key, ret)
 
-	err = cl.cacheMgr.GetCache().SetCache(ctx, key, string(cacheDataByte))
-	if err != nil {
-		return ret, fmt.Errorf("failed to update cache:: %v", err)
+	if !ret {
+		return false, errcode.ErrCaptchaAnswerIncorrect
 	}
-
-	return ret, nil
+	return true, nil
 }
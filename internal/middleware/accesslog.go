@@ -0,0 +1,93 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+)
+
+// grpcHealthServicePrefix is the method prefix of the gRPC health service
+const grpcHealthServicePrefix = "/grpc.health.v1.Health/"
+
+// accessLogEntry is one request of the access log
+type accessLogEntry struct {
+	method    string
+	path      string
+	status    zap.Field
+	client    string
+	requestID string
+	keyID     string
+	captcha   reqinfo.Captcha
+	bytes     int64
+	duration  time.Duration
+	userAgent string
+	err       error
+}
+
+// isHealthCheck reports whether the HTTP path or the gRPC method is a health check
+func isHealthCheck(path string) bool {
+	switch path {
+	case "/status/health", "/status/live", "/status/ready":
+		return true
+	}
+	return strings.HasPrefix(path, grpcHealthServicePrefix)
+}
+
+// shouldLogAccess applies the health check exclusion and samples the successful requests
+func shouldLogAccess(accessLog config.AccessLog, path string, failed bool) bool {
+	if accessLog.ExcludeHealth && isHealthCheck(path) {
+		return false
+	}
+	return failed || accessLog.SuccessSampleRate >= 1 || rand.Float64() < accessLog.SuccessSampleRate
+}
+
+// accessLogFields returns the configured fields of the entry
+func accessLogFields(accessLog config.AccessLog, e accessLogEntry) []zap.Field {
+	fields := make([]zap.Field, 0, len(accessLog.Fields))
+	for _, name := range accessLog.Fields {
+		switch name {
+		case config.AccessLogFieldMethod:
+			fields = append(fields, zap.String("method", e.method))
+		case config.AccessLogFieldPath:
+			if e.path != "" {
+				fields = append(fields, zap.String("path", e.path))
+			}
+		case config.AccessLogFieldStatus:
+			fields = append(fields, e.status)
+		case config.AccessLogFieldClient:
+			fields = append(fields, zap.String("client", e.client))
+		case config.AccessLogFieldRequestID:
+			fields = append(fields, zap.String("request_id", e.requestID))
+		case config.AccessLogFieldKeyID:
+			fields = append(fields, zap.String("key_id", e.keyID))
+		case config.AccessLogFieldCaptchaID:
+			fields = append(fields, zap.String("captcha_id", e.captcha.ID))
+		case config.AccessLogFieldCaptchaType:
+			fields = append(fields, zap.String("captcha_type", e.captcha.Type))
+		case config.AccessLogFieldCaptchaKey:
+			fields = append(fields, zap.String("captcha_key", e.captcha.Key))
+		case config.AccessLogFieldBytes:
+			fields = append(fields, zap.Int64("bytes", e.bytes))
+		case config.AccessLogFieldDuration:
+			fields = append(fields, zap.Duration("duration", e.duration))
+		case config.AccessLogFieldUserAgent:
+			fields = append(fields, zap.String("user_agent", e.userAgent))
+		case config.AccessLogFieldError:
+			if e.err != nil {
+				fields = append(fields, zap.Error(e.err))
+			}
+		}
+	}
+	return fields
+}
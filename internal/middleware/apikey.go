@@ -0,0 +1,105 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// operationScopes maps the normalized operation names to the scope they require
+var operationScopes = map[string]string{
+	"getdata":          config.ScopeCaptchaVerify,
+	"checkdata":        config.ScopeCaptchaVerify,
+	"checkstatus":      config.ScopeCaptchaVerify,
+	"getstatus":        config.ScopeCaptchaVerify,
+	"getstatusinfo":    config.ScopeStatusRead,
+	"gettenantstats":   config.ScopeStatusRead,
+	"delstatusinfo":    config.ScopeStatusWrite,
+	"getresourcelist":  config.ScopeResourceRead,
+	"uploadresource":   config.ScopeResourceWrite,
+	"deleteresource":   config.ScopeResourceWrite,
+	"getconfig":        config.ScopeConfigRead,
+	"updatehotconfig":  config.ScopeConfigWrite,
+	"ratelimit":        config.ScopeConfigWrite,
+	"getauditlog":      config.ScopeAuditRead,
+	"getwebhookstatus": config.ScopeConfigRead,
+}
+
+// RequiredScope returns the scope required by an HTTP path or a gRPC full method, empty when any key is accepted
+func RequiredScope(route string) string {
+	op := strings.ToLower(strings.ReplaceAll(path.Base(route), "-", ""))
+	return operationScopes[op]
+}
+
+// authenticateAPIKey resolves the API key of the secret and checks its expiry and scope for the route
+func authenticateAPIKey(cfg *config.Config, secret, route string) (config.APIKey, error) {
+	if !cfg.HasAPIKeys() || secret == "" {
+		return config.APIKey{}, errcode.ErrUnauthenticated.WithMessage("missing API Key")
+	}
+
+	key, ok := cfg.LookupAPIKey(secret)
+	if !ok {
+		return config.APIKey{}, errcode.ErrUnauthenticated.WithMessage("invalid API Key")
+	}
+	return key, authorizeAPIKey(key, route)
+}
+
+// authorizeAPIKey checks the expiry of the API key and its scope for the route
+func authorizeAPIKey(key config.APIKey, route string) error {
+	if key.Expired(time.Now()) {
+		return errcode.ErrUnauthenticated.WithMessage("API Key expired")
+	}
+	if scope := RequiredScope(route); !key.HasScope(scope) {
+		return errcode.ErrForbidden.WithMessage("API Key lacks the " + scope + " scope")
+	}
+	return nil
+}
+
+// apiKeyIDKey .
+type apiKeyIDKey struct{}
+
+// apiKeyIDHolder lets the outer middlewares read the key id resolved by the inner ones
+type apiKeyIDHolder struct {
+	mu sync.Mutex
+	id string
+}
+
+// withAPIKeyIDHolder adds a holder for the key id to the context
+func withAPIKeyIDHolder(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(apiKeyIDKey{}).(*apiKeyIDHolder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyIDKey{}, &apiKeyIDHolder{})
+}
+
+// WithAPIKeyID stores the id of the authenticated API key in the context
+func WithAPIKeyID(ctx context.Context, id string) context.Context {
+	ctx = withAPIKeyIDHolder(ctx)
+	holder := ctx.Value(apiKeyIDKey{}).(*apiKeyIDHolder)
+	holder.mu.Lock()
+	holder.id = id
+	holder.mu.Unlock()
+	return ctx
+}
+
+// APIKeyIDFromContext returns the id of the authenticated API key, empty if none
+func APIKeyIDFromContext(ctx context.Context) string {
+	holder, ok := ctx.Value(apiKeyIDKey{}).(*apiKeyIDHolder)
+	if !ok {
+		return ""
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.id
+}
@@ -0,0 +1,138 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/wenlng/go-captcha-service/internal/audit"
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuditAction returns the audited action of an HTTP path or a gRPC full method,
+// the gRPC method names are converted to the kebab case of the HTTP routes
+func AuditAction(route string) string {
+	name := path.Base(route)
+	var b strings.Builder
+	for i, c := range name {
+		if unicode.IsUpper(c) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// auditResult returns the result of an operation
+func auditResult(failed bool) string {
+	if failed {
+		return audit.ResultFailure
+	}
+	return audit.ResultSuccess
+}
+
+// AuditMiddleware records the manage requests to the audit log, it must run inside the logging middleware
+func AuditMiddleware(auditLog *audit.Log, dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if auditLog == nil || HTTPRateLimitScope(r.URL.Path) != config.RateLimitScopeManage {
+				next(w, r)
+				return
+			}
+
+			ctx := audit.WithHolder(r.Context())
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next(sw, r.WithContext(ctx))
+
+			cfg := dc.Get()
+			target, change := audit.FromContext(ctx)
+			entry := audit.Entry{
+				Actor:     APIKeyIDFromContext(ctx),
+				Source:    ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"), ParseIPNets(cfg.GetTrustedProxies())),
+				Transport: audit.TransportHTTP,
+				Action:    AuditAction(r.URL.Path),
+				Target:    target,
+				Change:    change,
+				Result:    auditResult(sw.status >= http.StatusBadRequest),
+				Status:    strconv.Itoa(sw.status),
+				RequestID: reqinfo.RequestID(ctx),
+			}
+			if err := auditLog.Record(entry); err != nil {
+				logger.Error("[HttpMiddleware] Failed to record the audit entry", zap.String("action", entry.Action), zap.Error(err))
+			}
+		}
+	}
+}
+
+// GRPCAuditMiddleware records the manage calls to the audit log, it must run inside the logging middleware
+func GRPCAuditMiddleware(auditLog *audit.Log, dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	record := func(ctx context.Context, method string, err error) {
+		var remoteAddr string
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			remoteAddr = p.Addr.String()
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		cfg := dc.Get()
+		target, change := audit.FromContext(ctx)
+		entry := audit.Entry{
+			Actor:     APIKeyIDFromContext(ctx),
+			Source:    ClientIP(remoteAddr, firstMetadata(md, "x-forwarded-for"), firstMetadata(md, "x-real-ip"), ParseIPNets(cfg.GetTrustedProxies())),
+			Transport: audit.TransportGRPC,
+			Action:    AuditAction(method),
+			Target:    target,
+			Change:    change,
+			Result:    auditResult(err != nil),
+			Status:    status.Code(err).String(),
+			RequestID: reqinfo.RequestID(ctx),
+		}
+		if err != nil {
+			entry.Error = status.Convert(err).Message()
+		}
+		if err := auditLog.Record(entry); err != nil {
+			logger.Error("[GrpcMiddleware] Failed to record the audit entry", zap.String("action", entry.Action), zap.Error(err))
+		}
+	}
+
+	audited := func(method string) bool {
+		return auditLog != nil && GRPCRateLimitScope(method) == config.RateLimitScopeManage
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if !audited(info.FullMethod) {
+				return handler(ctx, req)
+			}
+			ctx = audit.WithHolder(ctx)
+			resp, err := handler(ctx, req)
+			record(ctx, info.FullMethod, err)
+			return resp, err
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if !audited(info.FullMethod) {
+				return handler(srv, ss)
+			}
+			ctx := audit.WithHolder(ss.Context())
+			err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+			record(ctx, info.FullMethod, err)
+			return err
+		},
+	}
+}
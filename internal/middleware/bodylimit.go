@@ -0,0 +1,43 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// errBodyTooLarge .
+var errBodyTooLarge = errcode.ErrResourceTooLarge.WithMessage("request body too large")
+
+// BodyLimitMiddleware limits the request body to the limit of the route, a declared length over the limit is rejected before reading
+func BodyLimitMiddleware(dc *config.DynamicConfig) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			limit := cfg.GetServerLimits().BodyLimit(r.URL.Path)
+			if r.ContentLength > limit {
+				WriteAppError(w, errBodyTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next(w, r)
+		}
+	}
+}
+
+// BodyReadError returns the error of a request body failed to be read or decoded, the bodies over the limit are reported as too large
+func BodyReadError(err error, message string) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errBodyTooLarge
+	}
+	return errcode.ErrInvalidArgument.WithMessage(message)
+}
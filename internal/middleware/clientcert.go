@@ -0,0 +1,85 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// hasVerifiedClientCert .
+func hasVerifiedClientCert(state *tls.ConnectionState) bool {
+	return state != nil && len(state.VerifiedChains) > 0
+}
+
+// ClientCertMiddleware requires a verified client certificate on the manage routes when tls_client_auth is manage
+func ClientCertMiddleware(dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			if cfg.TlsClientAuth != config.TlsClientAuthManage || HTTPRateLimitScope(r.URL.Path) != config.RateLimitScopeManage {
+				next(w, r)
+				return
+			}
+			if !hasVerifiedClientCert(r.TLS) {
+				logger.Warn("[HttpMiddleware] Client certificate required",
+					zap.String("path", r.URL.Path),
+					zap.String("client", r.RemoteAddr),
+				)
+				WriteAppError(w, errcode.ErrUnauthenticated.WithMessage("client certificate required"))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// GRPCClientCertMiddleware requires a verified client certificate on the manage methods when tls_client_auth is manage
+func GRPCClientCertMiddleware(dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	check := func(ctx context.Context, method string) error {
+		cfg := dc.Get()
+		if cfg.TlsClientAuth != config.TlsClientAuthManage || GRPCRateLimitScope(method) != config.RateLimitScopeManage {
+			return nil
+		}
+
+		var state *tls.ConnectionState
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				state = &info.State
+			}
+		}
+		if !hasVerifiedClientCert(state) {
+			logger.Warn("[GrpcMiddleware] Client certificate required", zap.String("method", method))
+			return errcode.ErrUnauthenticated.WithMessage("client certificate required")
+		}
+		return nil
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	}
+}
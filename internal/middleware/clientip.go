@@ -0,0 +1,80 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"net"
+	"strings"
+)
+
+// ParseIPNets parses the IP addresses and CIDR blocks, invalid entries are skipped
+func ParseIPNets(values []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			nets = append(nets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return nets
+}
+
+// ClientIP returns the client address of the request.
+// The forwarded headers are only honoured when the peer is a trusted proxy, X-Forwarded-For
+// is walked from right to left and the first address that is not a trusted proxy is the client.
+func ClientIP(remoteAddr, forwardedFor, realIP string, trusted []*net.IPNet) string {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	if !containsIP(host, trusted) {
+		return host
+	}
+
+	if forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			host = hop
+			if !containsIP(hop, trusted) {
+				return hop
+			}
+		}
+		return host
+	}
+
+	if ip := strings.TrimSpace(realIP); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return host
+}
+
+// containsIP reports whether the address is in one of the networks
+func containsIP(host string, nets []*net.IPNet) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
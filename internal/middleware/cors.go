@@ -0,0 +1,135 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// CORSMiddleware implements cross-origin resource sharing with the configured origin allowlist
+func CORSMiddleware(dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			origin := r.Header.Get("Origin")
+			if !cfg.EnableCors || origin == "" {
+				next(w, r)
+				return
+			}
+
+			siteKey := r.Header.Get("X-Site-Key")
+			if siteKey == "" {
+				siteKey = r.URL.Query().Get("site_key")
+			}
+			rule := cfg.GetCORSRule(siteKey)
+
+			matched, ok := matchOrigins(origin, rule.AllowedOrigins)
+			if !ok {
+				logger.Warn("[HttpMiddleware] Origin not allowed",
+					zap.String("path", r.URL.Path),
+					zap.String("origin", origin),
+				)
+				WriteAppError(w, errcode.ErrForbidden.WithMessage("origin not allowed"))
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+			// Credentials are never shared with every origin
+			if rule.AllowCredentials && matched != "*" {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method != http.MethodOptions {
+				next(w, r)
+				return
+			}
+
+			// Handle preflight (OPTIONS) requests
+			if method := r.Header.Get("Access-Control-Request-Method"); method != "" {
+				if !containsFold(rule.AllowedMethods, method) || !headersAllowed(r.Header.Get("Access-Control-Request-Headers"), rule.AllowedHeaders) {
+					logger.Warn("[HttpMiddleware] CORS preflight rejected",
+						zap.String("path", r.URL.Path),
+						zap.String("origin", origin),
+						zap.String("method", method),
+					)
+					WriteAppError(w, errcode.ErrForbidden.WithMessage("cross-origin request not allowed"))
+					return
+				}
+
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(rule.AllowedHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// isOriginAllowed .
+func isOriginAllowed(origin string, allowedOrigins []string) bool {
+	_, ok := matchOrigins(origin, allowedOrigins)
+	return ok
+}
+
+// matchOrigins returns the first pattern matching the origin
+func matchOrigins(origin string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		if matchOrigin(origin, pattern) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// matchOrigin matches an exact origin, "*" or a wildcard subdomain such as https://*.example.com
+func matchOrigin(origin, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	pattern = strings.ToLower(pattern)
+
+	idx := strings.Index(pattern, "://*.")
+	if idx < 0 {
+		return origin == pattern
+	}
+	scheme, suffix := pattern[:idx+3], pattern[idx+4:]
+	if !strings.HasPrefix(origin, scheme) {
+		return false
+	}
+	host := origin[len(scheme):]
+	return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+}
+
+// headersAllowed checks the comma separated request headers against the allowed ones
+func headersAllowed(requested string, allowed []string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !containsFold(allowed, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold .
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if item == "*" || strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
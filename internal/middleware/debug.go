@@ -0,0 +1,49 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// DebugMiddleware hides the debug endpoints unless debug.enable is set.
+// On the public listener they also require an API key with the debug scope, the admin listener is trusted.
+func DebugMiddleware(dc *config.DynamicConfig, logger *zap.Logger, public bool) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			if !cfg.Debug.Enable {
+				http.NotFound(w, r)
+				return
+			}
+			if !public {
+				next(w, r)
+				return
+			}
+
+			key, err := authenticateAPIKey(&cfg, r.Header.Get("X-API-Key"), r.URL.Path)
+			if err == nil && !key.HasScope(config.ScopeDebug) {
+				err = errcode.ErrForbidden.WithMessage("API Key lacks the " + config.ScopeDebug + " scope")
+			}
+			if err != nil {
+				logger.Warn("[HttpMiddleware] Debug endpoint rejected",
+					zap.String("path", r.URL.Path),
+					zap.String("key_id", key.ID),
+					zap.Error(err),
+				)
+				WriteAppError(w, err)
+				return
+			}
+			next(w, r.WithContext(WithAPIKeyID(r.Context(), key.ID)))
+		}
+	}
+}
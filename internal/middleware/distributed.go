@@ -0,0 +1,113 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/cache"
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+const (
+	// distributedRetryInterval is how long the local limiter is used after the backend failed
+	distributedRetryInterval = 5 * time.Second
+	// globalRateLimitKey is the backend key of the global quota
+	globalRateLimitKey = "global"
+)
+
+// DistributedLimiter enforces rate limits shared by all nodes through the cache backend.
+// When the backend is unreachable the callers fall back to their local limiter.
+type DistributedLimiter struct {
+	getCache func() cache.Cache
+	logger   *zap.Logger
+
+	mu        sync.RWMutex
+	enabled   bool
+	timeout   time.Duration
+	downUntil time.Time
+}
+
+// NewDistributedLimiter creates a new distributed rate limiter
+func NewDistributedLimiter(getCache func() cache.Cache, cfg config.Config, logger *zap.Logger) *DistributedLimiter {
+	dl := &DistributedLimiter{getCache: getCache, logger: logger}
+	dl.Update(cfg)
+	return dl
+}
+
+// Update updates the mode and the backend timeout
+func (dl *DistributedLimiter) Update(cfg config.Config) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	dl.enabled = cfg.RateLimitMode == config.RateLimitModeDistributed
+	dl.timeout = time.Duration(cfg.RateLimitBackendTimeout) * time.Millisecond
+}
+
+// Allow takes a token from every key on the backend.
+// handled is false when the distributed mode is off or the backend failed, the local limiter must be used then.
+func (dl *DistributedLimiter) Allow(ctx context.Context, keys []string, qps float64, burst int) (retryAfter time.Duration, ok bool, handled bool) {
+	if dl == nil {
+		return 0, false, false
+	}
+
+	dl.mu.RLock()
+	enabled, timeout, downUntil := dl.enabled, dl.timeout, dl.downUntil
+	dl.mu.RUnlock()
+	if !enabled || time.Now().Before(downUntil) {
+		return 0, false, false
+	}
+
+	store, isStore := dl.getCache().(cache.RateLimitStore)
+	if !isStore {
+		return 0, false, false
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	retryAfter, ok, err := store.AllowRate(ctx, keys, qps, burst)
+	if err != nil {
+		dl.markDown(err)
+		return 0, false, false
+	}
+	dl.markUp()
+	return retryAfter, ok, true
+}
+
+// markDown switches to the local limiter for a while
+func (dl *DistributedLimiter) markDown(err error) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	if dl.downUntil.IsZero() {
+		dl.logger.Warn("[RateLimit] Distributed rate limit backend unavailable, falling back to the local limiter", zap.Error(err))
+	}
+	dl.downUntil = time.Now().Add(distributedRetryInterval)
+}
+
+// markUp .
+func (dl *DistributedLimiter) markUp() {
+	dl.mu.RLock()
+	down := !dl.downUntil.IsZero()
+	dl.mu.RUnlock()
+	if !down {
+		return
+	}
+
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+	if !dl.downUntil.IsZero() {
+		dl.downUntil = time.Time{}
+		dl.logger.Info("[RateLimit] Distributed rate limit backend recovered")
+	}
+}
@@ -13,66 +13,412 @@ import (
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
 )
 
+// GRPCMiddleware pairs the unary and stream interceptors of one middleware, either may be nil
+type GRPCMiddleware struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// GRPCChain .
+type GRPCChain struct {
+	middlewares []GRPCMiddleware
+}
+
+// NewChainGRPC .
+func NewChainGRPC(mws ...GRPCMiddleware) *GRPCChain {
+	return &GRPCChain{middlewares: mws}
+}
+
+// AppendMiddleware new middlewares
+func (c *GRPCChain) AppendMiddleware(mw GRPCMiddleware) *GRPCChain {
+	c.middlewares = append(c.middlewares, mw)
+
+	return c
+}
+
+// UnaryInterceptors returns the unary interceptors in order, the first one is the outermost
+func (c *GRPCChain) UnaryInterceptors() []grpc.UnaryServerInterceptor {
+	var interceptors []grpc.UnaryServerInterceptor
+	for _, mw := range c.middlewares {
+		if mw.Unary != nil {
+			interceptors = append(interceptors, mw.Unary)
+		}
+	}
+	return interceptors
+}
+
+// StreamInterceptors returns the stream interceptors in order, the first one is the outermost
+func (c *GRPCChain) StreamInterceptors() []grpc.StreamServerInterceptor {
+	var interceptors []grpc.StreamServerInterceptor
+	for _, mw := range c.middlewares {
+		if mw.Stream != nil {
+			interceptors = append(interceptors, mw.Stream)
+		}
+	}
+	return interceptors
+}
+
+// ServerOptions returns the server options installing the chain
+func (c *GRPCChain) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(c.UnaryInterceptors()...),
+		grpc.ChainStreamInterceptor(c.StreamInterceptors()...),
+	}
+}
+
+// Unary chains the unary interceptors into one
+func (c *GRPCChain) Unary() grpc.UnaryServerInterceptor {
+	interceptors := c.UnaryInterceptors()
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// Stream chains the stream interceptors into one
+func (c *GRPCChain) Stream() grpc.StreamServerInterceptor {
+	interceptors := c.StreamInterceptors()
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, inner)
+			}
+		}
+		return next(srv, ss)
+	}
+}
+
+// contextServerStream overrides the context of a server stream
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context .
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
 // UnaryServerInterceptor implements gRPC unary interceptor
 func UnaryServerInterceptor(dc *config.DynamicConfig, logger *zap.Logger, breaker *gobreaker.CircuitBreaker) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		start := time.Now()
+	return NewChainGRPC(
+		GRPCLoggingMiddleware(logger, dc),
+		GRPCAPIKeyMiddleware(dc, logger),
+		GRPCCircuitBreakerMiddleware(breaker, logger),
+	).Unary()
+}
+
+// StreamServerInterceptor implements gRPC stream interceptor
+func StreamServerInterceptor(dc *config.DynamicConfig, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return NewChainGRPC(
+		GRPCLoggingMiddleware(logger, dc),
+		GRPCAPIKeyMiddleware(dc, logger),
+	).Stream()
+}
+
+// GRPCRecoveryMiddleware turns panics of the handlers into internal errors
+func GRPCRecoveryMiddleware(logger *zap.Logger) GRPCMiddleware {
+	recoverPanic := func(method string, err *error) {
+		if r := recover(); r != nil {
+			logger.Error("[GrpcMiddleware] gRPC handler panic",
+				zap.String("method", method),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+			*err = errcode.ErrInternal
+		}
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+			defer recoverPanic(info.FullMethod, &err)
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+			defer recoverPanic(info.FullMethod, &err)
+			return handler(srv, ss)
+		},
+	}
+}
+
+// GRPCRequestIDMiddleware accepts or generates the request id, it is stored in the context and returned in the header
+func GRPCRequestIDMiddleware() GRPCMiddleware {
+	requestID := func(ctx context.Context) (context.Context, metadata.MD) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		id := firstMetadata(md, RequestIDMetadataKey)
+		if !isValidRequestID(id) {
+			id = NewRequestID()
+		}
+		return reqinfo.WithRequestID(ctx, id), metadata.Pairs(RequestIDMetadataKey, id)
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, header := requestID(ctx)
+			_ = grpc.SetHeader(ctx, header)
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, header := requestID(ss.Context())
+			_ = ss.SetHeader(header)
+			return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+		},
+	}
+}
+
+// GRPCLoggingMiddleware writes the access log of the gRPC requests, the fields and the sampling come from the access_log config
+func GRPCLoggingMiddleware(logger *zap.Logger, dc *config.DynamicConfig) GRPCMiddleware {
+	logRequest := func(ctx context.Context, msg, method string, start time.Time, resp interface{}, err error) {
+		cfg := dc.Get()
+		accessLog := cfg.GetAccessLog()
+		if !shouldLogAccess(accessLog, method, err != nil) {
+			return
+		}
+		var client string
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			client = p.Addr.String()
+		}
+		var size int64
+		if m, ok := resp.(proto.Message); ok {
+			size = int64(proto.Size(m))
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		logger.Info(msg, accessLogFields(accessLog, accessLogEntry{
+			method:    method,
+			status:    zap.String("code", status.Code(err).String()),
+			client:    client,
+			requestID: reqinfo.RequestID(ctx),
+			keyID:     APIKeyIDFromContext(ctx),
+			captcha:   reqinfo.CaptchaFromContext(ctx),
+			bytes:     size,
+			duration:  time.Since(start),
+			userAgent: firstMetadata(md, "user-agent"),
+			err:       err,
+		})...)
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			start := time.Now()
+			ctx = reqinfo.WithCaptchaHolder(withAPIKeyIDHolder(ctx))
+			resp, err := handler(ctx, req)
+			logRequest(ctx, "[GrpcMiddleware] gRPC request", info.FullMethod, start, resp, err)
+			return resp, err
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+			ctx := reqinfo.WithCaptchaHolder(withAPIKeyIDHolder(ss.Context()))
+			err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+			logRequest(ctx, "[GrpcMiddleware] gRPC stream request", info.FullMethod, start, nil, err)
+			return err
+		},
+	}
+}
+
+// GRPCRateLimitMiddleware enforces the global and per-client rate limits
+func GRPCRateLimitMiddleware(limiter *DynamicLimiter, keyed *KeyedRateLimiter, logger *zap.Logger) GRPCMiddleware {
+	return GRPCMiddleware{
+		Unary:  RateLimitUnaryInterceptor(limiter, keyed, logger),
+		Stream: RateLimitStreamInterceptor(limiter, keyed, logger),
+	}
+}
 
-		// Validate API key
+// GRPCOriginMiddleware rejects browser calls whose origin is not allowed, the equivalent of the CORS middleware
+func GRPCOriginMiddleware(dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	checkOrigin := func(ctx context.Context, method string) error {
 		cfg := dc.Get()
+		md, _ := metadata.FromIncomingContext(ctx)
+		origin := firstMetadata(md, "origin")
+		if !cfg.EnableCors || origin == "" {
+			return nil
+		}
+		if isOriginAllowed(origin, cfg.GetCORSRule(firstMetadata(md, "x-site-key")).AllowedOrigins) {
+			return nil
+		}
+		logger.Warn("[GrpcMiddleware] Origin not allowed", zap.String("method", method), zap.String("origin", origin))
+		return errcode.ErrForbidden.WithMessage("origin not allowed")
+	}
 
-		methodName := info.FullMethod
-		authApisMap := cfg.GetAuthAPIs()
-		if _, exists := authApisMap[methodName]; exists {
-			apiKeyMap := cfg.GetAPIKeys()
-			if len(apiKeyMap) == 0 {
-				logger.Warn("[GrpcMiddleware] Missing metadata")
-				return nil, status.Error(codes.Unauthenticated, "missing API Key")
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkOrigin(ctx, info.FullMethod); err != nil {
+				return nil, err
 			}
-
-			md, ok := metadata.FromIncomingContext(ctx)
-			if !ok {
-				logger.Warn("[GrpcMiddleware] Missing metadata")
-				return nil, status.Error(codes.Unauthenticated, "missing API Key")
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkOrigin(ss.Context(), info.FullMethod); err != nil {
+				return err
 			}
+			return handler(srv, ss)
+		},
+	}
+}
 
-			apiKeys := md.Get("x-api-key")
-			if _, exists := apiKeyMap[apiKeys[0]]; !exists {
-				logger.Warn("[GrpcMiddleware] Invalid API Key", zap.String("key", apiKeys[0]))
-				return nil, status.Error(codes.Unauthenticated, "invalid API Key")
+// GRPCAPIKeyMiddleware validates API keys
+func GRPCAPIKeyMiddleware(dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := validateGRPCAPIKey(ctx, dc, info.FullMethod, logger)
+			if err != nil {
+				return nil, err
 			}
-		}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := validateGRPCAPIKey(ss.Context(), dc, info.FullMethod, logger)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+		},
+	}
+}
 
-		// Apply circuit breaker
-		var resp interface{}
+// GRPCCircuitBreakerMiddleware implements circuit breaking
+func GRPCCircuitBreakerMiddleware(breaker *gobreaker.CircuitBreaker, logger *zap.Logger) GRPCMiddleware {
+	execute := func(call func() error) error {
 		var err error
 		_, cbErr := breaker.Execute(func() (interface{}, error) {
-			resp, err = handler(ctx, req)
+			err = call()
 			return nil, nil
 		})
 		if cbErr == gobreaker.ErrOpenState || cbErr == gobreaker.ErrTooManyRequests {
 			logger.Warn("[GrpcMiddleware] gRPC circuit breaker tripped", zap.Error(cbErr))
-			return nil, status.Error(codes.Unavailable, "service unavailable")
+			return errcode.ErrServiceUnavailable
 		}
 		if cbErr != nil {
 			logger.Error("[GrpcMiddleware] gRPC circuit breaker error", zap.Error(cbErr))
-			return nil, status.Error(codes.Internal, "internal server error")
+			return errcode.ErrInternal.Wrap(cbErr)
 		}
+		return err
+	}
 
-		// Log request
-		logger.Info("[GrpcMiddleware] gRPC request",
-			zap.String("method", info.FullMethod),
-			zap.Duration("duration", time.Since(start)),
-			zap.Error(err),
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			var resp interface{}
+			err := execute(func() (err error) {
+				resp, err = handler(ctx, req)
+				return err
+			})
+			return resp, err
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return execute(func() error {
+				return handler(srv, ss)
+			})
+		},
+	}
+}
+
+// RateLimitUnaryInterceptor enforces the global and per-client rate limits of unary calls
+func RateLimitUnaryInterceptor(limiter *DynamicLimiter, keyed *KeyedRateLimiter, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkGRPCRateLimit(ctx, limiter, keyed, info.FullMethod, logger); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor enforces the global and per-client rate limits of stream calls
+func RateLimitStreamInterceptor(limiter *DynamicLimiter, keyed *KeyedRateLimiter, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkGRPCRateLimit(ss.Context(), limiter, keyed, info.FullMethod, logger); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkGRPCRateLimit rejects the call immediately when a limit is exceeded
+func checkGRPCRateLimit(ctx context.Context, limiter *DynamicLimiter, keyed *KeyedRateLimiter, methodName string, logger *zap.Logger) error {
+	scope := GRPCRateLimitScope(methodName)
+	if scope == "" {
+		return nil
+	}
+
+	if limiter != nil {
+		if retryAfter, ok := limiter.Allow(ctx); !ok {
+			logger.Warn("[GrpcMiddleware] Rate limit exceeded", zap.String("method", methodName))
+			return errcode.ErrRateLimited.WithRetryAfter(retryAfter)
+		}
+	}
+	if keyed == nil {
+		return nil
+	}
+
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := ClientIdentity{
+		IP:       keyed.ClientIP(remoteAddr, firstMetadata(md, "x-forwarded-for"), firstMetadata(md, "x-real-ip")),
+		APIKeyID: keyed.APIKeyID(ctx, firstMetadata(md, "x-api-key")),
+		SiteKey:  firstMetadata(md, "x-site-key"),
+	}
+
+	if retryAfter, ok := keyed.Allow(ctx, scope, id); !ok {
+		logger.Warn("[GrpcMiddleware] Rate limit exceeded",
+			zap.String("method", methodName),
+			zap.String("scope", scope),
+			zap.String("client", id.IP),
+			zap.Duration("retry_after", retryAfter),
 		)
+		return errcode.ErrRateLimited.WithRetryAfter(retryAfter)
+	}
+	return nil
+}
+
+// firstMetadata returns the first value of the metadata key
+func firstMetadata(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// validateGRPCAPIKey checks the API key and its scope for the methods that require authentication
+func validateGRPCAPIKey(ctx context.Context, dc *config.DynamicConfig, methodName string, logger *zap.Logger) (context.Context, error) {
+	cfg := dc.Get()
 
-		return resp, err
+	if !cfg.IsAuthAPI(methodName) {
+		return ctx, nil
 	}
+	// Authenticated by a signed request
+	if APIKeyIDFromContext(ctx) != "" {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	key, err := authenticateAPIKey(&cfg, firstMetadata(md, "x-api-key"), methodName)
+	if err != nil {
+		logger.Warn("[GrpcMiddleware] API Key rejected",
+			zap.String("method", methodName),
+			zap.String("key_id", key.ID),
+			zap.Error(err),
+		)
+		return ctx, err
+	}
+
+	return WithAPIKeyID(ctx, key.ID), nil
 }
@@ -9,21 +9,33 @@ package middleware
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 )
 
 // ErrorResponse defines the standard error response format
 type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	Reason  string `json:"reason,omitempty"`
 }
 
 // HandlerFunc .
@@ -60,49 +72,45 @@ func (c *MwChain) Then(final HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(final)
 }
 
-// APIKeyMiddleware validates API keys
+// APIKeyMiddleware validates API keys and their scopes
 func APIKeyMiddleware(dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			cfg := dc.Get()
 
 			// Auth API
-			authApisMap := cfg.GetAuthAPIs()
-			if _, exists := authApisMap[r.URL.Path]; !exists {
+			if !cfg.IsAuthAPI(r.URL.Path) {
 				next(w, r)
 				return
 			}
-
-			apiKeyMap := cfg.GetAPIKeys()
-			if len(apiKeyMap) == 0 {
-				logger.Warn("[HttpMiddleware] Missing API Key")
-				WriteError(w, http.StatusUnauthorized, "missing API Key")
+			// Authenticated by a signed request
+			if APIKeyIDFromContext(r.Context()) != "" {
+				next(w, r)
 				return
 			}
 
-			apiKey := r.Header.Get("X-API-Key")
-			if apiKey == "" {
-				logger.Warn("[HttpMiddleware] Missing API Key")
-				WriteError(w, http.StatusUnauthorized, "missing API Key")
-				return
-			}
-			if _, exists := apiKeyMap[apiKey]; !exists {
-				logger.Warn("[HttpMiddleware] Invalid API Key", zap.String("key", apiKey))
-				WriteError(w, http.StatusUnauthorized, "invalid API Key")
+			key, err := authenticateAPIKey(&cfg, r.Header.Get("X-API-Key"), r.URL.Path)
+			if err != nil {
+				logger.Warn("[HttpMiddleware] API Key rejected",
+					zap.String("path", r.URL.Path),
+					zap.String("key_id", key.ID),
+					zap.Error(err),
+				)
+				WriteAppError(w, err)
 				return
 			}
-			next(w, r)
+			next(w, r.WithContext(WithAPIKeyID(r.Context(), key.ID)))
 		}
 	}
 }
 
-// RateLimitMiddleware enforces rate limiting
+// RateLimitMiddleware enforces the global rate limit, over-limit requests are rejected immediately
 func RateLimitMiddleware(limiter *DynamicLimiter, logger *zap.Logger) HTTPMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			if err := limiter.Wait(r.Context()); err != nil {
+			if retryAfter, ok := limiter.Allow(r.Context()); !ok {
 				logger.Warn("[HttpMiddleware] Rate limit exceeded", zap.String("client", r.RemoteAddr))
-				WriteError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				WriteAppError(w, errcode.ErrRateLimited.WithRetryAfter(retryAfter))
 				return
 			}
 			next(w, r)
@@ -110,88 +118,141 @@ func RateLimitMiddleware(limiter *DynamicLimiter, logger *zap.Logger) HTTPMiddle
 	}
 }
 
-// LoggingMiddleware logs HTTP requests
-func LoggingMiddleware(logger *zap.Logger) HTTPMiddleware {
+// KeyedRateLimitMiddleware enforces the per-client rate limits of the route scope
+func KeyedRateLimitMiddleware(limiter *KeyedRateLimiter, logger *zap.Logger) HTTPMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
+			scope := HTTPRateLimitScope(r.URL.Path)
+			if scope == "" {
+				next(w, r)
+				return
+			}
+
+			id := ClientIdentity{
+				IP:       limiter.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP")),
+				APIKeyID: limiter.APIKeyID(r.Context(), r.Header.Get("X-API-Key")),
+				SiteKey:  r.Header.Get("X-Site-Key"),
+			}
+			if id.SiteKey == "" {
+				id.SiteKey = r.URL.Query().Get("site_key")
+			}
+
+			if retryAfter, ok := limiter.Allow(r.Context(), scope, id); !ok {
+				logger.Warn("[HttpMiddleware] Rate limit exceeded",
+					zap.String("scope", scope),
+					zap.String("client", id.IP),
+					zap.Duration("retry_after", retryAfter),
+				)
+				WriteAppError(w, errcode.ErrRateLimited.WithRetryAfter(retryAfter))
+				return
+			}
 			next(w, r)
-			logger.Info("[HttpMiddleware] HTTP request",
-				zap.String("method", r.Method),
-				zap.String("path", r.URL.Path),
-				zap.String("client", r.RemoteAddr),
-				zap.Duration("duration", time.Since(start)),
-			)
 		}
 	}
 }
 
-// CircuitBreakerMiddleware implements circuit breaking
-func CircuitBreakerMiddleware(breaker *gobreaker.CircuitBreaker, logger *zap.Logger) HTTPMiddleware {
+// LoggingMiddleware writes the access log of the HTTP requests, the fields and the sampling come from the access_log config
+func LoggingMiddleware(logger *zap.Logger, dc *config.DynamicConfig) HTTPMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			_, err := breaker.Execute(func() (interface{}, error) {
-				next(w, r)
-				return nil, nil
-			})
-			if err == gobreaker.ErrOpenState || err == gobreaker.ErrTooManyRequests {
-				logger.Warn("[HttpMiddleware] Circuit breaker tripped", zap.Error(err))
-				WriteError(w, http.StatusServiceUnavailable, "service unavailable")
-				return
-			}
-			if err != nil {
-				logger.Error("[HttpMiddleware] Circuit breaker error", zap.Error(err))
-				WriteError(w, http.StatusInternalServerError, "internal server error")
+			start := time.Now()
+			r = r.WithContext(reqinfo.WithCaptchaHolder(withAPIKeyIDHolder(r.Context())))
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next(sw, r)
+
+			cfg := dc.Get()
+			accessLog := cfg.GetAccessLog()
+			if !shouldLogAccess(accessLog, r.URL.Path, sw.status >= http.StatusBadRequest) {
 				return
 			}
+			logger.Info("[HttpMiddleware] HTTP request", accessLogFields(accessLog, accessLogEntry{
+				method:    r.Method,
+				path:      r.URL.Path,
+				status:    zap.Int("status", sw.status),
+				client:    r.RemoteAddr,
+				requestID: reqinfo.RequestID(r.Context()),
+				keyID:     APIKeyIDFromContext(r.Context()),
+				captcha:   reqinfo.CaptchaFromContext(r.Context()),
+				bytes:     sw.bytes,
+				duration:  time.Since(start),
+				userAgent: r.UserAgent(),
+			})...)
 		}
 	}
 }
 
-// CORSMiddleware implements cross-origin resource sharing
-func CORSMiddleware(dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+// TracingMiddleware starts a server span for each HTTP request and propagates the W3C trace context
+func TracingMiddleware() HTTPMiddleware {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			if !dc.Get().EnableCors {
-				next(w, r)
-				return
-			}
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := otel.Tracer(tracing.InstrumentationName).Start(ctx, "HTTP "+r.Method+" "+r.URL.Path,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+					attribute.String("request.id", reqinfo.RequestID(r.Context())),
+				),
+			)
+			defer span.End()
 
-			origin := r.Header.Get("Origin")
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
 
-			allowedOrigins := []string{"*"}
-			allowOrigin := "*"
-			for _, allowed := range allowedOrigins {
-				if allowed == "*" || allowed == origin {
-					allowOrigin = origin
-					break
-				}
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next(sw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sw.status))
 			}
+		}
+	}
+}
 
-			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+// statusWriter records the status code and the body size written by the handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
 
-			if requestedHeaders := r.Header.Get("Access-Control-Request-Headers"); requestedHeaders != "" {
-				w.Header().Set("Access-Control-Allow-Headers", requestedHeaders)
-			} else {
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Custom-Header")
-			}
+// WriteHeader .
+func (sw *statusWriter) WriteHeader(code int) {
+	sw.status = code
+	sw.ResponseWriter.WriteHeader(code)
+}
 
-			w.Header().Set("Access-Control-Max-Age", "86400")          // CacheMgr preflight response for 24 hours
-			w.Header().Set("Access-Control-Allow-Credentials", "true") // Allow credentials if needed
+// Write .
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the flusher and the deadlines of the connection
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
 
-			// Handle preflight (OPTIONS) requests
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusNoContent)
+// CircuitBreakerMiddleware implements circuit breaking
+func CircuitBreakerMiddleware(breaker *gobreaker.CircuitBreaker, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, err := breaker.Execute(func() (interface{}, error) {
+				next(w, r)
+				return nil, nil
+			})
+			if err == gobreaker.ErrOpenState || err == gobreaker.ErrTooManyRequests {
+				logger.Warn("[HttpMiddleware] Circuit breaker tripped", zap.Error(err))
+				WriteAppError(w, errcode.ErrServiceUnavailable)
 				return
 			}
-
-			// Processing precheck requests
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
+			if err != nil {
+				logger.Error("[HttpMiddleware] Circuit breaker error", zap.Error(err))
+				WriteAppError(w, errcode.ErrInternal.Wrap(err))
 				return
 			}
-			next(w, r)
 		}
 	}
 }
@@ -199,6 +260,7 @@ func CORSMiddleware(dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware
 // DynamicLimiter manages dynamic rate limiting
 type DynamicLimiter struct {
 	limiter *rate.Limiter
+	dist    *DistributedLimiter
 	mu      sync.RWMutex
 }
 
@@ -216,6 +278,25 @@ func (d *DynamicLimiter) Wait(ctx context.Context) error {
 	return d.limiter.Wait(ctx)
 }
 
+// Allow takes a token without blocking, the retry delay is returned when no token is available
+func (d *DynamicLimiter) Allow(ctx context.Context) (time.Duration, bool) {
+	d.mu.RLock()
+	dist, qps, burst := d.dist, float64(d.limiter.Limit()), d.limiter.Burst()
+	d.mu.RUnlock()
+
+	if retryAfter, ok, handled := dist.Allow(ctx, []string{globalRateLimitKey}, qps, burst); handled {
+		return retryAfter, ok
+	}
+	return reserve(d.limiter, time.Now())
+}
+
+// SetDistributed shares the quota with the other nodes when the distributed mode is on
+func (d *DynamicLimiter) SetDistributed(dist *DistributedLimiter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dist = dist
+}
+
 // Update updates rate limit parameters
 func (d *DynamicLimiter) Update(qps, burst int) {
 	if qps <= 0 || burst <= 0 {
@@ -231,7 +312,7 @@ func (d *DynamicLimiter) Update(qps, burst int) {
 func RateLimitHandler(limiter *DynamicLimiter, logger *zap.Logger) HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+			WriteAppError(w, errcode.ErrMethodNotAllowed)
 			return
 		}
 		var params struct {
@@ -240,14 +321,20 @@ func RateLimitHandler(limiter *DynamicLimiter, logger *zap.Logger) HandlerFunc {
 		}
 		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
 			logger.Warn("[HttpMiddleware] Invalid rate limit params", zap.Error(err))
-			WriteError(w, http.StatusBadRequest, "invalid parameters")
+			WriteAppError(w, BodyReadError(err, "invalid parameters"))
 			return
 		}
 		if params.QPS <= 0 || params.Burst <= 0 {
-			WriteError(w, http.StatusBadRequest, "qps and burst must be positive")
+			WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("qps and burst must be positive"))
 			return
 		}
+		limiter.mu.RLock()
+		before := map[string]int{"qps": int(limiter.limiter.Limit()), "burst": limiter.limiter.Burst()}
+		limiter.mu.RUnlock()
+
 		limiter.Update(params.QPS, params.Burst)
+		audit.SetTarget(r.Context(), "global")
+		audit.SetChange(r.Context(), audit.Diff(before, map[string]int{"qps": params.QPS, "burst": params.Burst}))
 		logger.Info("[HttpMiddleware] Rate limit updated",
 			zap.Int("qps", params.QPS),
 			zap.Int("burst", params.Burst),
@@ -266,3 +353,18 @@ func WriteError(w http.ResponseWriter, code int, message string) {
 		Message: message,
 	})
 }
+
+// WriteAppError sends the error response of a catalogue error, unknown errors are reported as internal errors
+func WriteAppError(w http.ResponseWriter, err error) {
+	e := errcode.FromError(err)
+	if d := e.RetryAfter(); d > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.HTTPStatus)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Code:    e.HTTPStatus,
+		Message: e.Message,
+		Reason:  string(e.Reason),
+	})
+}
@@ -0,0 +1,251 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// ipFilterRule .
+type ipFilterRule struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// newIPFilterRule .
+func newIPFilterRule(rule config.IPFilterRule) ipFilterRule {
+	return ipFilterRule{allow: ParseIPNets(rule.Allow), deny: ParseIPNets(rule.Deny)}
+}
+
+// permits reports whether the address is not denied and, when an allow list is set, allowed
+func (r ipFilterRule) permits(ip string) bool {
+	if containsIP(ip, r.deny) {
+		return false
+	}
+	return len(r.allow) == 0 || containsIP(ip, r.allow)
+}
+
+// IPFilter applies the global, route group and API key allow and deny lists
+type IPFilter struct {
+	mu      sync.RWMutex
+	global  ipFilterRule
+	groups  map[string]ipFilterRule
+	apiKeys map[string]ipFilterRule
+	trusted []*net.IPNet
+}
+
+// NewIPFilter creates a new ip filter
+func NewIPFilter(cfg config.Config) *IPFilter {
+	f := &IPFilter{}
+	f.Update(cfg)
+	return f
+}
+
+// Update updates the lists
+func (f *IPFilter) Update(cfg config.Config) {
+	groups := make(map[string]ipFilterRule, len(cfg.IPFilterGroups))
+	for group, rule := range cfg.IPFilterGroups {
+		groups[group] = newIPFilterRule(rule)
+	}
+	apiKeys := make(map[string]ipFilterRule, len(cfg.IPFilterAPIKeys))
+	for key, rule := range cfg.IPFilterAPIKeys {
+		apiKeys[key] = newIPFilterRule(rule)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.global = newIPFilterRule(cfg.IPFilter)
+	f.groups = groups
+	f.apiKeys = apiKeys
+	f.trusted = ParseIPNets(cfg.GetTrustedProxies())
+}
+
+// ClientIP returns the client address using the trusted proxies of the filter
+func (f *IPFilter) ClientIP(remoteAddr, forwardedFor, realIP string) string {
+	f.mu.RLock()
+	trusted := f.trusted
+	f.mu.RUnlock()
+	return ClientIP(remoteAddr, forwardedFor, realIP, trusted)
+}
+
+// Check returns a forbidden error when the address is rejected by the global or route group lists
+func (f *IPFilter) Check(ip, group string) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if !f.global.permits(ip) {
+		return errcode.ErrForbidden.WithMessage("ip address not allowed")
+	}
+	if rule, ok := f.groups[group]; ok && !rule.permits(ip) {
+		return errcode.ErrForbidden.WithMessage("ip address not allowed")
+	}
+	return nil
+}
+
+// CheckAPIKey returns a forbidden error when the address is rejected by the lists of the API key id
+func (f *IPFilter) CheckAPIKey(ip, keyID string) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if rule, ok := f.apiKeys[keyID]; ok && keyID != "" && !rule.permits(ip) {
+		return errcode.ErrForbidden.WithMessage("ip address not allowed for the API key")
+	}
+	return nil
+}
+
+// grpcClientIP returns the client address of a gRPC call
+func (f *IPFilter) grpcClientIP(ctx context.Context) string {
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return f.ClientIP(remoteAddr, firstMetadata(md, "x-forwarded-for"), firstMetadata(md, "x-real-ip"))
+}
+
+// ipFilterGroup returns the route group of a rate limit scope, empty for unfiltered routes
+func ipFilterGroup(scope string) string {
+	switch scope {
+	case "":
+		return ""
+	case config.RateLimitScopeManage:
+		return config.IPFilterGroupManage
+	}
+	return config.IPFilterGroupPublic
+}
+
+// IPFilterMiddleware rejects clients outside the allow lists or inside the deny lists
+func IPFilterMiddleware(filter *IPFilter, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			group := ipFilterGroup(HTTPRateLimitScope(r.URL.Path))
+			if group == "" {
+				next(w, r)
+				return
+			}
+
+			ip := filter.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
+			if err := filter.Check(ip, group); err != nil {
+				logger.Warn("[HttpMiddleware] IP address not allowed",
+					zap.String("path", r.URL.Path),
+					zap.String("client", ip),
+				)
+				WriteAppError(w, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// GRPCIPFilterMiddleware rejects clients outside the allow lists or inside the deny lists
+func GRPCIPFilterMiddleware(filter *IPFilter, logger *zap.Logger) GRPCMiddleware {
+	check := func(ctx context.Context, method string) error {
+		group := ipFilterGroup(GRPCRateLimitScope(method))
+		if group == "" {
+			return nil
+		}
+
+		ip := filter.grpcClientIP(ctx)
+		if err := filter.Check(ip, group); err != nil {
+			logger.Warn("[GrpcMiddleware] IP address not allowed",
+				zap.String("method", method),
+				zap.String("client", ip),
+			)
+			return err
+		}
+		return nil
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	}
+}
+
+// APIKeyIPFilterMiddleware rejects clients outside the lists of their authenticated API key,
+// it runs after the authentication so that signed and JWT requests are covered too
+func APIKeyIPFilterMiddleware(filter *IPFilter, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			keyID := APIKeyIDFromContext(r.Context())
+			if keyID == "" {
+				next(w, r)
+				return
+			}
+
+			ip := filter.ClientIP(r.RemoteAddr, r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
+			if err := filter.CheckAPIKey(ip, keyID); err != nil {
+				logger.Warn("[HttpMiddleware] IP address not allowed for the API key",
+					zap.String("path", r.URL.Path),
+					zap.String("client", ip),
+					zap.String("key_id", keyID),
+				)
+				WriteAppError(w, err)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// GRPCAPIKeyIPFilterMiddleware rejects clients outside the lists of their authenticated API key
+func GRPCAPIKeyIPFilterMiddleware(filter *IPFilter, logger *zap.Logger) GRPCMiddleware {
+	check := func(ctx context.Context, method string) error {
+		keyID := APIKeyIDFromContext(ctx)
+		if keyID == "" {
+			return nil
+		}
+
+		ip := filter.grpcClientIP(ctx)
+		if err := filter.CheckAPIKey(ip, keyID); err != nil {
+			logger.Warn("[GrpcMiddleware] IP address not allowed for the API key",
+				zap.String("method", method),
+				zap.String("client", ip),
+				zap.String("key_id", keyID),
+			)
+			return err
+		}
+		return nil
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	}
+}
@@ -0,0 +1,464 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+const (
+	// defaultJWKSRefreshInterval is how long the signing keys are cached
+	defaultJWKSRefreshInterval = time.Hour
+	// jwksMinRefreshInterval limits the reloads triggered by unknown key ids
+	jwksMinRefreshInterval = 10 * time.Second
+	// jwksFetchTimeout .
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMaxSize .
+	jwksMaxSize = 1 << 20
+)
+
+// jwtValidMethods are the accepted signing algorithms, symmetric algorithms are never accepted
+var jwtValidMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// defaultScopeClaims .
+var defaultScopeClaims = []string{"scope", "scp", "roles"}
+
+// JWTVerifier verifies the bearer tokens issued by the identity provider.
+// The signing keys are loaded from a JWKS file or url and cached.
+type JWTVerifier struct {
+	logger *zap.Logger
+	client *http.Client
+
+	mu          sync.RWMutex
+	auth        config.JWTAuth
+	keys        map[string]crypto.PublicKey
+	loadedAt    time.Time
+	lastAttempt time.Time
+
+	// refreshMu serializes the reloads of the keys
+	refreshMu sync.Mutex
+}
+
+// NewJWTVerifier creates a new bearer token verifier
+func NewJWTVerifier(cfg config.Config, logger *zap.Logger) *JWTVerifier {
+	v := &JWTVerifier{
+		logger: logger,
+		client: &http.Client{Timeout: jwksFetchTimeout},
+	}
+	v.Update(cfg)
+	return v
+}
+
+// Update updates the settings, the cached keys are dropped when the key source changed
+func (v *JWTVerifier) Update(cfg config.Config) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.auth.JWKSFile != cfg.JWTAuth.JWKSFile || v.auth.JWKSURL != cfg.JWTAuth.JWKSURL {
+		v.keys = nil
+		v.loadedAt = time.Time{}
+		v.lastAttempt = time.Time{}
+	}
+	v.auth = cfg.JWTAuth
+}
+
+// Enabled .
+func (v *JWTVerifier) Enabled() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.auth.Enable
+}
+
+// Verify checks the token and returns the API key of its subject, the scopes are mapped from the claims
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (config.APIKey, error) {
+	v.mu.RLock()
+	auth := v.auth
+	v.mu.RUnlock()
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(jwtValidMethods),
+		jwt.WithIssuer(auth.Issuer),
+		jwt.WithLeeway(time.Duration(auth.Leeway)*time.Second),
+		jwt.WithExpirationRequired(),
+	)
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.publicKey(ctx, kid)
+	})
+	if err != nil {
+		return config.APIKey{}, fmt.Errorf("invalid bearer token: %v", err)
+	}
+
+	aud, _ := claims.GetAudience()
+	if !containsAny(aud, auth.Audience) {
+		return config.APIKey{}, fmt.Errorf("invalid bearer token: audience not allowed")
+	}
+
+	sub, _ := claims.GetSubject()
+	return config.APIKey{ID: "jwt:" + sub, Scopes: claimScopes(claims, auth)}, nil
+}
+
+// publicKey returns the signing key of the id, the keys are reloaded when they are stale or the id is unknown
+func (v *JWTVerifier) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if key, ok := v.cachedKey(kid, false); ok {
+		return key, nil
+	}
+
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+	// Reloaded by another request meanwhile
+	if key, ok := v.cachedKey(kid, false); ok {
+		return key, nil
+	}
+
+	v.mu.RLock()
+	auth, lastAttempt := v.auth, v.lastAttempt
+	v.mu.RUnlock()
+	if time.Since(lastAttempt) < jwksMinRefreshInterval {
+		if key, ok := v.cachedKey(kid, true); ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	keys, err := v.loadKeys(ctx, auth)
+	v.mu.Lock()
+	v.lastAttempt = time.Now()
+	if err == nil {
+		v.keys = keys
+		v.loadedAt = v.lastAttempt
+	}
+	v.mu.Unlock()
+	if err != nil {
+		v.logger.Warn("[JWT] Failed to load the JWKS, using the cached keys", zap.Error(err))
+	}
+
+	if key, ok := v.cachedKey(kid, true); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+// cachedKey returns the cached key of the id, stale keys are only returned when allowed
+func (v *JWTVerifier) cachedKey(kid string, allowStale bool) (crypto.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	refresh := time.Duration(v.auth.JWKSRefreshInterval) * time.Second
+	if refresh <= 0 {
+		refresh = defaultJWKSRefreshInterval
+	}
+	if v.keys == nil || (!allowStale && time.Since(v.loadedAt) > refresh) {
+		return nil, false
+	}
+
+	if key, ok := v.keys[kid]; ok {
+		return key, true
+	}
+	// A token without key id is accepted when the set has a single key
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// loadKeys reads the JWKS from the file or the url
+func (v *JWTVerifier) loadKeys(ctx context.Context, auth config.JWTAuth) (map[string]crypto.PublicKey, error) {
+	var data []byte
+	if auth.JWKSFile != "" {
+		var err error
+		if data, err = os.ReadFile(auth.JWKSFile); err != nil {
+			return nil, err
+		}
+		return parseJWKS(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, auth.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS status: %d", resp.StatusCode)
+	}
+	if data, err = io.ReadAll(io.LimitReader(resp.Body, jwksMaxSize)); err != nil {
+		return nil, err
+	}
+	return parseJWKS(data)
+}
+
+// jsonWebKey .
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the RSA, EC and Ed25519 signing keys of a JWKS, other keys are skipped
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %s: %v", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS has no signing keys")
+	}
+	return keys, nil
+}
+
+// parseJWK returns nil for unsupported key types
+func parseJWK(k jsonWebKey) (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid point size")
+		}
+		// Rejects the points that are not on the curve
+		if _, err = ecdhCurve.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+// decodeBigInt .
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// claimScopes maps the values of the scope claims to API key scopes, known scope names are used as is
+func claimScopes(claims jwt.MapClaims, auth config.JWTAuth) []string {
+	names := auth.ScopeClaims
+	if len(names) == 0 {
+		names = defaultScopeClaims
+	}
+
+	var scopes []string
+	for _, name := range names {
+		var values []string
+		switch val := claims[name].(type) {
+		case string:
+			values = strings.Fields(val)
+		case []interface{}:
+			for _, item := range val {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+
+		for _, value := range values {
+			if mapped, ok := auth.ClaimScopes[value]; ok {
+				scopes = append(scopes, mapped...)
+			} else if config.IsValidScope(value) {
+				scopes = append(scopes, value)
+			}
+		}
+	}
+	return scopes
+}
+
+// containsAny .
+func containsAny(values, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of a bearer authorization header
+func bearerToken(authorization string) string {
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	return ""
+}
+
+// JWTAuthMiddleware authenticates the bearer tokens of the management APIs, other requests are left to the API key middleware
+func JWTAuthMiddleware(verifier *JWTVerifier, dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r.Header.Get("Authorization"))
+			if token == "" || !verifier.Enabled() || HTTPRateLimitScope(r.URL.Path) != config.RateLimitScopeManage {
+				next(w, r)
+				return
+			}
+			cfg := dc.Get()
+			if !cfg.IsAuthAPI(r.URL.Path) {
+				next(w, r)
+				return
+			}
+
+			key, err := verifyBearerToken(r.Context(), verifier, token, r.URL.Path)
+			if err != nil {
+				logger.Warn("[HttpMiddleware] Bearer token rejected",
+					zap.String("path", r.URL.Path),
+					zap.String("key_id", key.ID),
+					zap.Error(err),
+				)
+				WriteAppError(w, err)
+				return
+			}
+			next(w, r.WithContext(WithAPIKeyID(r.Context(), key.ID)))
+		}
+	}
+}
+
+// GRPCJWTAuthMiddleware authenticates the bearer tokens of the management methods
+func GRPCJWTAuthMiddleware(verifier *JWTVerifier, dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	verify := func(ctx context.Context, method string) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		token := bearerToken(firstMetadata(md, "authorization"))
+		if token == "" || !verifier.Enabled() || GRPCRateLimitScope(method) != config.RateLimitScopeManage {
+			return ctx, nil
+		}
+		cfg := dc.Get()
+		if !cfg.IsAuthAPI(method) {
+			return ctx, nil
+		}
+
+		key, err := verifyBearerToken(ctx, verifier, token, method)
+		if err != nil {
+			logger.Warn("[GrpcMiddleware] Bearer token rejected",
+				zap.String("method", method),
+				zap.String("key_id", key.ID),
+				zap.Error(err),
+			)
+			return ctx, err
+		}
+		return WithAPIKeyID(ctx, key.ID), nil
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := verify(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := verify(ss.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+		},
+	}
+}
+
+// verifyBearerToken verifies the token and the scope of its claims for the route
+func verifyBearerToken(ctx context.Context, verifier *JWTVerifier, token, route string) (config.APIKey, error) {
+	key, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return key, errcode.ErrUnauthenticated.WithMessage("invalid bearer token").Wrap(err)
+	}
+	return key, authorizeAPIKey(key, route)
+}
@@ -2,22 +2,47 @@ package middleware
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/sony/gobreaker"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/mem"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	protoutil "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
+	"github.com/wenlng/go-captcha-service/internal/audit"
+	"github.com/wenlng/go-captcha-service/internal/cache"
 	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
 )
 
 func TestAPIKeyMiddleware(t *testing.T) {
@@ -92,16 +117,69 @@ func TestRateLimitMiddleware(t *testing.T) {
 }
 
 func TestLoggingMiddleware(t *testing.T) {
-	logger, _ := zap.NewDevelopment()
-	mw := LoggingMiddleware(logger)
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+	core, logs := observer.New(zap.InfoLevel)
+	cfg := config.DefaultConfig()
+	dc := &config.DynamicConfig{Config: cfg}
+	chain := NewChainHTTP(RequestIDMiddleware(), LoggingMiddleware(zap.New(core), dc))
+	handler := chain.Then(func(w http.ResponseWriter, r *http.Request) {
+		reqinfo.SetCaptcha(r.Context(), reqinfo.Captcha{ID: "click-default-ch", Type: "click", Key: "key-1"})
+		if r.URL.Path == "/fail" {
+			WriteAppError(w, errcode.ErrCaptchaNotFound)
+			return
+		}
+		w.Write([]byte("hello"))
+	})
+
+	request := func(path, requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
 	}
 
-	req := httptest.NewRequest("GET", "/test", nil)
-	rr := httptest.NewRecorder()
-	mw(handler)(rr, req)
-	assert.Equal(t, http.StatusOK, rr.Code)
+	rr := request("/api/v1/public/get-data", "req-1")
+	assert.Equal(t, "req-1", rr.Header().Get(RequestIDHeader))
+	entry := logs.TakeAll()[0].ContextMap()
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, int64(http.StatusOK), entry["status"])
+	assert.Equal(t, int64(5), entry["bytes"])
+	assert.Equal(t, "click", entry["captcha_type"])
+	assert.Equal(t, "key-1", entry["captcha_key"])
+	assert.NotContains(t, entry, "user_agent")
+
+	// Invalid client ids are replaced
+	rr = request("/api/v1/public/get-data", "bad id")
+	assert.Len(t, rr.Header().Get(RequestIDHeader), 32)
+	logs.TakeAll()
+
+	t.Run("Fields", func(t *testing.T) {
+		cfg.AccessLog = config.AccessLog{Fields: []string{config.AccessLogFieldPath, config.AccessLogFieldStatus}}
+		dc.Update(cfg)
+		request("/api/v1/public/get-data", "")
+		assert.Equal(t, map[string]interface{}{"path": "/api/v1/public/get-data", "status": int64(http.StatusOK)}, logs.TakeAll()[0].ContextMap())
+	})
+
+	t.Run("Sampling", func(t *testing.T) {
+		cfg.AccessLog = config.AccessLog{SuccessSampleRate: 0.000001, ExcludeHealth: true}
+		dc.Update(cfg)
+		for i := 0; i < 10; i++ {
+			request("/api/v1/public/get-data", "")
+		}
+		assert.Equal(t, 0, logs.Len())
+
+		// The failed requests are always logged
+		request("/fail", "")
+		assert.Equal(t, 1, logs.Len())
+		logs.TakeAll()
+
+		cfg.AccessLog.SuccessSampleRate = 1
+		dc.Update(cfg)
+		request("/status/health", "")
+		assert.Equal(t, 0, logs.Len())
+	})
 }
 
 func TestCircuitBreakerMiddleware(t *testing.T) {
@@ -218,3 +296,900 @@ func TestGRPCInterceptor(t *testing.T) {
 		assert.Equal(t, codes.Unauthenticated, status.Code(err))
 	})
 }
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testServerStream) SetHeader(md metadata.MD) error {
+	return nil
+}
+
+func TestGRPCStreamInterceptor(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	dc := &config.DynamicConfig{
+		Config: config.Config{
+			APIKeys: []string{"valid-key"},
+		},
+	}
+	interceptor := StreamServerInterceptor(dc, logger)
+	info := &grpc.StreamServerInfo{FullMethod: "/gocaptcha.GoCaptchaManageService/UploadResource", IsClientStream: true}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}
+
+	t.Run("ValidKey", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "valid-key"))
+		err := interceptor(nil, &testServerStream{ctx: ctx}, info, handler)
+		assert.NoError(t, err)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs())
+		err := interceptor(nil, &testServerStream{ctx: ctx}, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "invalid-key"))
+		err := interceptor(nil, &testServerStream{ctx: ctx}, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestKeyedRateLimitMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.RateLimitRules = map[string]config.RateLimitRule{
+		config.RateLimitScopeGetData: {QPS: 1, Burst: 1},
+	}
+	limiter := NewKeyedRateLimiter(cfg)
+	mw := KeyedRateLimitMiddleware(limiter, logger)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	request := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		mw(handler)(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/get-data", "10.0.0.1:1234").Code)
+
+	rr := request("/api/v1/public/get-data", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	// Other clients and scopes are not affected
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/get-data", "10.0.0.2:1234").Code)
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/check-data", "10.0.0.1:1234").Code)
+	assert.Equal(t, 2, limiter.Len())
+}
+
+func TestKeyedRateLimiterAPIKeyID(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.APIKeys = []string{"legacy-secret"}
+	limiter := NewKeyedRateLimiter(cfg)
+
+	assert.Equal(t, "api_keys[0]", limiter.APIKeyID(context.Background(), "legacy-secret"))
+	assert.Empty(t, limiter.APIKeyID(context.Background(), "unknown-secret"))
+
+	// The authenticated key of a signed or JWT request wins over the header
+	ctx := WithAPIKeyID(context.Background(), "ops")
+	assert.Equal(t, "ops", limiter.APIKeyID(ctx, ""))
+}
+
+func TestKeyedRateLimiterEviction(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RateLimitIdleTimeout = 10
+	cfg.RateLimitMaxKeys = 2
+	limiter := NewKeyedRateLimiter(cfg)
+
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		_, ok := limiter.Allow(context.Background(), config.RateLimitScopeGetData, ClientIdentity{IP: ip})
+		assert.True(t, ok)
+	}
+	assert.LessOrEqual(t, limiter.Len(), 2)
+
+	now = now.Add(time.Minute)
+	_, ok := limiter.Allow(context.Background(), config.RateLimitScopeGetData, ClientIdentity{IP: "10.0.0.4"})
+	assert.True(t, ok)
+	assert.Equal(t, 1, limiter.Len())
+}
+
+func TestClientIP(t *testing.T) {
+	trusted := ParseIPNets([]string{"10.0.0.0/8", "192.168.1.1"})
+
+	assert.Equal(t, "1.2.3.4", ClientIP("1.2.3.4:80", "5.6.7.8", "", trusted))
+	assert.Equal(t, "5.6.7.8", ClientIP("10.0.0.1:80", "9.9.9.9, 5.6.7.8, 10.0.0.2", "", trusted))
+	assert.Equal(t, "5.6.7.8", ClientIP("192.168.1.1:80", "", "5.6.7.8", trusted))
+	assert.Equal(t, "10.0.0.2", ClientIP("10.0.0.1:80", "10.0.0.2", "", trusted))
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.APIKeys = []string{"key-1"}
+	cfg.RateLimitKeyBy = []string{config.RateLimitKeyAPIKey}
+	cfg.RateLimitRules = map[string]config.RateLimitRule{
+		config.RateLimitScopeCheckData: {QPS: 1, Burst: 1},
+	}
+	interceptor := RateLimitUnaryInterceptor(NewDynamicLimiter(1000, 1000), NewKeyedRateLimiter(cfg), logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/gocaptcha.GoCaptchaService/CheckData"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "success", nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "key-1"))
+	_, err := interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestDistributedLimiter(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	redisClient, err := cache.NewRedisClient(mr.Addr(), "TEST_", time.Minute, "", "", "")
+	assert.NoError(t, err)
+
+	cfg := config.DefaultConfig()
+	cfg.CacheType = "redis"
+	cfg.RateLimitMode = config.RateLimitModeDistributed
+	dist := NewDistributedLimiter(func() cache.Cache { return redisClient }, cfg, logger)
+
+	// Two nodes share the same quota
+	node1 := NewDynamicLimiter(2, 2)
+	node1.SetDistributed(dist)
+	node2 := NewDynamicLimiter(2, 2)
+	node2.SetDistributed(dist)
+
+	_, ok := node1.Allow(context.Background())
+	assert.True(t, ok)
+	_, ok = node2.Allow(context.Background())
+	assert.True(t, ok)
+	retryAfter, ok := node1.Allow(context.Background())
+	assert.False(t, ok)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// Falls back to the local limiter when the backend is unreachable
+	mr.Close()
+	_, ok = node2.Allow(context.Background())
+	assert.True(t, ok)
+}
+
+func TestGRPCChain(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	chain := NewChainGRPC(
+		GRPCRecoveryMiddleware(logger),
+		GRPCRequestIDMiddleware(),
+		GRPCLoggingMiddleware(logger, &config.DynamicConfig{Config: config.DefaultConfig()}),
+	)
+	interceptor := chain.Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gocaptcha.GoCaptchaService/GetData"}
+
+	t.Run("RequestID", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+		resp, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return RequestIDFromContext(ctx), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "req-1", resp)
+
+		resp, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return RequestIDFromContext(ctx), nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, resp, 32)
+	})
+
+	t.Run("Recovery", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream := chain.Stream()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-2"))
+		err := stream(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/gocaptcha.GoCaptchaManageService/UploadResource"}, func(srv interface{}, ss grpc.ServerStream) error {
+			assert.Equal(t, "req-2", RequestIDFromContext(ss.Context()))
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestIPFilterMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.TrustedProxies = []string{"10.0.0.1"}
+	cfg.IPFilter = config.IPFilterRule{Deny: []string{"203.0.113.0/24"}}
+	cfg.IPFilterGroups = map[string]config.IPFilterRule{
+		config.IPFilterGroupManage: {Allow: []string{"192.168.0.0/16"}},
+	}
+	cfg.IPFilterAPIKeys = map[string]config.IPFilterRule{
+		"office-key": {Allow: []string{"192.168.1.0/24"}},
+	}
+	filter := NewIPFilter(cfg)
+	mw := IPFilterMiddleware(filter, logger)
+	keyMw := APIKeyIPFilterMiddleware(filter, logger)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	request := func(path, remoteAddr, forwardedFor, keyID string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		if keyID != "" {
+			req = req.WithContext(WithAPIKeyID(req.Context(), keyID))
+		}
+		rr := httptest.NewRecorder()
+		mw(keyMw(handler))(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/get-data", "198.51.100.1:80", "", ""))
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/public/get-data", "203.0.113.5:80", "", ""))
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/public/get-data", "10.0.0.1:80", "203.0.113.5", ""))
+	assert.Equal(t, http.StatusOK, request("/status/live", "203.0.113.5:80", "", ""))
+
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/manage/get-config", "198.51.100.1:80", "", ""))
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/get-config", "192.168.2.1:80", "", ""))
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/manage/get-config", "192.168.2.1:80", "", "office-key"))
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/get-config", "192.168.1.1:80", "", "office-key"))
+
+	// Hot reload
+	cfg.IPFilter = config.IPFilterRule{}
+	filter.Update(cfg)
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/get-data", "203.0.113.5:80", "", ""))
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.EnableCors = true
+	cfg.CORS = config.CORSRule{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+	}
+	cfg.CORSSites = map[string]config.CORSRule{
+		"site-a": {AllowedOrigins: []string{"*"}, AllowCredentials: false},
+	}
+	dc := &config.DynamicConfig{Config: cfg}
+
+	mw := CORSMiddleware(dc, logger)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	request := func(method, target, origin string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Origin", origin)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		mw(handler)(rr, req)
+		return rr
+	}
+
+	rr := request("GET", "/api/v1/public/get-data", "https://app.example.com", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://app.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	rr = request("GET", "/api/v1/public/get-data", "https://evil.com", nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+
+	// Wildcard subdomain
+	assert.Equal(t, http.StatusOK, request("GET", "/api/v1/public/get-data", "https://a.b.example.org", nil).Code)
+	assert.Equal(t, http.StatusForbidden, request("GET", "/api/v1/public/get-data", "https://example.org", nil).Code)
+	assert.Equal(t, http.StatusForbidden, request("GET", "/api/v1/public/get-data", "http://a.example.org", nil).Code)
+
+	// Preflight
+	rr = request("OPTIONS", "/api/v1/public/check-data", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "content-type, x-api-key",
+	})
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "86400", rr.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Allow-Methods"), "POST")
+	rr = request("OPTIONS", "/api/v1/public/check-data", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method": "PATCH",
+	})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	rr = request("OPTIONS", "/api/v1/public/check-data", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "X-Unknown",
+	})
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Site override, credentials are never sent for "*"
+	rr = request("GET", "/api/v1/public/get-data?site_key=site-a", "https://evil.com", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "https://evil.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	// Requests without an origin are not cross-origin
+	assert.Equal(t, http.StatusOK, request("GET", "/api/v1/public/get-data", "", nil).Code)
+}
+
+func TestScopedAPIKeyMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.APIKeys = []string{"legacy-secret"}
+	cfg.ScopedAPIKeys = []config.APIKey{
+		{ID: "reader", SecretHash: config.HashAPIKeySecret("reader-secret"), Scopes: []string{config.ScopeStatusRead, config.ScopeConfigRead}},
+		{ID: "expired", SecretHash: "sha256:" + config.HashAPIKeySecret("expired-secret"), Scopes: []string{config.ScopeAll}, ExpiresAt: "2020-01-01T00:00:00Z"},
+	}
+	assert.NoError(t, config.Validate(cfg))
+	dc := &config.DynamicConfig{Config: cfg}
+
+	var keyID string
+	mw := APIKeyMiddleware(dc, logger)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		keyID = APIKeyIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}
+	request := func(path, secret string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("X-API-Key", secret)
+		rr := httptest.NewRecorder()
+		mw(handler)(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/get-config", "reader-secret"))
+	assert.Equal(t, "reader", keyID)
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/manage/update-hot-config", "reader-secret"))
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-config", "expired-secret"))
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-config", "unknown"))
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/upload-resource", "legacy-secret"))
+	assert.Equal(t, "api_keys[0]", keyID)
+
+	t.Run("GRPC", func(t *testing.T) {
+		interceptor := NewChainGRPC(GRPCLoggingMiddleware(logger, dc), GRPCAPIKeyMiddleware(dc, logger)).Unary()
+		call := func(method, secret string) (string, error) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", secret))
+			resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return APIKeyIDFromContext(ctx), nil
+			})
+			id, _ := resp.(string)
+			return id, err
+		}
+
+		id, err := call("/gocaptcha.GoCaptchaService/GetStatusInfo", "reader-secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "reader", id)
+		_, err = call("/gocaptcha.GoCaptchaManageService/UploadResource", "reader-secret")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = call("/gocaptcha.GoCaptchaService/GetStatusInfo", "expired-secret")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestLegacyAuthAPIs(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.APIKeys = []string{"valid-key"}
+	// The explicit list of the earlier config templates
+	cfg.AuthAPIs = []string{
+		"/api/v1/manage/get-status-info",
+		"/api/v1/manage/del-status-info",
+		"/api/v1/manage/upload-resource",
+		"/api/v1/manage/delete-resource",
+		"/api/v1/manage/get-resource-list",
+		"/api/v1/manage/get-config",
+		"/api/v1/manage/update-hot-config",
+	}
+	dc := &config.DynamicConfig{Config: cfg}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	mw := APIKeyMiddleware(dc, logger)
+
+	for _, path := range []string{
+		"/api/v1/manage/update-hot-config",
+		"/api/v1/manage/get-tenant-stats",
+		"/api/v1/manage/get-audit-log",
+		"/api/v1/manage/get-webhook-status",
+		"/api/v1/manage/rpc/get-status-info",
+		"/api/v1/manage/rpc/del-status-info",
+		config.AuthRateLimitPath,
+	} {
+		rr := httptest.NewRecorder()
+		mw(handler)(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, path)
+	}
+
+	interceptor := GRPCAPIKeyMiddleware(dc, logger).Unary
+	for _, method := range []string{
+		"/gocaptcha.GoCaptchaManageService/UpdateHotConfig",
+		"/gocaptcha.GoCaptchaManageService/UploadResource",
+		"/gocaptcha.GoCaptchaService/GetStatusInfo",
+	} {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), method)
+	}
+
+	// The public APIs stay open
+	rr := httptest.NewRecorder()
+	mw(handler)(rr, httptest.NewRequest("GET", "/api/v1/public/get-data", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRateLimitHandlerScope(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.ScopedAPIKeys = []config.APIKey{
+		{ID: "reader", SecretHash: config.HashAPIKeySecret("reader-secret"), Scopes: []string{config.ScopeConfigRead}},
+		{ID: "admin", SecretHash: config.HashAPIKeySecret("admin-secret"), Scopes: []string{config.ScopeConfigWrite}},
+	}
+	dc := &config.DynamicConfig{Config: cfg}
+	handler := APIKeyMiddleware(dc, logger)(RateLimitHandler(NewDynamicLimiter(10, 10), logger))
+
+	request := func(secret string) int {
+		req := httptest.NewRequest("POST", config.AuthRateLimitPath, strings.NewReader(`{"qps":20,"burst":20}`))
+		req.Header.Set("X-API-Key", secret)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(""))
+	assert.Equal(t, http.StatusForbidden, request("reader-secret"))
+	assert.Equal(t, http.StatusOK, request("admin-secret"))
+}
+
+func TestSignatureAuthMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.ScopedAPIKeys = []config.APIKey{
+		{ID: "backend", SigningSecret: "signing-secret", Scopes: []string{config.ScopeStatusRead}},
+		{ID: "uploader", SigningSecret: "upload-secret", Scopes: []string{config.ScopeResourceWrite}},
+	}
+	assert.NoError(t, config.Validate(cfg))
+	dc := &config.DynamicConfig{Config: cfg}
+
+	memCache := cache.NewMemoryCache("TEST:", time.Minute, time.Minute)
+	defer memCache.Close()
+	verifier := NewSignatureVerifier(func() cache.Cache { return memCache }, logger)
+
+	var keyID string
+	chain := NewChainHTTP(SignatureAuthMiddleware(verifier, dc, logger), APIKeyMiddleware(dc, logger))
+	handler := chain.Then(func(w http.ResponseWriter, r *http.Request) {
+		keyID = APIKeyIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(path, body, timestamp, nonce, signature string) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(SignatureKeyIDHeader, "backend")
+		req.Header.Set(SignatureTimestampHeader, timestamp)
+		req.Header.Set(SignatureNonceHeader, nonce)
+		req.Header.Set(SignatureHeader, signature)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr.Code
+	}
+
+	path := "/api/v1/manage/get-status-info?captchaKey=abc"
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := SignRequest("signing-secret", "POST", path, ts, "nonce-0001", []byte("{}"))
+	assert.Equal(t, http.StatusOK, request(path, "{}", ts, "nonce-0001", sig))
+	assert.Equal(t, "backend", keyID)
+
+	// Replay
+	assert.Equal(t, http.StatusUnauthorized, request(path, "{}", ts, "nonce-0001", sig))
+	// Tampered body
+	sig = SignRequest("signing-secret", "POST", path, ts, "nonce-0002", []byte("{}"))
+	assert.Equal(t, http.StatusUnauthorized, request(path, `{"a":1}`, ts, "nonce-0002", sig))
+	// Clock skew
+	old := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	sig = SignRequest("signing-secret", "POST", path, old, "nonce-0003", []byte("{}"))
+	assert.Equal(t, http.StatusUnauthorized, request(path, "{}", old, "nonce-0003", sig))
+	// Scope
+	uploadPath := "/api/v1/manage/upload-resource"
+	sig = SignRequest("signing-secret", "POST", uploadPath, ts, "nonce-0004", nil)
+	assert.Equal(t, http.StatusForbidden, request(uploadPath, "", ts, "nonce-0004", sig))
+
+	codec := NewPayloadCodec()
+	grpcChain := NewChainGRPC(GRPCPayloadMiddleware(codec), GRPCSignatureAuthMiddleware(verifier, dc, logger), GRPCAPIKeyMiddleware(dc, logger))
+
+	t.Run("GRPC", func(t *testing.T) {
+		interceptor := grpcChain.Unary()
+		method := "/gocaptcha.GoCaptchaService/GetStatusInfo"
+		body, _ := protoutil.Marshal(wrapperspb.String("abc"))
+		call := func(nonce, sig string, body []byte) error {
+			req := &wrapperspb.StringValue{}
+			assert.NoError(t, codec.Unmarshal(mem.BufferSlice{mem.SliceBuffer(body)}, req))
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"x-key-id", "backend", "x-timestamp", ts, "x-nonce", nonce, "x-signature", sig,
+			))
+			_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+			return err
+		}
+
+		sig := SignRequest("signing-secret", "POST", method, ts, "nonce-0005", body)
+		assert.NoError(t, call("nonce-0005", sig, body))
+		assert.Equal(t, codes.Unauthenticated, status.Code(call("nonce-0005", sig, body)))
+
+		// The received bytes are signed, not a re-encoding of the request
+		tampered, _ := protoutil.Marshal(wrapperspb.String("abd"))
+		sig = SignRequest("signing-secret", "POST", method, ts, "nonce-0006", body)
+		assert.Equal(t, codes.Unauthenticated, status.Code(call("nonce-0006", sig, tampered)))
+	})
+
+	t.Run("GRPCStream", func(t *testing.T) {
+		interceptor := grpcChain.Stream()
+		method := "/gocaptcha.GoCaptchaManageService/UploadResource"
+		var chunks [][]byte
+		var hashes []string
+		for _, chunk := range []string{"chunk-1", "chunk-2"} {
+			body, _ := protoutil.Marshal(wrapperspb.String(chunk))
+			sum := sha256.Sum256(body)
+			chunks = append(chunks, body)
+			hashes = append(hashes, hex.EncodeToString(sum[:]))
+		}
+		list := strings.Join(hashes, ",")
+
+		call := func(nonce string, chunks [][]byte) (int, error) {
+			sig := SignRequest("upload-secret", "POST", method, ts, nonce, []byte(list))
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"x-key-id", "uploader", "x-timestamp", ts, "x-nonce", nonce, "x-signature", sig, "x-payload-sha256", list,
+			))
+			ss := &codecServerStream{testServerStream: testServerStream{ctx: ctx}, codec: codec, msgs: chunks}
+			var received int
+			err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: method, IsClientStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+				for {
+					if err := stream.RecvMsg(&wrapperspb.StringValue{}); err != nil {
+						if errors.Is(err, io.EOF) {
+							return nil
+						}
+						return err
+					}
+					received++
+				}
+			})
+			return received, err
+		}
+
+		received, err := call("nonce-0007", chunks)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, received)
+
+		// A changed chunk is rejected before the handler sees it
+		other, _ := protoutil.Marshal(wrapperspb.String("chunk-x"))
+		received, err = call("nonce-0008", [][]byte{chunks[0], other})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, 1, received)
+
+		// Missing and extra chunks
+		_, err = call("nonce-0009", chunks[:1])
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = call("nonce-0010", append(chunks, chunks[1]))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// codecServerStream receives its messages through a codec
+type codecServerStream struct {
+	testServerStream
+	codec *PayloadCodec
+	msgs  [][]byte
+}
+
+func (s *codecServerStream) RecvMsg(m any) error {
+	if len(s.msgs) == 0 {
+		return io.EOF
+	}
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return s.codec.Unmarshal(mem.BufferSlice{mem.SliceBuffer(msg)}, m)
+}
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "EC", "kid": "k1", "use": "sig", "crv": "P-256",
+		"x": base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+		"y": base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))),
+	}}})
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	defer jwksServer.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.JWTAuth = config.JWTAuth{
+		Enable:      true,
+		Issuer:      "https://idp.example.com",
+		Audience:    []string{"go-captcha"},
+		JWKSURL:     jwksServer.URL,
+		ClaimScopes: map[string][]string{"captcha-admin": {config.ScopeAll}},
+	}
+	assert.NoError(t, config.Validate(cfg))
+	dc := &config.DynamicConfig{Config: cfg}
+	verifier := NewJWTVerifier(cfg, logger)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = "k1"
+		s, _ := token.SignedString(privateKey)
+		return s
+	}
+	claims := func(aud string, roles ...string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": "https://idp.example.com", "aud": aud, "sub": "alice",
+			"exp": time.Now().Add(time.Hour).Unix(), "roles": roles,
+		}
+	}
+
+	var keyID string
+	chain := NewChainHTTP(JWTAuthMiddleware(verifier, dc, logger), APIKeyMiddleware(dc, logger))
+	handler := chain.Then(func(w http.ResponseWriter, r *http.Request) {
+		keyID = APIKeyIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(path, token string) int {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/update-hot-config", sign(claims("go-captcha", "captcha-admin"))))
+	assert.Equal(t, "jwt:alice", keyID)
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/get-config", sign(claims("go-captcha", config.ScopeConfigRead))))
+	assert.Equal(t, http.StatusForbidden, request("/api/v1/manage/update-hot-config", sign(claims("go-captcha", config.ScopeConfigRead))))
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-config", sign(claims("other", "captcha-admin"))))
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-config", "not-a-token"))
+
+	expired := claims("go-captcha", "captcha-admin")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-config", sign(expired)))
+
+	t.Run("GRPC", func(t *testing.T) {
+		interceptor := NewChainGRPC(GRPCJWTAuthMiddleware(verifier, dc, logger), GRPCAPIKeyMiddleware(dc, logger)).Unary()
+		call := func(method, token string) error {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+			return err
+		}
+		assert.NoError(t, call("/gocaptcha.GoCaptchaManageService/GetConfig", sign(claims("go-captcha", config.ScopeConfigRead))))
+		assert.Equal(t, codes.PermissionDenied, status.Code(call("/gocaptcha.GoCaptchaManageService/DeleteResource", sign(claims("go-captcha", config.ScopeConfigRead)))))
+	})
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.RateLimitKeyBy = []string{config.RateLimitKeySiteKey}
+	cfg.RateLimitRules = map[string]config.RateLimitRule{
+		config.RateLimitScopeGetData: {QPS: 1, Burst: 1},
+	}
+	cfg.Tenants = map[string]config.Tenant{
+		"acme":   {SiteKeys: []string{"acme-site"}, RateLimitRules: map[string]config.RateLimitRule{config.RateLimitScopeGetData: {QPS: 1, Burst: 2}}},
+		"globex": {SiteKeys: []string{"globex-site"}},
+	}
+	cfg.ScopedAPIKeys = []config.APIKey{
+		{ID: "globex-admin", SecretHash: config.HashAPIKeySecret("globex-secret"), Scopes: []string{config.ScopeAll}, Tenant: "globex"},
+	}
+	assert.NoError(t, config.Validate(cfg))
+	dc := &config.DynamicConfig{Config: cfg}
+
+	var tenantID string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		tenantID = tenant.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}
+	mw := NewChainHTTP(TenantMiddleware(dc), KeyedRateLimitMiddleware(NewKeyedRateLimiter(cfg), logger))
+	request := func(siteKey, secret string) int {
+		req := httptest.NewRequest("GET", "/api/v1/public/get-data?site_key="+siteKey, nil)
+		req.Header.Set("X-API-Key", secret)
+		rr := httptest.NewRecorder()
+		mw.Then(handler)(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("acme-site", ""))
+	assert.Equal(t, "acme", tenantID)
+	// The key of a tenant takes precedence over the site key
+	assert.Equal(t, http.StatusOK, request("acme-site", "globex-secret"))
+	assert.Equal(t, "globex", tenantID)
+	assert.Equal(t, http.StatusOK, request("unknown-site", ""))
+	assert.Equal(t, "", tenantID)
+
+	// The tenant rules replace the global ones
+	assert.Equal(t, http.StatusOK, request("acme-site", ""))
+	assert.Equal(t, http.StatusTooManyRequests, request("acme-site", ""))
+	assert.Equal(t, http.StatusOK, request("globex-site", ""))
+	assert.Equal(t, http.StatusTooManyRequests, request("globex-site", ""))
+
+	t.Run("GRPC", func(t *testing.T) {
+		interceptor := NewChainGRPC(GRPCTenantMiddleware(dc)).Unary()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-site-key", "globex-site"))
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/gocaptcha.GoCaptchaService/GetData"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return tenant.FromContext(ctx), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "globex", resp)
+	})
+
+	cfg.Tenants["acme"] = config.Tenant{ResourceDir: "../acme"}
+	assert.Error(t, config.Validate(cfg))
+}
+
+func TestClientCertMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.TlsClientAuth = config.TlsClientAuthManage
+	dc := &config.DynamicConfig{Config: cfg}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	mw := NewChainHTTP(ClientCertMiddleware(dc, logger))
+	request := func(path string, state *tls.ConnectionState) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.TLS = state
+		rr := httptest.NewRecorder()
+		mw.Then(handler)(rr, req)
+		return rr.Code
+	}
+
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/get-data", nil))
+	assert.Equal(t, http.StatusUnauthorized, request("/api/v1/manage/get-status-info", &tls.ConnectionState{}))
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/get-status-info", verified))
+
+	t.Run("GRPC", func(t *testing.T) {
+		interceptor := NewChainGRPC(GRPCClientCertMiddleware(dc, logger)).Unary()
+		info := &grpc.UnaryServerInfo{FullMethod: "/gocaptcha.GoCaptchaService/GetStatusInfo"}
+		ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+		_, err := interceptor(context.Background(), nil, info, ok)
+		assert.Error(t, err)
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: *verified}})
+		resp, err := interceptor(ctx, nil, info, ok)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+
+	cfg.TlsClientAuth = config.TlsClientAuthAll
+	assert.Error(t, config.Validate(cfg))
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Limits.RouteBodyLimits = map[string]int64{"check-data": 8}
+	dc := &config.DynamicConfig{Config: cfg}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var v map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			WriteAppError(w, BodyReadError(err, "invalid request body"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	mw := NewChainHTTP(BodyLimitMiddleware(dc))
+	request := func(path, body string, chunked bool) int {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+		rr := httptest.NewRecorder()
+		mw.Then(handler)(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("/api/v1/public/check-data", `{"a":1}`, false))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, request("/api/v2/public/check-data", `{"a":"123456"}`, false))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, request("/api/v1/rpc/check-data", `{"a":"123456"}`, true))
+	assert.Equal(t, http.StatusBadRequest, request("/api/v1/public/check-data", `{"a"`, false))
+	assert.Equal(t, http.StatusOK, request("/api/v1/manage/update-hot-config", `{"a":"123456"}`, false))
+
+	// The default route limits are kept
+	assert.Equal(t, int64(10<<20), cfg.GetServerLimits().BodyLimit("/api/v1/manage/upload-resource"))
+	cfg.Limits.RouteBodyLimits = map[string]int64{"/api/v1/public/check-data": 8}
+	assert.Error(t, config.Validate(cfg))
+}
+
+func TestDebugMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.ScopedAPIKeys = []config.APIKey{
+		{ID: "ops", SecretHash: config.HashAPIKeySecret("ops-secret"), Scopes: []string{config.ScopeDebug}},
+		{ID: "reader", SecretHash: config.HashAPIKeySecret("reader-secret"), Scopes: []string{config.ScopeStatusRead}},
+	}
+	dc := &config.DynamicConfig{Config: cfg}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	request := func(public bool, secret string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/runtime-stats", nil)
+		req.Header.Set("X-API-Key", secret)
+		rr := httptest.NewRecorder()
+		DebugMiddleware(dc, logger, public)(handler)(rr, req)
+		return rr.Code
+	}
+
+	// Disabled by default
+	assert.Equal(t, http.StatusNotFound, request(false, ""))
+	assert.Equal(t, http.StatusNotFound, request(true, "ops-secret"))
+
+	cfg.Debug.Enable = true
+	dc.Update(cfg)
+	assert.Equal(t, http.StatusOK, request(false, ""))
+	assert.Equal(t, http.StatusOK, request(true, "ops-secret"))
+	assert.Equal(t, http.StatusForbidden, request(true, "reader-secret"))
+	assert.Equal(t, http.StatusUnauthorized, request(true, ""))
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	cfg := config.DefaultConfig()
+	cfg.Audit.File = filepath.Join(t.TempDir(), "audit.log")
+	dc := &config.DynamicConfig{Config: cfg}
+	auditLog, err := audit.New(cfg.GetAuditLog())
+	assert.NoError(t, err)
+	defer auditLog.Close()
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		audit.SetTarget(r.Context(), "bg/a.png")
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	chain := NewChainHTTP(LoggingMiddleware(logger, dc), AuditMiddleware(auditLog, dc, logger), func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r.WithContext(WithAPIKeyID(r.Context(), "ops")))
+		}
+	})
+
+	for _, path := range []string{"/api/v1/manage/delete-resource", "/api/v1/public/get-data"} {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		chain.Then(handler).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Only the manage requests are recorded
+	entries, err := auditLog.Query(audit.Filter{})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "ops", entries[0].Actor)
+		assert.Equal(t, "10.0.0.1", entries[0].Source)
+		assert.Equal(t, "delete-resource", entries[0].Action)
+		assert.Equal(t, "bg/a.png", entries[0].Target)
+		assert.Equal(t, audit.ResultFailure, entries[0].Result)
+		assert.Equal(t, "400", entries[0].Status)
+	}
+
+	assert.Equal(t, "upload-resource", AuditAction("/gocaptcha.GoCaptchaManageService/UploadResource"))
+}
@@ -0,0 +1,118 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	encproto "google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/mem"
+)
+
+// PayloadCodec is the proto codec of the gRPC servers, it records the SHA-256 of the received bytes of
+// every message so that the signed calls are verified against what the client sent.
+type PayloadCodec struct {
+	codec  encoding.CodecV2
+	hashes sync.Map // decoded message -> []byte
+}
+
+// NewPayloadCodec creates a new payload codec
+func NewPayloadCodec() *PayloadCodec {
+	return &PayloadCodec{codec: encoding.GetCodecV2(encproto.Name)}
+}
+
+// Marshal .
+func (c *PayloadCodec) Marshal(v any) (mem.BufferSlice, error) {
+	return c.codec.Marshal(v)
+}
+
+// Unmarshal decodes the message and records the hash of its bytes
+func (c *PayloadCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	if err := c.codec.Unmarshal(data, v); err != nil {
+		return err
+	}
+	h := sha256.New()
+	for _, buf := range data {
+		h.Write(buf.ReadOnlyData())
+	}
+	c.hashes.Store(v, h.Sum(nil))
+	return nil
+}
+
+// Name .
+func (c *PayloadCodec) Name() string {
+	return c.codec.Name()
+}
+
+// take returns and forgets the hash of a decoded message
+func (c *PayloadCodec) take(v any) []byte {
+	if hash, ok := c.hashes.LoadAndDelete(v); ok {
+		return hash.([]byte)
+	}
+	return nil
+}
+
+// payloadHashKey .
+type payloadHashKey struct{}
+
+// payloadHashHolder holds the hash of the last message received by a call
+type payloadHashHolder struct {
+	hash []byte
+}
+
+// withPayloadHash stores the hash of the received message in the context
+func withPayloadHash(ctx context.Context, hash []byte) (context.Context, *payloadHashHolder) {
+	holder := &payloadHashHolder{hash: hash}
+	return context.WithValue(ctx, payloadHashKey{}, holder), holder
+}
+
+// payloadHashFromContext returns the SHA-256 of the last message received by the call, nil if unknown
+func payloadHashFromContext(ctx context.Context) []byte {
+	if holder, ok := ctx.Value(payloadHashKey{}).(*payloadHashHolder); ok {
+		return holder.hash
+	}
+	return nil
+}
+
+// payloadServerStream records the hash of every received message in its context
+type payloadServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	codec  *PayloadCodec
+	holder *payloadHashHolder
+}
+
+// Context .
+func (s *payloadServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg .
+func (s *payloadServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	s.holder.hash = s.codec.take(m)
+	return err
+}
+
+// GRPCPayloadMiddleware moves the hashes recorded by the codec into the call contexts.
+// It must be the outermost middleware as the unary requests are decoded before the chain runs.
+func GRPCPayloadMiddleware(codec *PayloadCodec) GRPCMiddleware {
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, _ = withPayloadHash(ctx, codec.take(req))
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, holder := withPayloadHash(ss.Context(), nil)
+			return handler(srv, &payloadServerStream{ServerStream: ss, ctx: ctx, codec: codec, holder: holder})
+		},
+	}
+}
@@ -0,0 +1,320 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+)
+
+// evictBatchRatio is the share of buckets evicted when the limiter is full of active keys
+const evictBatchRatio = 0.01
+
+// ClientIdentity is the set of keys a request is rate limited by
+type ClientIdentity struct {
+	IP       string
+	APIKeyID string // id of the API key, never the secret
+	SiteKey  string
+}
+
+// value returns the identity value of the rate limit key
+func (id ClientIdentity) value(key string) string {
+	switch key {
+	case config.RateLimitKeyIP:
+		return id.IP
+	case config.RateLimitKeyAPIKey:
+		return id.APIKeyID
+	case config.RateLimitKeySiteKey:
+		return id.SiteKey
+	}
+	return ""
+}
+
+// keyedBucket .
+type keyedBucket struct {
+	tenant   string
+	scope    string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// KeyedRateLimiter keeps a token bucket per tenant, scope and client key
+type KeyedRateLimiter struct {
+	mu sync.Mutex
+
+	keyBy       []string
+	rules       map[string]config.RateLimitRule
+	tenantCfg   config.Config
+	trusted     []*net.IPNet
+	idleTimeout time.Duration
+	maxKeys     int
+
+	buckets   map[string]*keyedBucket
+	lastSweep time.Time
+	now       func() time.Time
+	dist      *DistributedLimiter
+}
+
+// NewKeyedRateLimiter creates a new keyed rate limiter
+func NewKeyedRateLimiter(cfg config.Config) *KeyedRateLimiter {
+	kl := &KeyedRateLimiter{
+		buckets: make(map[string]*keyedBucket),
+		now:     time.Now,
+	}
+	kl.Update(cfg)
+	kl.lastSweep = kl.now()
+	return kl
+}
+
+// Update updates the keys, quotas and proxies, the quotas of existing buckets are changed in place
+func (kl *KeyedRateLimiter) Update(cfg config.Config) {
+	keyBy := cfg.RateLimitKeyBy
+	if len(keyBy) == 0 {
+		keyBy = []string{config.RateLimitKeyIP}
+	}
+
+	rules := make(map[string]config.RateLimitRule, len(cfg.RateLimitRules))
+	for scope, rule := range cfg.RateLimitRules {
+		rules[scope] = rule
+	}
+
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	kl.keyBy = keyBy
+	kl.rules = rules
+	kl.tenantCfg = config.Config{Tenants: cfg.Tenants, ScopedAPIKeys: cfg.ScopedAPIKeys, APIKeys: cfg.APIKeys}
+	kl.trusted = ParseIPNets(cfg.GetTrustedProxies())
+	kl.idleTimeout = time.Duration(cfg.RateLimitIdleTimeout) * time.Second
+	kl.maxKeys = cfg.RateLimitMaxKeys
+
+	for key, b := range kl.buckets {
+		rule, ok := kl.rule(b.tenant, b.scope)
+		if !ok {
+			delete(kl.buckets, key)
+			continue
+		}
+		b.limiter.SetLimit(rate.Limit(rule.QPS))
+		b.limiter.SetBurst(rule.Burst)
+	}
+}
+
+// rule returns the quota of the scope, falling back to the default scope.
+// The rules of the tenant replace the global ones.
+func (kl *KeyedRateLimiter) rule(tenantID, scope string) (config.RateLimitRule, bool) {
+	rules := kl.rules
+	t, isTenant := kl.tenantCfg.Tenants[tenantID]
+	_, hasScope := t.RateLimitRules[scope]
+	_, hasDefault := t.RateLimitRules[config.RateLimitScopeDefault]
+	if isTenant && tenantID != "" && (hasScope || hasDefault) {
+		rules = t.RateLimitRules
+	}
+
+	rule, ok := rules[scope]
+	if !ok {
+		rule, ok = rules[config.RateLimitScopeDefault]
+	}
+	return rule, ok && rule.QPS > 0
+}
+
+// Allow takes a token from every bucket of the client without blocking.
+// When any bucket is empty nothing is consumed and the longest retry delay is returned.
+// The tenant of the context, or else of the client keys, has its own buckets.
+func (kl *KeyedRateLimiter) Allow(ctx context.Context, scope string, id ClientIdentity) (time.Duration, bool) {
+	kl.mu.Lock()
+	tenantID := tenant.FromContext(ctx)
+	if tenantID == "" {
+		tenantID = kl.tenantCfg.ResolveTenant(id.APIKeyID, "", id.SiteKey)
+	}
+	rule, ok := kl.rule(tenantID, scope)
+	prefix := scope + "|"
+	if tenantID != "" {
+		prefix = "tenant:" + tenantID + "|" + prefix
+	}
+	var keys []string
+	for _, key := range kl.keyBy {
+		if value := id.value(key); value != "" {
+			keys = append(keys, prefix+key+":"+value)
+		}
+	}
+	dist := kl.dist
+	kl.mu.Unlock()
+
+	if !ok || len(keys) == 0 {
+		return 0, true
+	}
+	if retryAfter, allowed, handled := dist.Allow(ctx, keys, rule.QPS, rule.Burst); handled {
+		return retryAfter, allowed
+	}
+
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	now := kl.now()
+	kl.sweep(now)
+
+	var reservations []*rate.Reservation
+	var retryAfter time.Duration
+	for _, key := range keys {
+		b := kl.bucket(key, tenantID, scope, rule, now)
+		r := b.limiter.ReserveN(now, 1)
+		if !r.OK() {
+			retryAfter = max(retryAfter, time.Second)
+			continue
+		}
+		if delay := r.DelayFrom(now); delay > 0 {
+			r.CancelAt(now)
+			retryAfter = max(retryAfter, delay)
+			continue
+		}
+		reservations = append(reservations, r)
+	}
+
+	if retryAfter > 0 {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+		return retryAfter, false
+	}
+	return 0, true
+}
+
+// APIKeyID returns the id of the API key the request is limited by, the authenticated key of the context
+// or else the valid key of the secret sent on a public route. Unknown secrets are not used as keys.
+func (kl *KeyedRateLimiter) APIKeyID(ctx context.Context, secret string) string {
+	if id := APIKeyIDFromContext(ctx); id != "" {
+		return id
+	}
+	if secret == "" {
+		return ""
+	}
+	kl.mu.Lock()
+	cfg := kl.tenantCfg
+	kl.mu.Unlock()
+	key, _ := cfg.LookupAPIKey(secret)
+	return key.ID
+}
+
+// SetDistributed shares the quotas with the other nodes when the distributed mode is on
+func (kl *KeyedRateLimiter) SetDistributed(dist *DistributedLimiter) {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	kl.dist = dist
+}
+
+// Len returns the number of buckets
+func (kl *KeyedRateLimiter) Len() int {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	return len(kl.buckets)
+}
+
+// ClientIP returns the client address using the trusted proxies of the limiter
+func (kl *KeyedRateLimiter) ClientIP(remoteAddr, forwardedFor, realIP string) string {
+	kl.mu.Lock()
+	trusted := kl.trusted
+	kl.mu.Unlock()
+	return ClientIP(remoteAddr, forwardedFor, realIP, trusted)
+}
+
+// bucket returns the bucket of the key, it is created when missing
+func (kl *KeyedRateLimiter) bucket(key, tenantID, scope string, rule config.RateLimitRule, now time.Time) *keyedBucket {
+	b, ok := kl.buckets[key]
+	if !ok {
+		if kl.maxKeys > 0 && len(kl.buckets) >= kl.maxKeys {
+			kl.evict(now)
+		}
+		b = &keyedBucket{tenant: tenantID, scope: scope, limiter: rate.NewLimiter(rate.Limit(rule.QPS), rule.Burst)}
+		kl.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b
+}
+
+// sweep removes the buckets idle longer than the idle timeout, it runs at most once per half timeout
+func (kl *KeyedRateLimiter) sweep(now time.Time) {
+	if kl.idleTimeout <= 0 || now.Sub(kl.lastSweep) < kl.idleTimeout/2 {
+		return
+	}
+	kl.lastSweep = now
+	for key, b := range kl.buckets {
+		if now.Sub(b.lastSeen) > kl.idleTimeout {
+			delete(kl.buckets, key)
+		}
+	}
+}
+
+// evict makes room for a new bucket, idle buckets go first and then a small random batch
+func (kl *KeyedRateLimiter) evict(now time.Time) {
+	kl.lastSweep = time.Time{}
+	kl.sweep(now)
+	if len(kl.buckets) < kl.maxKeys {
+		return
+	}
+
+	n := max(1, int(float64(kl.maxKeys)*evictBatchRatio))
+	for key := range kl.buckets {
+		delete(kl.buckets, key)
+		n--
+		if n <= 0 {
+			break
+		}
+	}
+}
+
+// reserve takes a token without blocking, the reservation is cancelled when it would have to wait
+func reserve(limiter *rate.Limiter, now time.Time) (time.Duration, bool) {
+	r := limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return time.Second, false
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+// HTTPRateLimitScope returns the rate limit scope of the HTTP path, empty for unlimited paths
+func HTTPRateLimitScope(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/status/"):
+		return ""
+	case strings.HasPrefix(path, "/api/v1/manage/"), strings.HasPrefix(path, "/debug/"), path == "/rate-limit":
+		return config.RateLimitScopeManage
+	case strings.HasSuffix(path, "/get-data"):
+		return config.RateLimitScopeGetData
+	case strings.HasSuffix(path, "/check-data"):
+		return config.RateLimitScopeCheckData
+	}
+	return config.RateLimitScopeDefault
+}
+
+// GRPCRateLimitScope returns the rate limit scope of the gRPC method, empty for unlimited methods
+func GRPCRateLimitScope(fullMethod string) string {
+	switch {
+	case strings.HasPrefix(fullMethod, "/grpc.health.v1."):
+		return ""
+	case strings.Contains(fullMethod, "ManageService/"),
+		strings.HasSuffix(fullMethod, "/GetStatusInfo"),
+		strings.HasSuffix(fullMethod, "/DelStatusInfo"):
+		return config.RateLimitScopeManage
+	case strings.HasSuffix(fullMethod, "/GetData"):
+		return config.RateLimitScopeGetData
+	case strings.HasSuffix(fullMethod, "/CheckData"):
+		return config.RateLimitScopeCheckData
+	}
+	return config.RateLimitScopeDefault
+}
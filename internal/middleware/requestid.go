@@ -0,0 +1,66 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+)
+
+// Request id header and metadata keys
+const (
+	RequestIDHeader      = "X-Request-ID"
+	RequestIDMetadataKey = reqinfo.RequestIDMetadataKey
+)
+
+// maxRequestIDLength bounds the accepted client request ids
+const maxRequestIDLength = 128
+
+// RequestIDFromContext returns the request id of ctx, empty when missing
+func RequestIDFromContext(ctx context.Context) string {
+	return reqinfo.RequestID(ctx)
+}
+
+// NewRequestID generates a random request id
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware accepts or generates the request id, it is stored in the context and returned in the response header
+func RequestIDMiddleware() HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(id) {
+				id = NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next(w, r.WithContext(reqinfo.WithRequestID(r.Context(), id)))
+		}
+	}
+}
+
+// isValidRequestID checks a client supplied request id, only short ids of safe characters are accepted
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
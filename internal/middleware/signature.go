@@ -0,0 +1,323 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wenlng/go-captcha-service/internal/cache"
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+)
+
+// Signed request headers, the gRPC metadata keys are the lower case names
+const (
+	SignatureKeyIDHeader     = "X-Key-Id"
+	SignatureTimestampHeader = "X-Timestamp"
+	SignatureNonceHeader     = "X-Nonce"
+	SignatureHeader          = "X-Signature"
+	// SignaturePayloadHashesHeader lists the hex SHA-256 of the messages of a signed gRPC stream, comma-separated
+	SignaturePayloadHashesHeader = "X-Payload-Sha256"
+)
+
+const (
+	// minNonceLen and maxNonceLen bound the length of the request nonce
+	minNonceLen = 8
+	maxNonceLen = 128
+	// grpcSignatureMethod is the method signed by the gRPC calls
+	grpcSignatureMethod = "POST"
+)
+
+// SignedRequest is the authentication data of an HMAC signed request
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	URI       string // path and query of HTTP requests, full method of gRPC calls
+	Body      []byte
+	BodyHash  []byte // SHA-256 of the body when the body itself is not kept, replaces Body
+}
+
+// SignRequest returns the hex HMAC-SHA256 signature of a request.
+// The signed string is the method, the URI, the timestamp, the nonce and the hex SHA-256 of the body joined by newlines.
+func SignRequest(secret, method, uri, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return signRequestHash(secret, method, uri, timestamp, nonce, bodyHash[:])
+}
+
+// signRequestHash returns the signature of a request from the SHA-256 of its body
+func signRequestHash(secret, method, uri, timestamp, nonce string, bodyHash []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + uri + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureVerifier verifies HMAC signed requests, the nonces are recorded in the cache backend to reject replays.
+// When the cache cannot record nonces they are recorded locally.
+type SignatureVerifier struct {
+	getCache func() cache.Cache
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewSignatureVerifier creates a new signature verifier
+func NewSignatureVerifier(getCache func() cache.Cache, logger *zap.Logger) *SignatureVerifier {
+	return &SignatureVerifier{
+		getCache: getCache,
+		logger:   logger,
+		nonces:   make(map[string]time.Time),
+	}
+}
+
+// Verify checks the signature, the timestamp and the nonce of the request and returns its API key
+func (v *SignatureVerifier) Verify(ctx context.Context, cfg *config.Config, req SignedRequest) (config.APIKey, error) {
+	key, ok := cfg.GetSigningKey(req.KeyID)
+	if !ok {
+		return config.APIKey{}, errcode.ErrUnauthenticated.WithMessage("invalid signing key")
+	}
+
+	ts, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return key, errcode.ErrUnauthenticated.WithMessage("invalid request timestamp")
+	}
+	skew := cfg.GetSignatureMaxSkew()
+	if diff := time.Since(time.Unix(ts, 0)); diff > skew || diff < -skew {
+		return key, errcode.ErrUnauthenticated.WithMessage("request timestamp out of range")
+	}
+	if len(req.Nonce) < minNonceLen || len(req.Nonce) > maxNonceLen {
+		return key, errcode.ErrUnauthenticated.WithMessage("invalid request nonce")
+	}
+
+	bodyHash := req.BodyHash
+	if bodyHash == nil {
+		sum := sha256.Sum256(req.Body)
+		bodyHash = sum[:]
+	}
+	expected := signRequestHash(key.SigningSecret, req.Method, req.URI, req.Timestamp, req.Nonce, bodyHash)
+	if !hmac.Equal([]byte(expected), []byte(req.Signature)) {
+		return key, errcode.ErrUnauthenticated.WithMessage("invalid signature")
+	}
+
+	// A nonce is kept as long as its timestamp is accepted
+	if !v.addNonce(ctx, key.ID+":"+req.Nonce, 2*skew) {
+		return key, errcode.ErrUnauthenticated.WithMessage("nonce already used")
+	}
+	return key, nil
+}
+
+// addNonce records the nonce and reports false when it was already used
+func (v *SignatureVerifier) addNonce(ctx context.Context, nonce string, ttl time.Duration) bool {
+	if store, ok := v.getCache().(cache.NonceStore); ok {
+		added, err := store.AddNonce(ctx, nonce, ttl)
+		if err == nil {
+			return added
+		}
+		v.logger.Warn("[Signature] Failed to record the nonce in the cache, recording it locally", zap.Error(err))
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	if now.Sub(v.lastSweep) > ttl {
+		for n, expiresAt := range v.nonces {
+			if !now.Before(expiresAt) {
+				delete(v.nonces, n)
+			}
+		}
+		v.lastSweep = now
+	}
+	if expiresAt, exists := v.nonces[nonce]; exists && now.Before(expiresAt) {
+		return false
+	}
+	v.nonces[nonce] = now.Add(ttl)
+	return true
+}
+
+// SignatureAuthMiddleware authenticates the HMAC signed requests of the auth APIs, unsigned requests are left to the API key middleware
+func SignatureAuthMiddleware(verifier *SignatureVerifier, dc *config.DynamicConfig, logger *zap.Logger) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			if r.Header.Get(SignatureHeader) == "" {
+				next(w, r)
+				return
+			}
+			if !cfg.IsAuthAPI(r.URL.Path) {
+				next(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				WriteAppError(w, BodyReadError(err, "failed to read request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key, err := verifier.Verify(r.Context(), &cfg, SignedRequest{
+				KeyID:     r.Header.Get(SignatureKeyIDHeader),
+				Timestamp: r.Header.Get(SignatureTimestampHeader),
+				Nonce:     r.Header.Get(SignatureNonceHeader),
+				Signature: r.Header.Get(SignatureHeader),
+				Method:    r.Method,
+				URI:       r.URL.RequestURI(),
+				Body:      body,
+			})
+			if err == nil {
+				err = authorizeAPIKey(key, r.URL.Path)
+			}
+			if err != nil {
+				logger.Warn("[HttpMiddleware] Signed request rejected",
+					zap.String("path", r.URL.Path),
+					zap.String("key_id", key.ID),
+					zap.Error(err),
+				)
+				WriteAppError(w, err)
+				return
+			}
+			next(w, r.WithContext(WithAPIKeyID(r.Context(), key.ID)))
+		}
+	}
+}
+
+// GRPCSignatureAuthMiddleware authenticates the HMAC signed calls of the auth APIs.
+// Unary calls sign the received bytes of the request, the hashes are recorded by the PayloadCodec.
+// Stream calls sign the X-Payload-Sha256 list and every received message must match the next hash of it.
+func GRPCSignatureAuthMiddleware(verifier *SignatureVerifier, dc *config.DynamicConfig, logger *zap.Logger) GRPCMiddleware {
+	verify := func(ctx context.Context, method string, stream bool) (context.Context, [][]byte, error) {
+		cfg := dc.Get()
+		md, _ := metadata.FromIncomingContext(ctx)
+		signature := firstMetadata(md, "x-signature")
+		if signature == "" {
+			return ctx, nil, nil
+		}
+		if !cfg.IsAuthAPI(method) {
+			return ctx, nil, nil
+		}
+
+		req := SignedRequest{
+			KeyID:     firstMetadata(md, "x-key-id"),
+			Timestamp: firstMetadata(md, "x-timestamp"),
+			Nonce:     firstMetadata(md, "x-nonce"),
+			Signature: signature,
+			Method:    grpcSignatureMethod,
+			URI:       method,
+		}
+		var hashes [][]byte
+		var err error
+		if stream {
+			list := firstMetadata(md, "x-payload-sha256")
+			hashes, err = parsePayloadHashes(list)
+			req.Body = []byte(list)
+		} else if req.BodyHash = payloadHashFromContext(ctx); req.BodyHash == nil {
+			err = errcode.ErrUnauthenticated.WithMessage("request payload not available")
+		}
+
+		var key config.APIKey
+		if err == nil {
+			key, err = verifier.Verify(ctx, &cfg, req)
+		}
+		if err == nil {
+			err = authorizeAPIKey(key, method)
+		}
+		if err != nil {
+			logger.Warn("[GrpcMiddleware] Signed request rejected",
+				zap.String("method", method),
+				zap.String("key_id", key.ID),
+				zap.Error(err),
+			)
+			return ctx, nil, err
+		}
+		return WithAPIKeyID(ctx, key.ID), hashes, nil
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, _, err := verify(ctx, info.FullMethod, false)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, hashes, err := verify(ss.Context(), info.FullMethod, true)
+			if err != nil {
+				return err
+			}
+			if hashes == nil {
+				return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+			}
+			return handler(srv, &signedServerStream{ServerStream: ss, ctx: ctx, hashes: hashes})
+		},
+	}
+}
+
+// parsePayloadHashes parses the comma-separated hex SHA-256 of the messages of a signed stream
+func parsePayloadHashes(list string) ([][]byte, error) {
+	if list == "" {
+		return nil, errcode.ErrUnauthenticated.WithMessage("missing payload hashes")
+	}
+	parts := strings.Split(list, ",")
+	hashes := make([][]byte, 0, len(parts))
+	for _, part := range parts {
+		hash, err := hex.DecodeString(strings.TrimSpace(part))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, errcode.ErrUnauthenticated.WithMessage("invalid payload hash")
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// signedServerStream checks every received message against the signed payload hashes
+type signedServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	hashes [][]byte
+}
+
+// Context .
+func (s *signedServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg rejects the messages that were not signed, before the handler sees them
+func (s *signedServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if errors.Is(err, io.EOF) && len(s.hashes) > 0 {
+		return errcode.ErrUnauthenticated.WithMessage("stream ended before the signed messages")
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(s.hashes) == 0 || !hmac.Equal(payloadHashFromContext(s.ctx), s.hashes[0]) {
+		return errcode.ErrUnauthenticated.WithMessage("stream message does not match the signature")
+	}
+	s.hashes = s.hashes[1:]
+	return nil
+}
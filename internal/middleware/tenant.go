@@ -0,0 +1,60 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+)
+
+// TenantMiddleware resolves the tenant of the request by its API key or site key
+func TenantMiddleware(dc *config.DynamicConfig) HTTPMiddleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			cfg := dc.Get()
+			siteKey := r.Header.Get("X-Site-Key")
+			if siteKey == "" {
+				siteKey = r.URL.Query().Get("site_key")
+			}
+
+			id := cfg.ResolveTenant(APIKeyIDFromContext(r.Context()), r.Header.Get("X-API-Key"), siteKey)
+			if id == "" {
+				next(w, r)
+				return
+			}
+			next(w, r.WithContext(tenant.WithTenant(r.Context(), id)))
+		}
+	}
+}
+
+// GRPCTenantMiddleware resolves the tenant of the call by its API key or site key
+func GRPCTenantMiddleware(dc *config.DynamicConfig) GRPCMiddleware {
+	resolve := func(ctx context.Context) context.Context {
+		cfg := dc.Get()
+		md, _ := metadata.FromIncomingContext(ctx)
+		id := cfg.ResolveTenant(APIKeyIDFromContext(ctx), firstMetadata(md, "x-api-key"), firstMetadata(md, "x-site-key"))
+		if id == "" {
+			return ctx
+		}
+		return tenant.WithTenant(ctx, id)
+	}
+
+	return GRPCMiddleware{
+		Unary: func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(resolve(ctx), req)
+		},
+		Stream: func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &contextServerStream{ServerStream: ss, ctx: resolve(ss.Context())})
+		},
+	}
+}
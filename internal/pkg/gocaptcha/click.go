@@ -7,6 +7,7 @@
 package gocaptcha
 
 import (
+	"context"
 	"image"
 	"path"
 	"strings"
@@ -19,6 +20,7 @@ import (
 	"github.com/wenlng/go-captcha-assets/resources/shapes"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 	"github.com/wenlng/go-captcha/v2/click"
 )
 
@@ -29,6 +31,14 @@ type ClickCaptInstance struct {
 	Instance         click.Captcha
 }
 
+// Generate generates the captcha data of the instance
+func (ci *ClickCaptInstance) Generate(ctx context.Context) (data click.CaptchaData, err error) {
+	_, span := tracing.Start(ctx, "GoCaptcha.GenerateClick")
+	defer func() { tracing.End(span, err) }()
+
+	return ci.Instance.Generate()
+}
+
 // genClickOptions .
 func genClickOptions(conf config.ClickConfig) ([]click.Option, error) {
 	options := make([]click.Option, 0)
@@ -165,7 +175,7 @@ func genClickResources(conf config.ClickConfig, resources config.ResourceConfig)
 	if len(resources.Font.FileMaps) > 0 {
 		var newFonts = make([]*truetype.Font, 0)
 		for _, file := range resources.Font.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.Font.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 			stream, err := helper.ReadFileStream(filepath)
@@ -194,7 +204,7 @@ func genClickResources(conf config.ClickConfig, resources config.ResourceConfig)
 	if len(resources.MasterImage.FileMaps) > 0 {
 		var newImages = make([]image.Image, 0)
 		for _, file := range resources.MasterImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.MasterImage.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 
@@ -218,7 +228,7 @@ func genClickResources(conf config.ClickConfig, resources config.ResourceConfig)
 	if len(resources.ThumbImage.FileMaps) > 0 {
 		var newImages = make([]image.Image, 0)
 		for _, file := range resources.ThumbImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.ThumbImage.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 
@@ -274,7 +284,7 @@ func setupClickShapeCapt(conf config.ClickConfig, resources config.ResourceConfi
 	if len(resources.ShapeImage.FileMaps) > 0 {
 		var newImageMaps = make(map[string]image.Image, 0)
 		for name, file := range resources.ShapeImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.ShapeImage.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 
@@ -39,6 +39,7 @@ type DynamicCaptchaConfig struct {
 	Config      CaptchaConfig
 	mu          sync.RWMutex
 	hotCbsHooks map[string]HandleHotCallbackFnc
+	resourceDir string
 
 	outputLogCbs helper.OutputLogCallback
 }
@@ -48,6 +49,7 @@ type HotCallbackType int
 const (
 	HotCallbackTypeLocalConfigFile HotCallbackType = 1
 	HotCallbackTypeRemoteConfig                    = 2
+	HotCallbackTypeManageAPI                       = 3
 )
 
 type HandleHotCallbackFnc = func(*DynamicCaptchaConfig, HotCallbackType)
@@ -91,6 +93,14 @@ func (dc *DynamicCaptchaConfig) outLog(logType helper.OutputLogType, message str
 	}
 }
 
+// SetResourceDir sets the directory of the resource files, empty is the resources directory of the service
+func (dc *DynamicCaptchaConfig) SetResourceDir(dir string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.resourceDir = dir
+	dc.Config.Resources.RootDir = dir
+}
+
 // Get ..
 func (dc *DynamicCaptchaConfig) Get() CaptchaConfig {
 	dc.mu.RLock()
@@ -100,6 +110,10 @@ func (dc *DynamicCaptchaConfig) Get() CaptchaConfig {
 
 // Update updates the configuration
 func (dc *DynamicCaptchaConfig) Update(cfg CaptchaConfig) error {
+	dc.mu.RLock()
+	cfg.Resources.RootDir = dc.resourceDir
+	dc.mu.RUnlock()
+
 	if err := Validate(cfg); err != nil {
 		return err
 	}
@@ -130,6 +144,7 @@ func (dc *DynamicCaptchaConfig) UnMarshalConfig(str string) error {
 	}
 
 	dc.mu.Lock()
+	config.Resources.RootDir = dc.resourceDir
 	dc.Config = config
 	dc.mu.Unlock()
 
@@ -217,7 +232,7 @@ func (dc *DynamicCaptchaConfig) HotUpdate(cfg CaptchaConfig) error {
 	}
 
 	// Instance update gocaptcha
-	dc.HandleHotCallback(HotCallbackTypeLocalConfigFile)
+	dc.HandleHotCallback(HotCallbackTypeManageAPI)
 	return nil
 }
 
@@ -237,7 +252,7 @@ func Load(file string) (CaptchaConfig, error) {
 // Validate checks the configuration for validity
 func Validate(config CaptchaConfig) error {
 	filepathList := make([]string, 0, 0)
-	resourcePath := helper.GetResourceDirAbsPath()
+	resourcePath := config.Resources.GetRootDir()
 
 	fontConfig := config.Resources.Font
 	for _, f := range fontConfig.FileMaps {
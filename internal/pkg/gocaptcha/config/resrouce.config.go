@@ -6,6 +6,8 @@
 
 package config
 
+import "github.com/wenlng/go-captcha-service/internal/helper"
+
 // ResourceChar .
 type ResourceChar struct {
 	Type      string              `json:"type"`
@@ -37,4 +39,14 @@ type ResourceConfig struct {
 	MasterImage ResourceFileConfig      `json:"master_image"`
 	ThumbImage  ResourceFileConfig      `json:"thumb_image"`
 	TileImage   ResourceMultiFileConfig `json:"tile_image"`
+
+	RootDir string `json:"-"` // directory of the resource files, empty is the resources directory of the service
+}
+
+// GetRootDir returns the directory of the resource files
+func (r ResourceConfig) GetRootDir() string {
+	if r.RootDir != "" {
+		return r.RootDir
+	}
+	return helper.GetResourceDirAbsPath()
 }
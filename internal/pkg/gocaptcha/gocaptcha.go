@@ -20,7 +20,8 @@ const (
 
 // GoCaptcha .
 type GoCaptcha struct {
-	DynamicCnf *config.DynamicCaptchaConfig
+	DynamicCnf    *config.DynamicCaptchaConfig
+	GenerateQueue *GenerateQueue
 
 	clickInstanceMaps      map[string]*ClickCaptInstance
 	clickShapeInstanceMaps map[string]*ClickCaptInstance
@@ -58,6 +59,33 @@ func (gc *GoCaptcha) GetCaptTypeWithKey(key string) int {
 	return consts.GoCaptchaTypeUnknown
 }
 
+// InstanceCount returns the number of loaded captcha instances
+func (gc *GoCaptcha) InstanceCount() int {
+	count := 0
+
+	gc.clickInstanceMutex.RLock()
+	count += len(gc.clickInstanceMaps)
+	gc.clickInstanceMutex.RUnlock()
+
+	gc.clickShapeInstanceMutex.RLock()
+	count += len(gc.clickShapeInstanceMaps)
+	gc.clickShapeInstanceMutex.RUnlock()
+
+	gc.slideInstanceMutex.RLock()
+	count += len(gc.slideInstanceMaps)
+	gc.slideInstanceMutex.RUnlock()
+
+	gc.dragInstanceMutex.RLock()
+	count += len(gc.dragInstanceMaps)
+	gc.dragInstanceMutex.RUnlock()
+
+	gc.rotateInstanceMutex.RLock()
+	count += len(gc.rotateInstanceMaps)
+	gc.rotateInstanceMutex.RUnlock()
+
+	return count
+}
+
 // GetClickInstanceWithKey .
 func (gc *GoCaptcha) GetClickInstanceWithKey(key string) *ClickCaptInstance {
 	gc.clickInstanceMutex.RLock()
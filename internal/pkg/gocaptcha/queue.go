@@ -0,0 +1,60 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package gocaptcha
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// GenerateQueue bounds the number of concurrent captcha generations
+type GenerateQueue struct {
+	slots   chan struct{}
+	waiting atomic.Int64
+}
+
+// NewGenerateQueue creates a queue, a size less than or equal to zero means unbounded
+func NewGenerateQueue(size int) *GenerateQueue {
+	q := &GenerateQueue{}
+	if size > 0 {
+		q.slots = make(chan struct{}, size)
+	}
+	return q
+}
+
+// Acquire waits for a free generation slot
+func (q *GenerateQueue) Acquire(ctx context.Context) error {
+	if q == nil || q.slots == nil {
+		return nil
+	}
+
+	q.waiting.Add(1)
+	defer q.waiting.Add(-1)
+
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot held by the caller
+func (q *GenerateQueue) Release() {
+	if q == nil || q.slots == nil {
+		return
+	}
+	<-q.slots
+}
+
+// Saturation returns the ratio of in-flight and waiting generations to the queue capacity
+func (q *GenerateQueue) Saturation() float64 {
+	if q == nil || q.slots == nil {
+		return 0
+	}
+	return float64(int64(len(q.slots))+q.waiting.Load()) / float64(cap(q.slots))
+}
@@ -7,12 +7,14 @@
 package gocaptcha
 
 import (
+	"context"
 	"image"
 	"path"
 
 	images "github.com/wenlng/go-captcha-assets/resources/images_v2"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 	"github.com/wenlng/go-captcha/v2/rotate"
 )
 
@@ -23,6 +25,14 @@ type RotateCaptInstance struct {
 	Instance         rotate.Captcha
 }
 
+// Generate generates the captcha data of the instance
+func (ci *RotateCaptInstance) Generate(ctx context.Context) (data rotate.CaptchaData, err error) {
+	_, span := tracing.Start(ctx, "GoCaptcha.GenerateRotate")
+	defer func() { tracing.End(span, err) }()
+
+	return ci.Instance.Generate()
+}
+
 // genRotateOptions .
 func genRotateOptions(conf config.RotateConfig) ([]rotate.Option, error) {
 	options := make([]rotate.Option, 0)
@@ -56,7 +66,7 @@ func genRotateResources(conf config.RotateConfig, resources config.ResourceConfi
 	if len(resources.MasterImage.FileMaps) > 0 {
 		var newImages = make([]image.Image, 0)
 		for _, file := range resources.MasterImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.MasterImage.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 
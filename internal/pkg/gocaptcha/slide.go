@@ -7,6 +7,7 @@
 package gocaptcha
 
 import (
+	"context"
 	"image"
 	"path"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/wenlng/go-captcha-assets/resources/tiles"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/internal/tracing"
 	"github.com/wenlng/go-captcha/v2/base/codec"
 	"github.com/wenlng/go-captcha/v2/slide"
 )
@@ -25,6 +27,14 @@ type SlideCaptInstance struct {
 	Instance         slide.Captcha
 }
 
+// Generate generates the captcha data of the instance
+func (ci *SlideCaptInstance) Generate(ctx context.Context) (data slide.CaptchaData, err error) {
+	_, span := tracing.Start(ctx, "GoCaptcha.GenerateSlide")
+	defer func() { tracing.End(span, err) }()
+
+	return ci.Instance.Generate()
+}
+
 // genSlideOptions .
 func genSlideOptions(conf config.SlideConfig) ([]slide.Option, error) {
 	options := make([]slide.Option, 0)
@@ -84,7 +94,7 @@ func genSlideResources(conf config.SlideConfig, resources config.ResourceConfig)
 	if len(resources.MasterImage.FileMaps) > 0 {
 		var newImages = make([]image.Image, 0)
 		for _, file := range resources.MasterImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.MasterImage.FileDir
 			filepath := path.Join(resourcesPath, rootDir, file)
 			stream, err := helper.ReadFileStream(filepath)
@@ -120,7 +130,7 @@ func genSlideResources(conf config.SlideConfig, resources config.ResourceConfig)
 	if len(resources.TileImage.FileMaps) > 0 {
 		var newGraphs = make([]*slide.GraphImage, 0, len(resources.TileImage.FileMaps))
 		for name, file := range resources.TileImage.FileMaps {
-			resourcesPath := helper.GetResourceDirAbsPath()
+			resourcesPath := resources.GetRootDir()
 			rootDir := resources.TileImage.FileDir
 			overlayImageFilepath := path.Join(resourcesPath, rootDir, file)
 
@@ -0,0 +1,93 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package reqinfo
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey is the gRPC metadata key of the request id
+const RequestIDMetadataKey = "x-request-id"
+
+// requestIDKey .
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request id of ctx, empty when missing
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// OutgoingContext adds the request id of ctx to the metadata of the outgoing gRPC calls
+func OutgoingContext(ctx context.Context) context.Context {
+	if id := RequestID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, id)
+	}
+	return ctx
+}
+
+// Captcha is the captcha handled by the request
+type Captcha struct {
+	ID   string
+	Type string
+	Key  string
+}
+
+// captchaKey .
+type captchaKey struct{}
+
+// captchaHolder lets the access log read the captcha resolved by the logic
+type captchaHolder struct {
+	mu      sync.Mutex
+	captcha Captcha
+}
+
+// WithCaptchaHolder adds a holder for the captcha of the request to the context
+func WithCaptchaHolder(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(captchaKey{}).(*captchaHolder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, captchaKey{}, &captchaHolder{})
+}
+
+// SetCaptcha records the non-empty fields of c, it does nothing without a holder
+func SetCaptcha(ctx context.Context, c Captcha) {
+	holder, ok := ctx.Value(captchaKey{}).(*captchaHolder)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	if c.ID != "" {
+		holder.captcha.ID = c.ID
+	}
+	if c.Type != "" {
+		holder.captcha.Type = c.Type
+	}
+	if c.Key != "" {
+		holder.captcha.Key = c.Key
+	}
+}
+
+// CaptchaFromContext returns the captcha recorded for the request
+func CaptchaFromContext(ctx context.Context) Captcha {
+	holder, ok := ctx.Value(captchaKey{}).(*captchaHolder)
+	if !ok {
+		return Captcha{}
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return holder.captcha
+}
@@ -0,0 +1,225 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/wenlng/go-captcha-service/internal/adapt"
+	"github.com/wenlng/go-captcha-service/internal/common"
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/middleware"
+	"go.uber.org/zap"
+)
+
+// Profile types of the capture API, the others are looked up in runtime/pprof
+const (
+	profileTypeCPU  = "cpu"
+	profileTypeHeap = "heap"
+)
+
+// defaultProfileSeconds is the duration of a CPU capture without the seconds parameter
+const defaultProfileSeconds = 30
+
+// DebugHandlers serves the runtime stats, the goroutine dump and the profile captures
+type DebugHandlers struct {
+	svcCtx     *common.SvcContext
+	dynamicCfg *config.DynamicConfig
+	logger     *zap.Logger
+	startedAt  time.Time
+
+	// Only one profile is captured at a time
+	capturing atomic.Bool
+}
+
+// NewDebugHandlers .
+func NewDebugHandlers(svcCtx *common.SvcContext) *DebugHandlers {
+	return &DebugHandlers{
+		svcCtx:     svcCtx,
+		dynamicCfg: svcCtx.DynamicConfig,
+		logger:     svcCtx.Logger,
+		startedAt:  time.Now(),
+	}
+}
+
+// RuntimeStats .
+type RuntimeStats struct {
+	Uptime                  string  `json:"uptime"`
+	GoVersion               string  `json:"go_version"`
+	NumCPU                  int     `json:"num_cpu"`
+	GOMAXPROCS              int     `json:"gomaxprocs"`
+	Goroutines              int     `json:"goroutines"`
+	HeapAlloc               uint64  `json:"heap_alloc"`
+	HeapInuse               uint64  `json:"heap_inuse"`
+	HeapObjects             uint64  `json:"heap_objects"`
+	Sys                     uint64  `json:"sys"`
+	TotalAlloc              uint64  `json:"total_alloc"`
+	NumGC                   uint32  `json:"num_gc"`
+	LastGC                  string  `json:"last_gc"`
+	PauseTotal              string  `json:"pause_total"`
+	GenerateQueueSaturation float64 `json:"generate_queue_saturation"`
+}
+
+// RuntimeStatsHandler reports the goroutines, the memory and the GC statistics
+func (h *DebugHandlers) RuntimeStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	stats := &RuntimeStats{
+		Uptime:      time.Since(h.startedAt).Round(time.Second).String(),
+		GoVersion:   runtime.Version(),
+		NumCPU:      runtime.NumCPU(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   ms.HeapAlloc,
+		HeapInuse:   ms.HeapInuse,
+		HeapObjects: ms.HeapObjects,
+		Sys:         ms.Sys,
+		TotalAlloc:  ms.TotalAlloc,
+		NumGC:       ms.NumGC,
+		PauseTotal:  time.Duration(ms.PauseTotalNs).String(),
+	}
+	if ms.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(ms.LastGC)).Format(time.RFC3339)
+	}
+	if h.svcCtx.Captcha != nil && h.svcCtx.Captcha.GenerateQueue != nil {
+		stats.GenerateQueueSaturation = h.svcCtx.Captcha.GenerateQueue.Saturation()
+	}
+
+	resp.Data = stats
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
+
+// GoroutineDumpHandler writes the stacks of all the goroutines as text
+func (h *DebugHandlers) GoroutineDumpHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// CaptureProfileHandler captures a profile into debug.profile_dir and returns the path of the file.
+// The cpu profile lasts the seconds parameter, the other runtime/pprof profiles such as heap are snapshots.
+func (h *DebugHandlers) CaptureProfileHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
+	if r.Method != http.MethodPost {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+
+	cfg := h.dynamicCfg.Get()
+	debug := cfg.GetDebugEndpoints()
+
+	query := r.URL.Query()
+	profileType := query.Get("type")
+	if profileType == "" {
+		profileType = profileTypeCPU
+	}
+	if profileType != profileTypeCPU && pprof.Lookup(profileType) == nil {
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("unknown profile type: "+profileType))
+		return
+	}
+	seconds := defaultProfileSeconds
+	if v := query.Get("seconds"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("seconds must be a positive integer"))
+			return
+		}
+		seconds = n
+	}
+	if seconds > debug.MaxProfileSeconds {
+		seconds = debug.MaxProfileSeconds
+	}
+
+	if !h.capturing.CompareAndSwap(false, true) {
+		middleware.WriteAppError(w, errcode.ErrServiceUnavailable.WithMessage("a profile capture is already running"))
+		return
+	}
+	defer h.capturing.Store(false)
+
+	if err := os.MkdirAll(debug.ProfileDir, 0750); err != nil {
+		middleware.WriteAppError(w, errcode.ErrInternal.Wrapf("failed to create the profile directory: %v", err))
+		return
+	}
+	file := filepath.Join(debug.ProfileDir, fmt.Sprintf("%s-%s.pprof", profileType, time.Now().Format("20060102-150405")))
+	f, err := os.Create(file)
+	if err != nil {
+		middleware.WriteAppError(w, errcode.ErrInternal.Wrapf("failed to create the profile file: %v", err))
+		return
+	}
+	defer f.Close()
+
+	if profileType == profileTypeCPU {
+		err = h.captureCPUProfile(w, r, f, time.Duration(seconds)*time.Second)
+	} else {
+		seconds = 0
+		if profileType == profileTypeHeap {
+			runtime.GC()
+		}
+		err = pprof.Lookup(profileType).WriteTo(f, 0)
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		h.logger.Warn("[HttpHandler] Failed to capture the profile", zap.String("type", profileType), zap.Error(err))
+		middleware.WriteAppError(w, err)
+		return
+	}
+
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	h.logger.Info("[HttpHandler] Profile captured", zap.String("type", profileType), zap.String("file", file), zap.Int64("size", size))
+
+	resp.Data = map[string]interface{}{
+		"type":    profileType,
+		"file":    file,
+		"size":    size,
+		"seconds": seconds,
+	}
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
+
+// captureCPUProfile profiles the CPU for the duration, the write deadline of the response is extended past it.
+// A request cancelled before the end fails, its truncated profile is removed by the caller.
+func (h *DebugHandlers) captureCPUProfile(w http.ResponseWriter, r *http.Request, f *os.File, duration time.Duration) error {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(duration + 10*time.Second))
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return errcode.ErrServiceUnavailable.WithMessage("a CPU profile is already running")
+	}
+	defer pprof.StopCPUProfile()
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.Context().Done():
+		return errcode.ErrServiceUnavailable.WithMessage("the profile capture was cancelled").Wrap(r.Context().Err())
+	}
+}
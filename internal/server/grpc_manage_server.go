@@ -0,0 +1,235 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/wenlng/go-captcha-service/internal/audit"
+	"github.com/wenlng/go-captcha-service/internal/common"
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/logic"
+	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/proto"
+	"go.uber.org/zap"
+)
+
+// GrpcManageServer implements the gRPC manage service
+type GrpcManageServer struct {
+	svcCtx *common.SvcContext
+	proto.UnimplementedGoCaptchaManageServiceServer
+	dynamicCfg *config.DynamicConfig
+	logger     *zap.Logger
+
+	// Initialize logic
+	resourceLogic *logic.ResourceLogic
+}
+
+// NewGoCaptchaManageServer creates a new gRPC manage server
+func NewGoCaptchaManageServer(svcCtx *common.SvcContext) *GrpcManageServer {
+	return &GrpcManageServer{
+		svcCtx:        svcCtx,
+		dynamicCfg:    svcCtx.DynamicConfig,
+		logger:        svcCtx.Logger,
+		resourceLogic: logic.NewResourceLogic(svcCtx),
+	}
+}
+
+// UploadResource handle
+func (s *GrpcManageServer) UploadResource(stream proto.GoCaptchaManageService_UploadResourceServer) error {
+	ctx := stream.Context()
+
+	var dirname, filename string
+	var dst *os.File
+	var size int64
+	cfg := s.dynamicCfg.Get()
+	maxSize := cfg.GetServerLimits().BodyLimit("upload-resource")
+	var fileCount int
+	allDone := true
+
+	// The hashes of the saved files are recorded to the audit log
+	var hasher *audit.Hasher
+	saved := make(map[string]string)
+	defer func() {
+		audit.SetTarget(ctx, dirname)
+		audit.SetChange(ctx, &audit.Change{Files: saved})
+	}()
+
+	// closeFile closes the file being written, the partial file is removed when the upload failed
+	closeFile := func(failed bool) {
+		if dst == nil {
+			return
+		}
+		name := dst.Name()
+		dst.Close()
+		if failed {
+			os.Remove(name)
+		} else {
+			saved[filename] = hasher.Sum()
+		}
+		dst = nil
+	}
+
+	fail := func(err error) error {
+		closeFile(true)
+		s.logger.Warn("[GrpcManageServer] Failed to upload resource, err: ", zap.Error(err))
+		return errcode.FromError(err)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			closeFile(true)
+			return err
+		}
+
+		if dirname == "" {
+			dirname = req.GetDirname()
+			if dirname == "" {
+				return fail(errcode.ErrInvalidArgument.WithMessage("dirname is required"))
+			}
+			if !helper.IsValidDirName(dirname) {
+				return fail(errcode.ErrInvalidArgument.WithMessage("invalid directory name"))
+			}
+		}
+
+		if req.GetFilename() != "" && req.GetFilename() != filename {
+			closeFile(false)
+			filename = req.GetFilename()
+
+			var exists bool
+			dst, exists, err = s.resourceLogic.CreateResourceFile(ctx, dirname, filename)
+			if err != nil {
+				return fail(err)
+			}
+			if exists {
+				allDone = false
+			}
+			hasher = audit.NewHasher()
+			fileCount++
+		}
+
+		if filename == "" {
+			return fail(errcode.ErrInvalidArgument.WithMessage("filename is required"))
+		}
+
+		size += int64(len(req.GetChunk()))
+		if size > maxSize {
+			return fail(errcode.ErrResourceTooLarge)
+		}
+
+		// Chunks of an existing file are discarded
+		if dst == nil {
+			continue
+		}
+		if _, err = io.MultiWriter(dst, hasher).Write(req.GetChunk()); err != nil {
+			return fail(errcode.ErrInternal.Wrapf("failed to save file %s: %v", filename, err))
+		}
+	}
+	closeFile(false)
+
+	if fileCount == 0 {
+		return fail(errcode.ErrInvalidArgument.WithMessage("no files uploaded"))
+	}
+	s.resourceLogic.NotifyUploaded(ctx, dirname, saved)
+
+	resp := &proto.ManageResponse{Code: http.StatusOK, Message: "success", Data: "ok"}
+	if !allDone {
+		resp.Data = "some-files-ok"
+		resp.Message = "some files failed to be uploaded. check if they already exist"
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+// DeleteResource handle
+func (s *GrpcManageServer) DeleteResource(ctx context.Context, req *proto.ResourcePathRequest) (*proto.ManageResponse, error) {
+	resp := &proto.ManageResponse{Code: http.StatusOK, Message: "success"}
+
+	if req.GetPath() == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("path is required")
+	}
+
+	ret, err := s.resourceLogic.DelResource(ctx, req.GetPath())
+	if err != nil {
+		s.logger.Warn("[GrpcManageServer] Failed to delete resource, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
+	}
+
+	if ret {
+		resp.Data = "ok"
+	} else {
+		resp.Data = "no-ops"
+	}
+
+	return resp, nil
+}
+
+// GetResourceList handle
+func (s *GrpcManageServer) GetResourceList(ctx context.Context, req *proto.ResourcePathRequest) (*proto.ResourceListResponse, error) {
+	resp := &proto.ResourceListResponse{Code: http.StatusOK, Message: "success"}
+
+	if req.GetPath() == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("path is required")
+	}
+
+	fileList, err := s.resourceLogic.GetResourceList(ctx, req.GetPath())
+	if err != nil {
+		s.logger.Warn("[GrpcManageServer] Failed to get resource, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
+	}
+
+	resp.Files = fileList
+	return resp, nil
+}
+
+// GetConfig handle
+func (s *GrpcManageServer) GetConfig(ctx context.Context, req *proto.GetConfigRequest) (*proto.ManageResponse, error) {
+	audit.SetTarget(ctx, captchaConfigTarget(ctx))
+	dataByte, err := json.Marshal(s.svcCtx.GetCaptcha(ctx).DynamicCnf.Get())
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
+	}
+
+	return &proto.ManageResponse{Code: http.StatusOK, Message: "success", Data: string(dataByte)}, nil
+}
+
+// UpdateHotConfig handle
+func (s *GrpcManageServer) UpdateHotConfig(ctx context.Context, req *proto.UpdateHotConfigRequest) (*proto.ManageResponse, error) {
+	var conf config2.CaptchaConfig
+	if err := json.Unmarshal([]byte(req.GetConfig()), &conf); err != nil {
+		return nil, errcode.ErrConfigInvalid
+	}
+
+	captcha := s.svcCtx.GetWritableCaptcha(ctx)
+	if captcha == nil {
+		return nil, errcode.ErrForbidden.WithMessage("the tenant shares the default captcha config")
+	}
+
+	audit.SetTarget(ctx, captchaConfigTarget(ctx))
+	before := captcha.DynamicCnf.Get()
+	err := captcha.DynamicCnf.HotUpdate(conf)
+	change := audit.Diff(before, captcha.DynamicCnf.Get())
+	audit.SetChange(ctx, change)
+	if err != nil {
+		s.logger.Warn("[GrpcManageServer] Failed to hot update config, err: ", zap.Error(err))
+		return nil, errcode.ErrConfigInvalid.Wrap(err).WithMessage("hot update config fail")
+	}
+	notifyConfigUpdated(ctx, s.svcCtx, change)
+
+	return &proto.ManageResponse{Code: http.StatusOK, Message: "success", Data: "ok"}, nil
+}
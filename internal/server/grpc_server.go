@@ -9,13 +9,14 @@ package server
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/wenlng/go-captcha-service/internal/adapt"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
 	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
 	"github.com/wenlng/go-captcha-service/internal/logic"
 	"github.com/wenlng/go-captcha-service/proto"
 	"go.uber.org/zap"
@@ -50,17 +51,17 @@ func NewGoCaptchaServer(svcCtx *common.SvcContext) *GrpcServer {
 
 // GetData handle
 func (s *GrpcServer) GetData(ctx context.Context, req *proto.GetDataRequest) (*proto.GetDataResponse, error) {
-	resp := &proto.GetDataResponse{Code: 0}
+	resp := &proto.GetDataResponse{Code: http.StatusOK, Message: "success"}
 	var err error
 
 	var data = &adapt.CaptData{}
 
 	id := req.GetId()
 	if id == "" {
-		return &proto.GetDataResponse{Code: 0, Message: "missing id parameter"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
 	}
 
-	switch s.svcCtx.Captcha.GetCaptTypeWithKey(id) {
+	switch s.svcCtx.GetCaptcha(ctx).GetCaptTypeWithKey(id) {
 	case consts.GoCaptchaTypeClick:
 		data, err = s.clickCaptLogic.GetData(ctx, id)
 		break
@@ -77,12 +78,12 @@ func (s *GrpcServer) GetData(ctx context.Context, req *proto.GetDataRequest) (*p
 		data, err = s.rotateCaptLogic.GetData(ctx, id)
 		break
 	default:
-		//
+		err = errcode.ErrCaptchaTypeNotFound
 	}
 
-	if err != nil || data == nil {
+	if err != nil {
 		s.logger.Warn("[GrpcServer] Failed to get captcha data, err: ", zap.Error(err))
-		return &proto.GetDataResponse{Code: 0, Message: "captcha type not found"}, nil
+		return nil, errcode.FromError(err)
 	}
 
 	resp.Id = req.GetId()
@@ -103,20 +104,20 @@ func (s *GrpcServer) GetData(ctx context.Context, req *proto.GetDataRequest) (*p
 
 // CheckData handle
 func (s *GrpcServer) CheckData(ctx context.Context, req *proto.CheckDataRequest) (*proto.CheckDataResponse, error) {
-	resp := &proto.CheckDataResponse{Code: 0}
+	resp := &proto.CheckDataResponse{Code: http.StatusOK, Message: "success"}
 
 	if req.GetCaptchaKey() == "" || req.GetValue() == "" {
-		return &proto.CheckDataResponse{Code: 1, Message: "captchaKey and value are required"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("captchaKey and value are required")
 	}
 
 	id := req.GetId()
 	if id == "" {
-		return &proto.CheckDataResponse{Code: 0, Message: "missing id parameter"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
 	}
 
 	var err error
 	var ok bool
-	switch s.svcCtx.Captcha.GetCaptTypeWithKey(id) {
+	switch s.svcCtx.GetCaptcha(ctx).GetCaptTypeWithKey(id) {
 	case consts.GoCaptchaTypeClick:
 		ok, err = s.clickCaptLogic.CheckData(ctx, req.GetCaptchaKey(), req.GetValue())
 		break
@@ -132,23 +133,27 @@ func (s *GrpcServer) CheckData(ctx context.Context, req *proto.CheckDataRequest)
 	case consts.GoCaptchaTypeRotate:
 		var angle int64
 		angle, err = strconv.ParseInt(req.GetValue(), 10, 64)
-		if err == nil {
+		if err != nil {
+			err = errcode.ErrInvalidArgument.WithMessage("invalid value")
+		} else {
 			ok, err = s.rotateCaptLogic.CheckData(ctx, req.GetCaptchaKey(), int(angle))
 		}
 		break
 	default:
-		//...
+		err = errcode.ErrCaptchaTypeNotFound
 	}
 
-	if err != nil {
+	// A failed verification is answered with "failure" and its reason
+	if err != nil && !errcode.IsVerifyFailure(err) {
 		s.logger.Warn("[GrpcServer] Failed to check captcha data, err: ", zap.Error(err))
-		return &proto.CheckDataResponse{Code: 1, Message: "failed to check captcha data"}, nil
+		return nil, errcode.FromError(err)
 	}
 
 	if ok {
 		resp.Data = "ok"
 	} else {
 		resp.Data = "failure"
+		resp.Reason = errcode.ProtoReason(errcode.ReasonOf(err))
 	}
 
 	return resp, nil
@@ -156,22 +161,23 @@ func (s *GrpcServer) CheckData(ctx context.Context, req *proto.CheckDataRequest)
 
 // CheckStatus handle
 func (s *GrpcServer) CheckStatus(ctx context.Context, req *proto.StatusInfoRequest) (*proto.StatusInfoResponse, error) {
-	resp := &proto.StatusInfoResponse{Code: 0}
+	resp := &proto.StatusInfoResponse{Code: http.StatusOK, Message: "success"}
 
 	if req.GetCaptchaKey() == "" {
-		return &proto.StatusInfoResponse{Code: 1, Message: "captchaKey is required"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("captchaKey is required")
 	}
 
-	data, err := s.commonLogic.GetStatusInfo(ctx, req.GetCaptchaKey())
-	if err != nil {
+	ok, err := s.commonLogic.CheckStatus(ctx, req.GetCaptchaKey())
+	if err != nil && !errcode.IsVerifyFailure(err) {
 		s.logger.Warn("[GrpcServer] Failed to check status, err: ", zap.Error(err))
-		return &proto.StatusInfoResponse{Code: 1}, nil
+		return nil, errcode.FromError(err)
 	}
 
-	if data != nil && data.Status == 1 {
+	if ok {
 		resp.Data = "ok"
 	} else {
 		resp.Data = "failure"
+		resp.Reason = errcode.ProtoReason(errcode.ReasonOf(err))
 	}
 
 	return resp, nil
@@ -179,22 +185,22 @@ func (s *GrpcServer) CheckStatus(ctx context.Context, req *proto.StatusInfoReque
 
 // GetStatusInfo handle
 func (s *GrpcServer) GetStatusInfo(ctx context.Context, req *proto.StatusInfoRequest) (*proto.StatusInfoResponse, error) {
-	resp := &proto.StatusInfoResponse{Code: 0}
+	resp := &proto.StatusInfoResponse{Code: http.StatusOK, Message: "success"}
 
 	if req.CaptchaKey == "" {
-		return &proto.StatusInfoResponse{Code: 1, Message: "captchaKey is required"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("captchaKey is required")
 	}
 
 	data, err := s.commonLogic.GetStatusInfo(ctx, req.GetCaptchaKey())
 	if err != nil {
-		s.logger.Warn("[GrpcServer] Failed to check status, err: ", zap.Error(err))
-		return &proto.StatusInfoResponse{Code: 1}, nil
+		s.logger.Warn("[GrpcServer] Failed to get status info, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
 	}
 
-	if data != nil && data.Status == 1 {
+	if data != nil {
 		dataByte, err := json.Marshal(data)
 		if err != nil {
-			return nil, fmt.Errorf("failed to json marshal: %v", err)
+			return nil, errcode.ErrInternal.Wrapf("failed to json marshal: %v", err)
 		}
 
 		resp.Data = string(dataByte)
@@ -205,20 +211,22 @@ func (s *GrpcServer) GetStatusInfo(ctx context.Context, req *proto.StatusInfoReq
 
 // DelStatusInfo handle
 func (s *GrpcServer) DelStatusInfo(ctx context.Context, req *proto.StatusInfoRequest) (*proto.StatusInfoResponse, error) {
-	resp := &proto.StatusInfoResponse{Code: 0}
+	resp := &proto.StatusInfoResponse{Code: http.StatusOK, Message: "success"}
 
 	if req.CaptchaKey == "" {
-		return &proto.StatusInfoResponse{Code: 1, Message: "captchaKey is required"}, nil
+		return nil, errcode.ErrInvalidArgument.WithMessage("captchaKey is required")
 	}
 
 	ret, err := s.commonLogic.DelStatusInfo(ctx, req.GetCaptchaKey())
 	if err != nil {
 		s.logger.Warn("[GrpcServer] Failed to delete status info, err: ", zap.Error(err))
-		return &proto.StatusInfoResponse{Code: 1}, nil
+		return nil, errcode.FromError(err)
 	}
 
 	if ret {
 		resp.Data = "ok"
+	} else {
+		resp.Data = "no-ops"
 	}
 
 	return resp, nil
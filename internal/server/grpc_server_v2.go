@@ -0,0 +1,203 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package server
+
+import (
+	"context"
+	"math"
+
+	"github.com/wenlng/go-captcha-service/internal/adapt"
+	"github.com/wenlng/go-captcha-service/internal/common"
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/logic"
+	protov2 "github.com/wenlng/go-captcha-service/proto/v2"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GrpcServerV2 implements the gocaptcha.v2 service, it also backs the /api/v2 HTTP handlers
+type GrpcServerV2 struct {
+	svcCtx *common.SvcContext
+	protov2.UnimplementedGoCaptchaServiceServer
+	dynamicCfg *config.DynamicConfig
+	logger     *zap.Logger
+
+	// Initialize logic
+	clickCaptLogic  *logic.ClickCaptLogic
+	slideCaptLogic  *logic.SlideCaptLogic
+	rotateCaptLogic *logic.RotateCaptLogic
+	commonLogic     *logic.CommonLogic
+}
+
+// NewGoCaptchaServerV2 creates a new gocaptcha.v2 server
+func NewGoCaptchaServerV2(svcCtx *common.SvcContext) *GrpcServerV2 {
+	return &GrpcServerV2{
+		svcCtx:          svcCtx,
+		dynamicCfg:      svcCtx.DynamicConfig,
+		logger:          svcCtx.Logger,
+		clickCaptLogic:  logic.NewClickCaptLogic(svcCtx),
+		slideCaptLogic:  logic.NewSlideCaptLogic(svcCtx),
+		rotateCaptLogic: logic.NewRotateCaptLogic(svcCtx),
+		commonLogic:     logic.NewCommonLogic(svcCtx),
+	}
+}
+
+// GetData handle
+func (s *GrpcServerV2) GetData(ctx context.Context, req *protov2.GetDataRequest) (*protov2.GetDataResponse, error) {
+	id := req.GetId()
+	if id == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
+	}
+
+	var data *adapt.CaptData
+	var err error
+
+	ttype := s.svcCtx.GetCaptcha(ctx).GetCaptTypeWithKey(id)
+	switch ttype {
+	case consts.GoCaptchaTypeClick, consts.GoCaptchaTypeClickShape:
+		data, err = s.clickCaptLogic.GetData(ctx, id)
+	case consts.GoCaptchaTypeSlide, consts.GoCaptchaTypeDrag:
+		data, err = s.slideCaptLogic.GetData(ctx, id)
+	case consts.GoCaptchaTypeRotate:
+		data, err = s.rotateCaptLogic.GetData(ctx, id)
+	default:
+		err = errcode.ErrCaptchaTypeNotFound
+	}
+
+	if err != nil {
+		s.logger.Warn("[GrpcServerV2] Failed to get captcha data, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
+	}
+
+	resp := &protov2.GetDataResponse{
+		Id:         id,
+		CaptchaKey: data.CaptchaKey,
+		Type:       protov2.CaptchaType(ttype),
+		MasterImage: &protov2.Image{
+			Base64: data.MasterImageBase64,
+			Width:  data.MasterWidth,
+			Height: data.MasterHeight,
+		},
+		ThumbImage: &protov2.Image{
+			Base64: data.ThumbImageBase64,
+			Width:  data.ThumbWidth,
+			Height: data.ThumbHeight,
+		},
+		ThumbSize: data.ThumbSize,
+	}
+	if ttype == consts.GoCaptchaTypeSlide || ttype == consts.GoCaptchaTypeDrag {
+		resp.Display = &protov2.Point{X: data.DisplayX, Y: data.DisplayY}
+	}
+	if !data.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(data.ExpiresAt)
+	}
+
+	return resp, nil
+}
+
+// CheckData handle
+func (s *GrpcServerV2) CheckData(ctx context.Context, req *protov2.CheckDataRequest) (*protov2.CheckDataResponse, error) {
+	if req.GetId() == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("missing id parameter")
+	}
+	if req.GetCaptchaKey() == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("captcha_key is required")
+	}
+	if req.GetAnswer() == nil {
+		return nil, errcode.ErrInvalidArgument.WithMessage("answer is required")
+	}
+
+	var ok bool
+	var err error
+
+	errAnswerMismatch := errcode.ErrInvalidArgument.WithMessage("answer does not match the captcha type")
+	switch s.svcCtx.GetCaptcha(ctx).GetCaptTypeWithKey(req.GetId()) {
+	case consts.GoCaptchaTypeClick, consts.GoCaptchaTypeClickShape:
+		answer := req.GetClick()
+		if answer == nil {
+			return nil, errAnswerMismatch
+		}
+		points := make([]adapt.Point, 0, len(answer.GetPoints()))
+		for _, p := range answer.GetPoints() {
+			points = append(points, adapt.Point{X: p.GetX(), Y: p.GetY()})
+		}
+		ok, err = s.clickCaptLogic.CheckAnswer(ctx, req.GetCaptchaKey(), points)
+	case consts.GoCaptchaTypeSlide, consts.GoCaptchaTypeDrag:
+		answer := req.GetSlide()
+		if answer == nil || answer.GetPoint() == nil {
+			return nil, errAnswerMismatch
+		}
+		slideAnswer := &adapt.SlideAnswer{
+			Point: adapt.Point{X: answer.GetPoint().GetX(), Y: answer.GetPoint().GetY()},
+		}
+		for _, p := range answer.GetTrack() {
+			slideAnswer.Track = append(slideAnswer.Track, adapt.TrackPoint{X: p.GetX(), Y: p.GetY(), T: p.GetT()})
+		}
+		ok, err = s.slideCaptLogic.CheckAnswer(ctx, req.GetCaptchaKey(), slideAnswer)
+	case consts.GoCaptchaTypeRotate:
+		answer := req.GetRotate()
+		if answer == nil {
+			return nil, errAnswerMismatch
+		}
+		ok, err = s.rotateCaptLogic.CheckData(ctx, req.GetCaptchaKey(), int(math.Round(float64(answer.GetAngle()))))
+	default:
+		err = errcode.ErrCaptchaTypeNotFound
+	}
+
+	// A failed verification is answered with its reason
+	if err != nil && !errcode.IsVerifyFailure(err) {
+		s.logger.Warn("[GrpcServerV2] Failed to check captcha data, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
+	}
+
+	return &protov2.CheckDataResponse{Ok: ok, Reason: errcode.ProtoReason(errcode.ReasonOf(err))}, nil
+}
+
+// GetStatus handle
+func (s *GrpcServerV2) GetStatus(ctx context.Context, req *protov2.GetStatusRequest) (*protov2.GetStatusResponse, error) {
+	key := req.GetCaptchaKey()
+	if key == "" {
+		return nil, errcode.ErrInvalidArgument.WithMessage("captcha_key is required")
+	}
+
+	resp := &protov2.GetStatusResponse{CaptchaKey: key}
+	if expiresAt, ok := s.commonLogic.ExpiresAt(key); ok {
+		resp.ExpiresAt = timestamppb.New(expiresAt)
+	}
+
+	data, err := s.commonLogic.GetStatusInfo(ctx, key)
+	switch errcode.ReasonOf(err) {
+	case "":
+		resp.Type = protov2.CaptchaType(data.Type)
+		resp.Status = captchaStatusV2(data.Status)
+	case errcode.ReasonCaptchaExpired:
+		resp.Status = protov2.CaptchaStatus_CAPTCHA_STATUS_EXPIRED
+	case errcode.ReasonCaptchaNotFound:
+		resp.Status = protov2.CaptchaStatus_CAPTCHA_STATUS_NOT_FOUND
+		resp.ExpiresAt = nil
+	default:
+		s.logger.Warn("[GrpcServerV2] Failed to get status, err: ", zap.Error(err))
+		return nil, errcode.FromError(err)
+	}
+
+	return resp, nil
+}
+
+// captchaStatusV2 converts the cached captcha status
+func captchaStatusV2(status int) protov2.CaptchaStatus {
+	switch status {
+	case 0:
+		return protov2.CaptchaStatus_CAPTCHA_STATUS_PENDING
+	case 1:
+		return protov2.CaptchaStatus_CAPTCHA_STATUS_VERIFIED
+	case 2:
+		return protov2.CaptchaStatus_CAPTCHA_STATUS_FAILED
+	}
+	return protov2.CaptchaStatus_CAPTCHA_STATUS_UNSPECIFIED
+}
@@ -7,22 +7,29 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/wenlng/go-captcha-service/internal/adapt"
+	"github.com/wenlng/go-captcha-service/internal/audit"
 	"github.com/wenlng/go-captcha-service/internal/common"
 	"github.com/wenlng/go-captcha-service/internal/config"
-	"github.com/wenlng/go-captcha-service/internal/consts"
+	"github.com/wenlng/go-captcha-service/internal/errcode"
+	"github.com/wenlng/go-captcha-service/internal/health"
 	"github.com/wenlng/go-captcha-service/internal/helper"
 	"github.com/wenlng/go-captcha-service/internal/logic"
 	"github.com/wenlng/go-captcha-service/internal/middleware"
 	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"github.com/wenlng/go-captcha-service/internal/tenant"
+	"github.com/wenlng/go-captcha-service/proto"
 	"go.uber.org/zap"
 )
 
-const maxUploadSize = int64(10 << 20) // 10MB
+const maxUploadMemory = int64(10 << 20) // 10MB, the larger parts of the uploads are stored in temporary files
 
 // HTTPHandlers manages HTTP request handlers
 type HTTPHandlers struct {
@@ -30,96 +37,85 @@ type HTTPHandlers struct {
 	dynamicCfg *config.DynamicConfig
 	logger     *zap.Logger
 
+	// The captcha APIs are served by the gRPC implementation
+	rpc *GrpcServer
+
 	// Initialize logic
-	clickCaptLogic  *logic.ClickCaptLogic
-	slideCaptLogic  *logic.SlideCaptLogic
-	rotateCaptLogic *logic.RotateCaptLogic
-	commonLogic     *logic.CommonLogic
-	resourceLogic   *logic.ResourceLogic
+	commonLogic   *logic.CommonLogic
+	resourceLogic *logic.ResourceLogic
 }
 
 // NewHTTPHandlers creates a new HTTP handlers instance
 func NewHTTPHandlers(svcCtx *common.SvcContext) *HTTPHandlers {
 	return &HTTPHandlers{
-		svcCtx:          svcCtx,
-		dynamicCfg:      svcCtx.DynamicConfig,
-		logger:          svcCtx.Logger,
-		clickCaptLogic:  logic.NewClickCaptLogic(svcCtx),
-		slideCaptLogic:  logic.NewSlideCaptLogic(svcCtx),
-		rotateCaptLogic: logic.NewRotateCaptLogic(svcCtx),
-		commonLogic:     logic.NewCommonLogic(svcCtx),
-		resourceLogic:   logic.NewResourceLogic(svcCtx),
+		svcCtx:        svcCtx,
+		dynamicCfg:    svcCtx.DynamicConfig,
+		logger:        svcCtx.Logger,
+		rpc:           NewGoCaptchaServer(svcCtx),
+		commonLogic:   logic.NewCommonLogic(svcCtx),
+		resourceLogic: logic.NewResourceLogic(svcCtx),
 	}
 }
 
 // HealthStatusHandler .
 func (h *HTTPHandlers) HealthStatusHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
-	json.NewEncoder(w).Encode(helper.Marshal(resp))
+	h.ReadinessHandler(w, r)
 }
 
-// GetDataHandler .
-func (h *HTTPHandlers) GetDataHandler(w http.ResponseWriter, r *http.Request) {
+// LivenessHandler reports whether the process is alive
+func (h *HTTPHandlers) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeHealthReport(w, h.svcCtx.HealthChecker.Liveness(r.Context()))
+}
+
+// ReadinessHandler reports whether the instance is ready to receive traffic
+func (h *HTTPHandlers) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeHealthReport(w, h.svcCtx.HealthChecker.Readiness(r.Context()))
+}
+
+// writeHealthReport .
+func (h *HTTPHandlers) writeHealthReport(w http.ResponseWriter, report *health.Report) {
 	w.Header().Set("Content-Type", "application/json")
-	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: ""}
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success", Data: report}
 
-	if r.Method != http.MethodGet {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
+	if !report.Healthy() {
+		h.logger.Warn("[HttpHandler] Health check failed", zap.Any("report", report))
+		resp.Code = http.StatusServiceUnavailable
+		resp.Message = "unhealthy"
+		resp.Reason = string(errcode.ReasonServiceUnavailable)
+		w.WriteHeader(http.StatusServiceUnavailable)
 	}
 
-	query := r.URL.Query()
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
 
-	id := query.Get("id")
-	if id == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "missing id parameter")
+// GetDataHandler .
+func (h *HTTPHandlers) GetDataHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
-	var data *adapt.CaptData
-	var err error
-	switch h.svcCtx.Captcha.GetCaptTypeWithKey(id) {
-	case consts.GoCaptchaTypeClick:
-		data, err = h.clickCaptLogic.GetData(r.Context(), id)
-		break
-	case consts.GoCaptchaTypeClickShape:
-		data, err = h.clickCaptLogic.GetData(r.Context(), id)
-		break
-	case consts.GoCaptchaTypeSlide:
-		data, err = h.slideCaptLogic.GetData(r.Context(), id)
-		break
-	case consts.GoCaptchaTypeDrag:
-		data, err = h.slideCaptLogic.GetData(r.Context(), id)
-		break
-	case consts.GoCaptchaTypeRotate:
-		data, err = h.rotateCaptLogic.GetData(r.Context(), id)
-		break
-	default:
-		//...
-	}
-
-	if err != nil || data == nil {
-		h.logger.Warn("[HttpHandler] Failed to get captcha data, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusNotFound, "captcha type not found")
+	id := r.URL.Query().Get("id")
+	data, err := h.rpc.GetData(r.Context(), &proto.GetDataRequest{Id: id})
+	if err != nil {
+		middleware.WriteAppError(w, err)
 		return
 	}
 
-	resp.Code = http.StatusOK
-	resp.Message = "success"
-
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	resp.Data = &adapt.CaptData{
-		Id:                id,
-		CaptchaKey:        data.CaptchaKey,
-		MasterImageBase64: data.MasterImageBase64,
-		ThumbImageBase64:  data.ThumbImageBase64,
-		MasterWidth:       data.MasterWidth,
-		MasterHeight:      data.MasterHeight,
-		ThumbWidth:        data.ThumbWidth,
-		ThumbHeight:       data.ThumbHeight,
-		ThumbSize:         data.ThumbSize,
-		DisplayX:          data.DisplayX,
-		DisplayY:          data.DisplayY,
+		Id:                data.GetId(),
+		CaptchaKey:        data.GetCaptchaKey(),
+		MasterImageBase64: data.GetMasterImageBase64(),
+		ThumbImageBase64:  data.GetThumbImageBase64(),
+		MasterWidth:       data.GetMasterWidth(),
+		MasterHeight:      data.GetMasterHeight(),
+		ThumbWidth:        data.GetThumbWidth(),
+		ThumbHeight:       data.GetThumbHeight(),
+		ThumbSize:         data.GetThumbSize(),
+		DisplayX:          data.GetDisplayX(),
+		DisplayY:          data.GetDisplayY(),
 	}
 
 	json.NewEncoder(w).Encode(helper.Marshal(resp))
@@ -127,11 +123,8 @@ func (h *HTTPHandlers) GetDataHandler(w http.ResponseWriter, r *http.Request) {
 
 // CheckDataHandler .
 func (h *HTTPHandlers) CheckDataHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: ""}
-
 	if r.Method != http.MethodPost {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
@@ -141,91 +134,39 @@ func (h *HTTPHandlers) CheckDataHandler(w http.ResponseWriter, r *http.Request)
 		Value      string `json:"value"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-	if req.CaptchaKey == "" || req.Value == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "captchaKey and value are required")
-		return
-	}
-
-	if req.Id == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "missing id parameter")
+		middleware.WriteAppError(w, middleware.BodyReadError(err, "invalid request body"))
 		return
 	}
 
-	var ok bool
-	var err error
-
-	switch h.svcCtx.Captcha.GetCaptTypeWithKey(req.Id) {
-	case consts.GoCaptchaTypeClick:
-		ok, err = h.clickCaptLogic.CheckData(r.Context(), req.CaptchaKey, req.Value)
-		break
-	case consts.GoCaptchaTypeClickShape:
-		ok, err = h.clickCaptLogic.CheckData(r.Context(), req.CaptchaKey, req.Value)
-		break
-	case consts.GoCaptchaTypeSlide:
-		ok, err = h.slideCaptLogic.CheckData(r.Context(), req.CaptchaKey, req.Value)
-		break
-	case consts.GoCaptchaTypeDrag:
-		ok, err = h.slideCaptLogic.CheckData(r.Context(), req.CaptchaKey, req.Value)
-		break
-	case consts.GoCaptchaTypeRotate:
-		var angle int64
-		angle, err = strconv.ParseInt(req.Value, 10, 64)
-		if err == nil {
-			ok, err = h.rotateCaptLogic.CheckData(r.Context(), req.CaptchaKey, int(angle))
-		}
-		break
-	default:
-		//...
-	}
-
+	ret, err := h.rpc.CheckData(r.Context(), &proto.CheckDataRequest{Id: req.Id, CaptchaKey: req.CaptchaKey, Value: req.Value})
 	if err != nil {
-		h.logger.Warn("[HttpHandler] Failed to check data, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "failed to check captcha data")
+		middleware.WriteAppError(w, err)
 		return
 	}
-
-	if ok {
-		resp.Data = "ok"
-	} else {
-		resp.Data = "failure"
-	}
-	resp.Code = http.StatusOK
-
-	json.NewEncoder(w).Encode(helper.Marshal(resp))
+	h.writeVerifyResult(w, ret.GetData(), ret.GetReason())
 }
 
 // CheckStatusHandler .
 func (h *HTTPHandlers) CheckStatusHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
-
 	if r.Method != http.MethodGet {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
-	query := r.URL.Query()
-	captchaKey := query.Get("captchaKey")
-	if captchaKey == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "captchaKey is required")
-		return
-	}
-
-	data, err := h.commonLogic.GetStatusInfo(r.Context(), captchaKey)
+	ret, err := h.rpc.CheckStatus(r.Context(), &proto.StatusInfoRequest{CaptchaKey: r.URL.Query().Get("captchaKey")})
 	if err != nil {
-		h.logger.Warn("[HttpHandler] Failed to check status, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "failed to check status")
+		middleware.WriteAppError(w, err)
 		return
 	}
+	h.writeVerifyResult(w, ret.GetData(), ret.GetReason())
+}
 
-	if data != nil && data.Status == 1 {
-		resp.Code = http.StatusOK
-		resp.Data = "ok"
-	} else {
-		resp.Data = "failure"
+// writeVerifyResult writes the "ok" or "failure" result of a verification with the reason of a failure
+func (h *HTTPHandlers) writeVerifyResult(w http.ResponseWriter, data string, reason proto.ErrorReason) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success", Data: data}
+	if reason != proto.ErrorReason_ERROR_REASON_UNSPECIFIED {
+		resp.Reason = reason.String()
 	}
 
 	json.NewEncoder(w).Encode(helper.Marshal(resp))
@@ -236,21 +177,21 @@ func (h *HTTPHandlers) GetStatusInfoHandler(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "application/json")
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	if r.Method != http.MethodGet {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
 	query := r.URL.Query()
 	captchaKey := query.Get("captchaKey")
 	if captchaKey == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "captchaKey is required")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("captchaKey is required"))
 		return
 	}
 
 	data, err := h.commonLogic.GetStatusInfo(r.Context(), captchaKey)
 	if err != nil {
 		h.logger.Warn("[HttpHandler] Failed to get status info, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusNotFound, "not found status info")
+		middleware.WriteAppError(w, err)
 		return
 	}
 
@@ -264,33 +205,19 @@ func (h *HTTPHandlers) GetStatusInfoHandler(w http.ResponseWriter, r *http.Reque
 
 // DelStatusInfoHandler .
 func (h *HTTPHandlers) DelStatusInfoHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	if r.Method != http.MethodDelete {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
-	query := r.URL.Query()
-	captchaKey := query.Get("captchaKey")
-	if captchaKey == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "captchaKey is required")
-		return
-	}
-
-	ret, err := h.commonLogic.DelStatusInfo(r.Context(), captchaKey)
+	ret, err := h.rpc.DelStatusInfo(r.Context(), &proto.StatusInfoRequest{CaptchaKey: r.URL.Query().Get("captchaKey")})
 	if err != nil {
-		h.logger.Warn("[HttpHandler] Failed to del status data, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "not found status info")
+		middleware.WriteAppError(w, err)
 		return
 	}
 
-	if ret {
-		resp.Data = "ok"
-	} else {
-		resp.Data = "no-ops"
-	}
-
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success", Data: ret.GetData()}
 	json.NewEncoder(w).Encode(helper.Marshal(resp))
 }
 
@@ -300,40 +227,38 @@ func (h *HTTPHandlers) UploadResourceHandler(w http.ResponseWriter, r *http.Requ
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 
 	if r.Method != http.MethodPost {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
 	dirname := r.FormValue("dirname")
 	if dirname == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "dirname is required")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("dirname is required"))
 		return
 	}
 
 	if !helper.IsValidDirName(dirname) {
-		middleware.WriteError(w, http.StatusBadRequest, "invalid directory name")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("invalid directory name"))
 		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
-
-	// Parse multipart/form-data
-	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+	// Parse multipart/form-data, the body is limited by the upload-resource body limit
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
 		h.logger.Warn("[HttpHandler] Failed to parse form: %v ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "parse form fail")
+		middleware.WriteAppError(w, middleware.BodyReadError(err, "parse form fail"))
 		return
 	}
 
 	files := r.MultipartForm.File["files"]
 	if len(files) == 0 {
-		middleware.WriteError(w, http.StatusBadRequest, "no files uploaded")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("no files uploaded"))
 		return
 	}
 
 	ret, allDone, err := h.resourceLogic.SaveResource(r.Context(), dirname, files)
-	if !ret && err != nil {
+	if err != nil {
 		h.logger.Warn("[HttpHandler] Failed to save resource, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "save resource fail")
+		middleware.WriteAppError(w, err)
 		return
 	}
 
@@ -354,21 +279,21 @@ func (h *HTTPHandlers) GetResourceListHandler(w http.ResponseWriter, r *http.Req
 	w.Header().Set("Content-Type", "application/json")
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	if r.Method != http.MethodGet {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
 	query := r.URL.Query()
 	resourcePath := query.Get("path")
 	if resourcePath == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "path is required")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("path is required"))
 		return
 	}
 
 	fileList, err := h.resourceLogic.GetResourceList(r.Context(), resourcePath)
 	if err != nil {
 		h.logger.Warn("[HttpHandler] Failed to get resource, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "get resource fail")
+		middleware.WriteAppError(w, err)
 		return
 	}
 
@@ -384,21 +309,21 @@ func (h *HTTPHandlers) DeleteResourceHandler(w http.ResponseWriter, r *http.Requ
 	w.Header().Set("Content-Type", "application/json")
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	if r.Method != http.MethodDelete {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
 	query := r.URL.Query()
 	resourcePath := query.Get("path")
 	if resourcePath == "" {
-		middleware.WriteError(w, http.StatusBadRequest, "path is required")
+		middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("path is required"))
 		return
 	}
 
 	ret, err := h.resourceLogic.DelResource(r.Context(), resourcePath)
 	if err != nil {
 		h.logger.Warn("[HttpHandler] Failed to delete resource, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "delete resource fail")
+		middleware.WriteAppError(w, err)
 		return
 	}
 
@@ -416,11 +341,12 @@ func (h *HTTPHandlers) GetGoCaptchaConfigHandler(w http.ResponseWriter, r *http.
 	w.Header().Set("Content-Type", "application/json")
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
 	if r.Method != http.MethodGet {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
-	resp.Data = h.svcCtx.Captcha.DynamicCnf.Get()
+	audit.SetTarget(r.Context(), captchaConfigTarget(r.Context()))
+	resp.Data = h.svcCtx.GetCaptcha(r.Context()).DynamicCnf.Get()
 	json.NewEncoder(w).Encode(helper.Marshal(resp))
 }
 
@@ -430,25 +356,150 @@ func (h *HTTPHandlers) UpdateHotGoCaptchaConfigHandler(w http.ResponseWriter, r
 	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: ""}
 
 	if r.Method != http.MethodPost {
-		middleware.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
 		return
 	}
 
 	var conf config2.CaptchaConfig
 	if err := json.NewDecoder(r.Body).Decode(&conf); err != nil {
-		middleware.WriteError(w, http.StatusBadRequest, "invalid request body")
+		middleware.WriteAppError(w, middleware.BodyReadError(err, "invalid request body"))
+		return
+	}
+
+	captcha := h.svcCtx.GetWritableCaptcha(r.Context())
+	if captcha == nil {
+		middleware.WriteAppError(w, errcode.ErrForbidden.WithMessage("the tenant shares the default captcha config"))
 		return
 	}
 
-	err := h.svcCtx.Captcha.DynamicCnf.HotUpdate(conf)
+	audit.SetTarget(r.Context(), captchaConfigTarget(r.Context()))
+	before := captcha.DynamicCnf.Get()
+	err := captcha.DynamicCnf.HotUpdate(conf)
+	change := audit.Diff(before, captcha.DynamicCnf.Get())
+	audit.SetChange(r.Context(), change)
 	if err != nil {
 		h.logger.Warn("[HttpHandler] Failed to hot update config, err: ", zap.Error(err))
-		middleware.WriteError(w, http.StatusBadRequest, "hot update config fail")
+		middleware.WriteAppError(w, errcode.ErrConfigInvalid.Wrap(err).WithMessage("hot update config fail"))
 		return
 	}
+	notifyConfigUpdated(r.Context(), h.svcCtx, change)
 
 	resp.Data = "ok"
 	resp.Code = http.StatusOK
 
 	json.NewEncoder(w).Encode(helper.Marshal(resp))
 }
+
+// GetTenantStatsHandler returns the captcha stats of the tenants, a tenant only gets its own
+func (h *HTTPHandlers) GetTenantStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+
+	stats := h.svcCtx.Tenants.Stats()
+	if id := tenant.FromContext(r.Context()); id != "" {
+		stats = map[string]tenant.Stats{id: stats[id]}
+	}
+
+	resp.Data = stats
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
+
+// GetAuditLogHandler returns the audit entries selected by the actor, action, target, result, since, until
+// and limit query parameters, newest first. The audit log is not available to the tenant keys.
+func (h *HTTPHandlers) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+
+	if tenant.FromContext(r.Context()) != "" {
+		middleware.WriteAppError(w, errcode.ErrForbidden.WithMessage("the audit log is not available to tenant keys"))
+		return
+	}
+	if h.svcCtx.Audit == nil {
+		middleware.WriteAppError(w, errcode.ErrNotFound.WithMessage("the audit log is disabled"))
+		return
+	}
+
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Target: query.Get("target"),
+		Result: query.Get("result"),
+	}
+	for name, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := query.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage(name+" must be an RFC 3339 time"))
+				return
+			}
+			*t = parsed
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			middleware.WriteAppError(w, errcode.ErrInvalidArgument.WithMessage("limit must be a positive integer"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.svcCtx.Audit.Query(filter)
+	if err != nil {
+		h.logger.Warn("[HttpHandler] Failed to query the audit log, err: ", zap.Error(err))
+		middleware.WriteAppError(w, errcode.ErrInternal.Wrap(err))
+		return
+	}
+
+	resp.Data = entries
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
+
+// GetWebhookStatusHandler returns the webhook delivery counters and the latest failed attempts.
+// The status is not available to the tenant keys.
+func (h *HTTPHandlers) GetWebhookStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := &adapt.CaptNormalDataResponse{Code: http.StatusOK, Message: "success"}
+	if r.Method != http.MethodGet {
+		middleware.WriteAppError(w, errcode.ErrMethodNotAllowed)
+		return
+	}
+
+	if tenant.FromContext(r.Context()) != "" {
+		middleware.WriteAppError(w, errcode.ErrForbidden.WithMessage("the webhook status is not available to tenant keys"))
+		return
+	}
+
+	resp.Data = h.svcCtx.Webhooks.Stats()
+	json.NewEncoder(w).Encode(helper.Marshal(resp))
+}
+
+// notifyConfigUpdated notifies the webhooks of a captcha config updated by the manage API
+func notifyConfigUpdated(ctx context.Context, svcCtx *common.SvcContext, change *audit.Change) {
+	svcCtx.Webhooks.Emit(config.WebhookEventConfigUpdated, tenant.FromContext(ctx), map[string]any{
+		"target":     captchaConfigTarget(ctx),
+		"source":     "manage-api",
+		"actor":      middleware.APIKeyIDFromContext(ctx),
+		"fields":     change.Fields,
+		"before":     change.Before,
+		"after":      change.After,
+		"request_id": reqinfo.RequestID(ctx),
+	})
+}
+
+// captchaConfigTarget returns the audit target of the captcha config of the request tenant
+func captchaConfigTarget(ctx context.Context) string {
+	if id := tenant.FromContext(ctx); id != "" {
+		return "gocaptcha-config@" + id
+	}
+	return "gocaptcha-config"
+}
@@ -0,0 +1,23 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package tenant
+
+import "context"
+
+// tenantKey .
+type tenantKey struct{}
+
+// WithTenant stores the tenant id of the request in the context
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant id of the request, empty is the default tenant
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
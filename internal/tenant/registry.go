@@ -0,0 +1,202 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package tenant
+
+import (
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/helper"
+	"github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha"
+	config2 "github.com/wenlng/go-captcha-service/internal/pkg/gocaptcha/config"
+)
+
+// Stat events .
+const (
+	StatGenerated = iota
+	StatVerified
+	StatFailed
+)
+
+// Stats is the captcha usage of a tenant
+type Stats struct {
+	Generated uint64 `json:"generated"`
+	Verified  uint64 `json:"verified"`
+	Failed    uint64 `json:"failed"`
+}
+
+// counters .
+type counters struct {
+	generated atomic.Uint64
+	verified  atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// entry .
+type entry struct {
+	spec     config.Tenant
+	captcha  *gocaptcha.GoCaptcha
+	counters *counters
+}
+
+// Registry keeps the captcha instances and the stats of the tenants, tenants are added and removed by Sync
+type Registry struct {
+	logger *zap.Logger
+	queue  *gocaptcha.GenerateQueue
+
+	mu      sync.RWMutex
+	tenants map[string]*entry
+}
+
+// NewRegistry creates a new tenant registry, the tenants share the generation queue
+func NewRegistry(queue *gocaptcha.GenerateQueue, logger *zap.Logger) *Registry {
+	return &Registry{
+		logger:  logger,
+		queue:   queue,
+		tenants: make(map[string]*entry),
+	}
+}
+
+// ResourceDir returns the absolute resource directory of the tenant
+func ResourceDir(t config.Tenant) string {
+	return path.Join(helper.GetResourceDirAbsPath(), t.ResourceDir)
+}
+
+// Sync adds the new tenants of the config, reloads the changed ones and removes the others.
+// A tenant whose captcha config fails to load keeps its previous instances.
+func (r *Registry) Sync(cfg config.Config) {
+	r.mu.RLock()
+	current := make(map[string]*entry, len(r.tenants))
+	for id, e := range r.tenants {
+		current[id] = e
+	}
+	r.mu.RUnlock()
+
+	next := make(map[string]*entry, len(cfg.Tenants))
+	for id := range cfg.Tenants {
+		spec, _ := cfg.GetTenant(id)
+		old, exists := current[id]
+		if exists && old.spec.CaptchaConfigFile == spec.CaptchaConfigFile && old.spec.ResourceDir == spec.ResourceDir {
+			next[id] = &entry{spec: spec, captcha: old.captcha, counters: old.counters}
+			continue
+		}
+
+		e := &entry{spec: spec, counters: &counters{}}
+		if exists {
+			e.counters = old.counters
+		}
+		if spec.CaptchaConfigFile != "" {
+			gc, err := r.setupCaptcha(spec)
+			if err != nil {
+				r.logger.Error("[Tenant] Failed to setup the captcha of the tenant", zap.String("tenant", id), zap.Error(err))
+				if exists {
+					e.captcha = old.captcha
+				}
+			} else {
+				e.captcha = gc
+			}
+		}
+		next[id] = e
+
+		if exists {
+			r.logger.Info("[Tenant] Tenant reloaded", zap.String("tenant", id))
+		} else {
+			r.logger.Info("[Tenant] Tenant added", zap.String("tenant", id))
+		}
+	}
+	for id := range current {
+		if _, ok := next[id]; !ok {
+			r.logger.Info("[Tenant] Tenant removed", zap.String("tenant", id))
+		}
+	}
+
+	r.mu.Lock()
+	r.tenants = next
+	r.mu.Unlock()
+}
+
+// setupCaptcha loads the captcha config of the tenant and its resources
+func (r *Registry) setupCaptcha(spec config.Tenant) (*gocaptcha.GoCaptcha, error) {
+	dgc, err := config2.NewDynamicConfig(spec.CaptchaConfigFile, false)
+	if err != nil {
+		return nil, err
+	}
+	dgc.SetResourceDir(ResourceDir(spec))
+	if err = config2.Validate(dgc.Get()); err != nil {
+		return nil, err
+	}
+
+	gc, err := gocaptcha.Setup(dgc)
+	if err != nil {
+		return nil, err
+	}
+	gc.GenerateQueue = r.queue
+
+	dgc.RegisterHotCallback("GENERATE_CAPTCHA", func(captchaConfig *config2.DynamicCaptchaConfig, callbackType config2.HotCallbackType) {
+		if err := gc.HotSetup(captchaConfig); err != nil {
+			r.logger.Error("[Tenant] Failed to hot update gocaptcha, without any change: ", zap.Error(err))
+		}
+	})
+	return gc, nil
+}
+
+// Captcha returns the captcha instances of the tenant, nil when the tenant shares the default ones
+func (r *Registry) Captcha(id string) *gocaptcha.GoCaptcha {
+	if r == nil || id == "" {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if e, ok := r.tenants[id]; ok {
+		return e.captcha
+	}
+	return nil
+}
+
+// Record counts a stat event of the tenant
+func (r *Registry) Record(id string, event int) {
+	if r == nil || id == "" {
+		return
+	}
+	r.mu.RLock()
+	e, ok := r.tenants[id]
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	switch event {
+	case StatGenerated:
+		e.counters.generated.Add(1)
+	case StatVerified:
+		e.counters.verified.Add(1)
+	case StatFailed:
+		e.counters.failed.Add(1)
+	}
+}
+
+// Stats returns the stats of every tenant
+func (r *Registry) Stats() map[string]Stats {
+	if r == nil {
+		return map[string]Stats{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make(map[string]Stats, len(r.tenants))
+	for id, e := range r.tenants {
+		stats[id] = Stats{
+			Generated: e.counters.generated.Load(),
+			Verified:  e.counters.verified.Load(),
+			Failed:    e.counters.failed.Load(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,220 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+// Reloader serves the certificate and the client CAs of the servers, they are reloaded when the files change on disk
+type Reloader struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	clientAuth tls.ClientAuthType
+	minVersion uint16
+	logger     *zap.Logger
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+
+	watcher *fsnotify.Watcher
+}
+
+// Options are the files and the handshake policy of a listener
+type Options struct {
+	CertFile   string
+	KeyFile    string
+	CaFile     string
+	ClientAuth tls.ClientAuthType
+	MinVersion uint16
+}
+
+// ServerOptions returns the options of the public HTTP and gRPC servers
+func ServerOptions(cfg config.Config) Options {
+	return Options{
+		CertFile:   cfg.TlsCertFile,
+		KeyFile:    cfg.TlsKeyFile,
+		CaFile:     cfg.TlsCaFile,
+		ClientAuth: ClientAuthType(cfg.TlsClientAuth),
+		MinVersion: MinVersion(cfg.TlsMinVersion),
+	}
+}
+
+// AdminOptions returns the options of the admin listener, every client must present a certificate when the CA is set
+func AdminOptions(admin config.AdminListener) Options {
+	opts := Options{
+		CertFile:   admin.TlsCertFile,
+		KeyFile:    admin.TlsKeyFile,
+		CaFile:     admin.TlsCaFile,
+		ClientAuth: tls.NoClientCert,
+		MinVersion: MinVersion(admin.TlsMinVersion),
+	}
+	if admin.TlsCaFile != "" {
+		opts.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return opts
+}
+
+// NewReloader loads the certificate and the client CAs of the options
+func NewReloader(opts Options, logger *zap.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile:   opts.CertFile,
+		keyFile:    opts.KeyFile,
+		caFile:     opts.CaFile,
+		clientAuth: opts.ClientAuth,
+		minVersion: opts.MinVersion,
+		logger:     logger,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ClientAuthType returns the client certificate policy of the handshake.
+// In the manage mode certificates are verified when given and required by the middlewares of the manage routes.
+func ClientAuthType(mode string) tls.ClientAuthType {
+	switch mode {
+	case config.TlsClientAuthManage:
+		return tls.VerifyClientCertIfGiven
+	case config.TlsClientAuthAll:
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.NoClientCert
+}
+
+// MinVersion returns the TLS version of the setting, default TLS 1.2
+func MinVersion(version string) uint16 {
+	if version == config.TlsVersion13 {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// Reload loads the files again, the previous certificate is kept when they are invalid
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		caPEM, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS CA file: %v", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("no certificate found in TLS CA file: %s", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = pool
+	r.mu.Unlock()
+	return nil
+}
+
+// ServerConfig returns the TLS config of a server advertising the protocols, every handshake uses the current certificate
+func (r *Reloader) ServerConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   r.minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    r.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// Watch reloads the files when they change, the directories are watched to follow the atomic replacements of mounted secrets
+func (r *Reloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %v", err)
+	}
+
+	files := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		files[absPath] = true
+		dirs[filepath.Dir(absPath)] = true
+	}
+	for dir := range dirs {
+		if err = watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch directory: %v", err)
+		}
+	}
+	r.watcher = watcher
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// Mounted secrets are swapped through the ..data symlink
+				if !files[event.Name] && filepath.Base(event.Name) != "..data" {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				if err := r.Reload(); err != nil {
+					r.logger.Warn("[TLS] Failed to reload the certificate, keeping the previous one", zap.String("cert", r.certFile), zap.Error(err))
+					continue
+				}
+				r.logger.Info("[TLS] Certificate reloaded", zap.String("cert", r.certFile))
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.logger.Error("[TLS] Failed to watch the certificate files", zap.Error(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops watching the files
+func (r *Reloader) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+	return r.watcher.Close()
+}
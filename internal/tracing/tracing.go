@@ -0,0 +1,142 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/reqinfo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer used by the service
+const InstrumentationName = "github.com/wenlng/go-captcha-service"
+
+// Params ..
+type Params struct {
+	ServiceName string
+	ServiceNode int64
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	Headers     map[string]string
+	SampleRatio float64
+	FilePath    string
+}
+
+// Provider wraps the tracer provider and the resources held by the exporter
+type Provider struct {
+	tp   *sdktrace.TracerProvider
+	file *os.File
+}
+
+// NewProvider creates a tracer provider and registers it as the global provider
+func NewProvider(ctx context.Context, arg *Params) (*Provider, error) {
+	p := &Provider{}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch arg.Exporter {
+	case config.TracingExporterOTLPGrpc:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(arg.Endpoint)}
+		if arg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(arg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(arg.Headers))
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case config.TracingExporterOTLPHttp:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(arg.Endpoint)}
+		if arg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(arg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(arg.Headers))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.TracingExporterFile:
+		if arg.FilePath == "" {
+			return nil, fmt.Errorf("tracing file path is required for the file exporter")
+		}
+		p.file, err = os.OpenFile(arg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tracing file: %v", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(p.file))
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter: %v", arg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing exporter: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(arg.ServiceName),
+		semconv.ServiceInstanceID(strconv.FormatInt(arg.ServiceNode, 10)),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %v", err)
+	}
+
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(arg.SampleRatio))
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+
+	otel.SetTracerProvider(p.tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return p, nil
+}
+
+// Shutdown flushes the pending spans and stops the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.tp.Shutdown(ctx)
+	if p.file != nil {
+		if cErr := p.file.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+// Start creates a span from the global tracer, the span carries the request id of ctx
+func Start(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if id := reqinfo.RequestID(ctx); id != "" {
+		attrs = append(attrs, attribute.String("request.id", id))
+	}
+	return otel.Tracer(InstrumentationName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// End records the error if any and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
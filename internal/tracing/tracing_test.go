@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+func TestFileExporter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "trace.json")
+	p, err := NewProvider(context.Background(), &Params{
+		ServiceName: "test",
+		ServiceNode: 1,
+		Exporter:    config.TracingExporterFile,
+		SampleRatio: 1,
+		FilePath:    file,
+	})
+	assert.NoError(t, err)
+
+	ctx, span := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(span, nil)
+
+	assert.NoError(t, p.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"parent"`)
+	assert.Contains(t, string(data), `"Name":"child"`)
+	assert.Contains(t, string(data), "boom")
+}
+
+func TestInvalidExporter(t *testing.T) {
+	_, err := NewProvider(context.Background(), &Params{Exporter: "unknown"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,445 @@
+/**
+ * @Author Awen
+ * @Date 2025/04/04
+ * @Email wengaolng@gmail.com
+ **/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+	"github.com/wenlng/go-captcha-service/internal/helper"
+)
+
+// Webhook request headers
+const (
+	IDHeader        = "X-Webhook-Id"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// maxFailures is the number of failed attempts kept for the status endpoint
+	maxFailures = 100
+	// deliveryFileExt is the extension of the pending delivery files in the queue directory
+	deliveryFileExt = ".json"
+)
+
+// Event is the body of a webhook request
+type Event struct {
+	ID     string         `json:"id"`
+	Type   string         `json:"type"`
+	Time   time.Time      `json:"time"`
+	Tenant string         `json:"tenant,omitempty"`
+	Data   map[string]any `json:"data"`
+}
+
+// delivery is an event pending for an endpoint, it is stored in the queue directory until it is delivered or given up
+type delivery struct {
+	ID          string    `json:"id"`
+	EndpointID  string    `json:"endpoint_id"`
+	Event       Event     `json:"event"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// Failure is a failed delivery attempt
+type Failure struct {
+	Time       time.Time `json:"time"`
+	EndpointID string    `json:"endpoint_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	Status     int       `json:"status,omitempty"` // HTTP status of the endpoint, 0 when the request failed
+	Error      string    `json:"error"`
+	Final      bool      `json:"final"` // no attempt left, the delivery is given up
+}
+
+// Stats are the delivery counters and the latest failed attempts, newest first
+type Stats struct {
+	Pending   int       `json:"pending"`
+	Delivered int64     `json:"delivered"`
+	Failed    int64     `json:"failed"`  // deliveries given up
+	Dropped   int64     `json:"dropped"` // events dropped by a full queue
+	Failures  []Failure `json:"failures"`
+}
+
+// Sign returns the hex HMAC-SHA256 signature of a webhook request.
+// The signed string is the event id, the timestamp and the body joined by newlines.
+func Sign(secret, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id + "\n" + timestamp + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers the events to the webhook endpoints in the background.
+// The pending deliveries are bounded and stored in the queue directory, they are resumed after a restart.
+// A nil Dispatcher drops the events.
+type Dispatcher struct {
+	settings config.Webhooks
+	client   *http.Client
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	endpoints map[string]config.WebhookEndpoint
+	pending   int
+	delivered int64
+	failed    int64
+	dropped   int64
+	failures  []Failure
+
+	// incoming holds the emitted deliveries until they are stored, ready the ones due for an attempt
+	incoming chan *delivery
+	ready    chan *delivery
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New starts the dispatcher of the settings and resumes the deliveries stored in the queue directory
+func New(cfg config.Config, logger *zap.Logger) (*Dispatcher, error) {
+	settings := cfg.GetWebhooks()
+	if err := os.MkdirAll(settings.QueueDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the webhook queue directory: %v", err)
+	}
+
+	d := &Dispatcher{
+		settings: settings,
+		client:   &http.Client{Timeout: time.Duration(settings.Timeout) * time.Second},
+		logger:   logger,
+		incoming: make(chan *delivery, settings.QueueSize),
+		ready:    make(chan *delivery, settings.QueueSize),
+		stop:     make(chan struct{}),
+	}
+	d.Update(cfg)
+
+	if err := d.resume(); err != nil {
+		return nil, err
+	}
+
+	d.wg.Add(1)
+	go d.store()
+	for i := 0; i < settings.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d, nil
+}
+
+// Update replaces the endpoints, the pending deliveries of the removed endpoints are given up
+func (d *Dispatcher) Update(cfg config.Config) {
+	if d == nil {
+		return
+	}
+	endpoints := make(map[string]config.WebhookEndpoint, len(cfg.Webhooks.Endpoints))
+	for _, endpoint := range cfg.Webhooks.Endpoints {
+		endpoints[endpoint.ID] = endpoint
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.endpoints = endpoints
+}
+
+// Emit queues the event for the endpoints receiving its type without blocking,
+// the event is dropped when the queue is full
+func (d *Dispatcher) Emit(eventType, tenant string, data map[string]any) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	var endpointIDs []string
+	for id, endpoint := range d.endpoints {
+		if endpoint.Accepts(eventType) {
+			endpointIDs = append(endpointIDs, id)
+		}
+	}
+	d.mu.Unlock()
+	if len(endpointIDs) == 0 {
+		return
+	}
+
+	event := Event{ID: helper.GenerateID(), Type: eventType, Time: time.Now(), Tenant: tenant, Data: data}
+	for _, id := range endpointIDs {
+		d.mu.Lock()
+		full := d.pending >= d.settings.QueueSize
+		if full {
+			d.dropped++
+		} else {
+			d.pending++
+		}
+		d.mu.Unlock()
+
+		if full {
+			d.logger.Warn("[Webhook] Queue is full, the event is dropped",
+				zap.String("endpoint", id), zap.String("event", eventType))
+			continue
+		}
+		// The pending deliveries never exceed the channel capacity
+		d.incoming <- &delivery{ID: helper.GenerateID(), EndpointID: id, Event: event, NextAttempt: event.Time}
+	}
+}
+
+// Stats returns the delivery counters and the latest failed attempts
+func (d *Dispatcher) Stats() Stats {
+	if d == nil {
+		return Stats{Failures: []Failure{}}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	failures := make([]Failure, 0, len(d.failures))
+	for i := len(d.failures) - 1; i >= 0; i-- {
+		failures = append(failures, d.failures[i])
+	}
+	return Stats{
+		Pending:   d.pending,
+		Delivered: d.delivered,
+		Failed:    d.failed,
+		Dropped:   d.dropped,
+		Failures:  failures,
+	}
+}
+
+// Close stops the deliveries, the pending ones stay in the queue directory
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	close(d.stop)
+	d.wg.Wait()
+}
+
+// resume schedules the deliveries stored in the queue directory, the ones over the queue size are dropped
+func (d *Dispatcher) resume() error {
+	entries, err := os.ReadDir(d.settings.QueueDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the webhook queue directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		name := filepath.Join(d.settings.QueueDir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(name, deliveryFileExt) {
+			// Deliveries cut while being stored
+			if strings.HasSuffix(name, deliveryFileExt+".tmp") {
+				os.Remove(name)
+			}
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read the webhook delivery %s: %v", name, err)
+		}
+
+		var dl delivery
+		if err = json.Unmarshal(data, &dl); err != nil || dl.ID == "" || d.pending >= d.settings.QueueSize {
+			d.dropped++
+			os.Remove(name)
+			continue
+		}
+		d.pending++
+		d.schedule(&dl)
+	}
+	return nil
+}
+
+// store writes the emitted deliveries to the queue directory and schedules them
+func (d *Dispatcher) store() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case dl := <-d.incoming:
+			d.save(dl)
+			d.schedule(dl)
+		}
+	}
+}
+
+// work delivers the due deliveries
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case dl := <-d.ready:
+			d.attempt(dl)
+		}
+	}
+}
+
+// schedule makes the delivery ready at its next attempt time
+func (d *Dispatcher) schedule(dl *delivery) {
+	enqueue := func() {
+		select {
+		case d.ready <- dl:
+		case <-d.stop:
+		}
+	}
+
+	delay := time.Until(dl.NextAttempt)
+	if delay <= 0 {
+		enqueue()
+		return
+	}
+	time.AfterFunc(delay, enqueue)
+}
+
+// attempt sends the delivery, it is retried with an exponential backoff until the attempts run out
+func (d *Dispatcher) attempt(dl *delivery) {
+	d.mu.Lock()
+	endpoint, ok := d.endpoints[dl.EndpointID]
+	d.mu.Unlock()
+
+	dl.Attempts++
+	var status int
+	var err error
+	if !ok {
+		err = fmt.Errorf("endpoint removed")
+	} else {
+		status, err = d.send(endpoint, dl.Event)
+	}
+
+	if err == nil {
+		d.remove(dl)
+		d.mu.Lock()
+		d.pending--
+		d.delivered++
+		d.mu.Unlock()
+		return
+	}
+
+	final := !ok || dl.Attempts >= d.settings.MaxAttempts
+	d.mu.Lock()
+	d.failures = append(d.failures, Failure{
+		Time:       time.Now(),
+		EndpointID: dl.EndpointID,
+		EventID:    dl.Event.ID,
+		EventType:  dl.Event.Type,
+		Attempt:    dl.Attempts,
+		Status:     status,
+		Error:      err.Error(),
+		Final:      final,
+	})
+	if len(d.failures) > maxFailures {
+		d.failures = d.failures[len(d.failures)-maxFailures:]
+	}
+	if final {
+		d.pending--
+		d.failed++
+	}
+	d.mu.Unlock()
+
+	if final {
+		d.logger.Warn("[Webhook] Delivery given up",
+			zap.String("endpoint", dl.EndpointID),
+			zap.String("event", dl.Event.Type),
+			zap.Int("attempts", dl.Attempts),
+			zap.Error(err),
+		)
+		d.remove(dl)
+		return
+	}
+
+	dl.NextAttempt = time.Now().Add(d.backoff(dl.Attempts))
+	d.save(dl)
+	d.schedule(dl)
+}
+
+// backoff returns the delay before the next attempt, doubled after each failure
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := time.Duration(d.settings.InitialBackoff) * time.Second
+	maxDelay := time.Duration(d.settings.MaxBackoff) * time.Second
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// send posts the event to the endpoint, the responses other than 2xx are failures
+func (d *Dispatcher) send(endpoint config.WebhookEndpoint, event Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, event.ID)
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(TimestampHeader, timestamp)
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, event.ID, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// save writes the delivery to the queue directory, the delivery is still attempted when it cannot be stored
+func (d *Dispatcher) save(dl *delivery) {
+	data, err := json.Marshal(dl)
+	if err == nil {
+		name := d.deliveryFile(dl)
+		tmp := name + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, name)
+		}
+	}
+	if err != nil {
+		d.logger.Error("[Webhook] Failed to store the delivery", zap.String("endpoint", dl.EndpointID), zap.Error(err))
+	}
+}
+
+// remove deletes the delivery from the queue directory
+func (d *Dispatcher) remove(dl *delivery) {
+	if err := os.Remove(d.deliveryFile(dl)); err != nil && !os.IsNotExist(err) {
+		d.logger.Error("[Webhook] Failed to remove the delivery", zap.String("endpoint", dl.EndpointID), zap.Error(err))
+	}
+}
+
+// deliveryFile returns the path of the delivery in the queue directory
+func (d *Dispatcher) deliveryFile(dl *delivery) string {
+	return filepath.Join(d.settings.QueueDir, dl.ID+deliveryFileExt)
+}
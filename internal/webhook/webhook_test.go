@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/wenlng/go-captcha-service/internal/config"
+)
+
+func newTestConfig(t *testing.T, endpoints ...config.WebhookEndpoint) config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Webhooks.QueueDir = t.TempDir()
+	cfg.Webhooks.Endpoints = endpoints
+	return cfg
+}
+
+func TestDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t,
+		config.WebhookEndpoint{ID: "fraud", URL: server.URL, Secret: "secret", Events: []string{config.WebhookEventCaptchaFailed}},
+		config.WebhookEndpoint{ID: "ops", URL: server.URL},
+	)
+	d, err := New(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	defer d.Close()
+
+	d.Emit(config.WebhookEventCaptchaFailed, "acme", map[string]any{"captcha_key": "k"})
+	d.Emit(config.WebhookEventCaptchaVerified, "", map[string]any{"captcha_key": "k"})
+
+	assert.Eventually(t, func() bool { return d.Stats().Delivered == 3 }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, d.Stats().Pending)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var signed int
+	for i, r := range received {
+		if sig := r.Header.Get(SignatureHeader); sig != "" {
+			signed++
+			assert.Equal(t, config.WebhookEventCaptchaFailed, r.Header.Get(EventHeader))
+			assert.Equal(t, Sign("secret", r.Header.Get(IDHeader), r.Header.Get(TimestampHeader), bodies[i]), sig)
+		}
+	}
+	assert.Equal(t, 1, signed)
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, config.WebhookEndpoint{ID: "ops", URL: server.URL})
+	d, err := New(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	defer d.Close()
+
+	d.Emit(config.WebhookEventConfigUpdated, "", map[string]any{})
+	assert.Eventually(t, func() bool { return d.Stats().Delivered == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	stats := d.Stats()
+	if assert.Len(t, stats.Failures, 1) {
+		assert.Equal(t, http.StatusInternalServerError, stats.Failures[0].Status)
+		assert.False(t, stats.Failures[0].Final)
+	}
+}
+
+func TestDispatcherGiveUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, config.WebhookEndpoint{ID: "ops", URL: server.URL})
+	cfg.Webhooks.MaxAttempts = 1
+	d, err := New(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	defer d.Close()
+
+	d.Emit(config.WebhookEventResourceDeleted, "", map[string]any{})
+	assert.Eventually(t, func() bool { return d.Stats().Failed == 1 }, 3*time.Second, 10*time.Millisecond)
+	assert.True(t, d.Stats().Failures[0].Final)
+
+	entries, _ := os.ReadDir(cfg.Webhooks.QueueDir)
+	assert.Empty(t, entries)
+}
+
+func TestDispatcherResume(t *testing.T) {
+	var ok atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ok.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(t, config.WebhookEndpoint{ID: "ops", URL: server.URL})
+	cfg.Webhooks.QueueSize = 1
+	d, err := New(cfg, zap.NewNop())
+	assert.NoError(t, err)
+
+	d.Emit(config.WebhookEventResourceUploaded, "", map[string]any{})
+	// The queue is full
+	d.Emit(config.WebhookEventResourceUploaded, "", map[string]any{})
+	assert.Eventually(t, func() bool { return len(d.Stats().Failures) == 1 }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), d.Stats().Dropped)
+	d.Close()
+
+	// The pending delivery is resumed after a restart
+	ok.Store(true)
+	d, err = New(cfg, zap.NewNop())
+	assert.NoError(t, err)
+	defer d.Close()
+	assert.Equal(t, 1, d.Stats().Pending)
+	assert.Eventually(t, func() bool { return d.Stats().Delivered == 1 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.3.0
-// - protoc             v4.22.2
+// - protoc             (unknown)
 // source: proto/api.proto
 
 package proto
@@ -255,3 +255,277 @@ var GoCaptchaService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/api.proto",
 }
+
+const (
+	GoCaptchaManageService_UploadResource_FullMethodName  = "/gocaptcha.GoCaptchaManageService/UploadResource"
+	GoCaptchaManageService_DeleteResource_FullMethodName  = "/gocaptcha.GoCaptchaManageService/DeleteResource"
+	GoCaptchaManageService_GetResourceList_FullMethodName = "/gocaptcha.GoCaptchaManageService/GetResourceList"
+	GoCaptchaManageService_GetConfig_FullMethodName       = "/gocaptcha.GoCaptchaManageService/GetConfig"
+	GoCaptchaManageService_UpdateHotConfig_FullMethodName = "/gocaptcha.GoCaptchaManageService/UpdateHotConfig"
+)
+
+// GoCaptchaManageServiceClient is the client API for GoCaptchaManageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GoCaptchaManageServiceClient interface {
+	UploadResource(ctx context.Context, opts ...grpc.CallOption) (GoCaptchaManageService_UploadResourceClient, error)
+	DeleteResource(ctx context.Context, in *ResourcePathRequest, opts ...grpc.CallOption) (*ManageResponse, error)
+	GetResourceList(ctx context.Context, in *ResourcePathRequest, opts ...grpc.CallOption) (*ResourceListResponse, error)
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ManageResponse, error)
+	UpdateHotConfig(ctx context.Context, in *UpdateHotConfigRequest, opts ...grpc.CallOption) (*ManageResponse, error)
+}
+
+type goCaptchaManageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoCaptchaManageServiceClient(cc grpc.ClientConnInterface) GoCaptchaManageServiceClient {
+	return &goCaptchaManageServiceClient{cc}
+}
+
+func (c *goCaptchaManageServiceClient) UploadResource(ctx context.Context, opts ...grpc.CallOption) (GoCaptchaManageService_UploadResourceClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GoCaptchaManageService_ServiceDesc.Streams[0], GoCaptchaManageService_UploadResource_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &goCaptchaManageServiceUploadResourceClient{stream}
+	return x, nil
+}
+
+type GoCaptchaManageService_UploadResourceClient interface {
+	Send(*UploadResourceRequest) error
+	CloseAndRecv() (*ManageResponse, error)
+	grpc.ClientStream
+}
+
+type goCaptchaManageServiceUploadResourceClient struct {
+	grpc.ClientStream
+}
+
+func (x *goCaptchaManageServiceUploadResourceClient) Send(m *UploadResourceRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *goCaptchaManageServiceUploadResourceClient) CloseAndRecv() (*ManageResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ManageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *goCaptchaManageServiceClient) DeleteResource(ctx context.Context, in *ResourcePathRequest, opts ...grpc.CallOption) (*ManageResponse, error) {
+	out := new(ManageResponse)
+	err := c.cc.Invoke(ctx, GoCaptchaManageService_DeleteResource_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goCaptchaManageServiceClient) GetResourceList(ctx context.Context, in *ResourcePathRequest, opts ...grpc.CallOption) (*ResourceListResponse, error) {
+	out := new(ResourceListResponse)
+	err := c.cc.Invoke(ctx, GoCaptchaManageService_GetResourceList_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goCaptchaManageServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*ManageResponse, error) {
+	out := new(ManageResponse)
+	err := c.cc.Invoke(ctx, GoCaptchaManageService_GetConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goCaptchaManageServiceClient) UpdateHotConfig(ctx context.Context, in *UpdateHotConfigRequest, opts ...grpc.CallOption) (*ManageResponse, error) {
+	out := new(ManageResponse)
+	err := c.cc.Invoke(ctx, GoCaptchaManageService_UpdateHotConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GoCaptchaManageServiceServer is the server API for GoCaptchaManageService service.
+// All implementations must embed UnimplementedGoCaptchaManageServiceServer
+// for forward compatibility
+type GoCaptchaManageServiceServer interface {
+	UploadResource(GoCaptchaManageService_UploadResourceServer) error
+	DeleteResource(context.Context, *ResourcePathRequest) (*ManageResponse, error)
+	GetResourceList(context.Context, *ResourcePathRequest) (*ResourceListResponse, error)
+	GetConfig(context.Context, *GetConfigRequest) (*ManageResponse, error)
+	UpdateHotConfig(context.Context, *UpdateHotConfigRequest) (*ManageResponse, error)
+	mustEmbedUnimplementedGoCaptchaManageServiceServer()
+}
+
+// UnimplementedGoCaptchaManageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedGoCaptchaManageServiceServer struct {
+}
+
+func (UnimplementedGoCaptchaManageServiceServer) UploadResource(GoCaptchaManageService_UploadResourceServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadResource not implemented")
+}
+func (UnimplementedGoCaptchaManageServiceServer) DeleteResource(context.Context, *ResourcePathRequest) (*ManageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteResource not implemented")
+}
+func (UnimplementedGoCaptchaManageServiceServer) GetResourceList(context.Context, *ResourcePathRequest) (*ResourceListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResourceList not implemented")
+}
+func (UnimplementedGoCaptchaManageServiceServer) GetConfig(context.Context, *GetConfigRequest) (*ManageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedGoCaptchaManageServiceServer) UpdateHotConfig(context.Context, *UpdateHotConfigRequest) (*ManageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateHotConfig not implemented")
+}
+func (UnimplementedGoCaptchaManageServiceServer) mustEmbedUnimplementedGoCaptchaManageServiceServer() {
+}
+
+// UnsafeGoCaptchaManageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoCaptchaManageServiceServer will
+// result in compilation errors.
+type UnsafeGoCaptchaManageServiceServer interface {
+	mustEmbedUnimplementedGoCaptchaManageServiceServer()
+}
+
+func RegisterGoCaptchaManageServiceServer(s grpc.ServiceRegistrar, srv GoCaptchaManageServiceServer) {
+	s.RegisterService(&GoCaptchaManageService_ServiceDesc, srv)
+}
+
+func _GoCaptchaManageService_UploadResource_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GoCaptchaManageServiceServer).UploadResource(&goCaptchaManageServiceUploadResourceServer{stream})
+}
+
+type GoCaptchaManageService_UploadResourceServer interface {
+	SendAndClose(*ManageResponse) error
+	Recv() (*UploadResourceRequest, error)
+	grpc.ServerStream
+}
+
+type goCaptchaManageServiceUploadResourceServer struct {
+	grpc.ServerStream
+}
+
+func (x *goCaptchaManageServiceUploadResourceServer) SendAndClose(m *ManageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *goCaptchaManageServiceUploadResourceServer) Recv() (*UploadResourceRequest, error) {
+	m := new(UploadResourceRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _GoCaptchaManageService_DeleteResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourcePathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoCaptchaManageServiceServer).DeleteResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoCaptchaManageService_DeleteResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoCaptchaManageServiceServer).DeleteResource(ctx, req.(*ResourcePathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoCaptchaManageService_GetResourceList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourcePathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoCaptchaManageServiceServer).GetResourceList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoCaptchaManageService_GetResourceList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoCaptchaManageServiceServer).GetResourceList(ctx, req.(*ResourcePathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoCaptchaManageService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoCaptchaManageServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoCaptchaManageService_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoCaptchaManageServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoCaptchaManageService_UpdateHotConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateHotConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoCaptchaManageServiceServer).UpdateHotConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoCaptchaManageService_UpdateHotConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoCaptchaManageServiceServer).UpdateHotConfig(ctx, req.(*UpdateHotConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GoCaptchaManageService_ServiceDesc is the grpc.ServiceDesc for GoCaptchaManageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoCaptchaManageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gocaptcha.GoCaptchaManageService",
+	HandlerType: (*GoCaptchaManageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DeleteResource",
+			Handler:    _GoCaptchaManageService_DeleteResource_Handler,
+		},
+		{
+			MethodName: "GetResourceList",
+			Handler:    _GoCaptchaManageService_GetResourceList_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _GoCaptchaManageService_GetConfig_Handler,
+		},
+		{
+			MethodName: "UpdateHotConfig",
+			Handler:    _GoCaptchaManageService_UpdateHotConfig_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadResource",
+			Handler:       _GoCaptchaManageService_UploadResource_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/api.proto",
+}
@@ -0,0 +1,1287 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: proto/v2/api.proto
+
+package protov2
+
+import (
+	proto "github.com/wenlng/go-captcha-service/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CaptchaType int32
+
+const (
+	CaptchaType_CAPTCHA_TYPE_UNSPECIFIED CaptchaType = 0
+	CaptchaType_CAPTCHA_TYPE_CLICK       CaptchaType = 1
+	CaptchaType_CAPTCHA_TYPE_CLICK_SHAPE CaptchaType = 2
+	CaptchaType_CAPTCHA_TYPE_SLIDE       CaptchaType = 3
+	CaptchaType_CAPTCHA_TYPE_DRAG        CaptchaType = 4
+	CaptchaType_CAPTCHA_TYPE_ROTATE      CaptchaType = 5
+)
+
+// Enum value maps for CaptchaType.
+var (
+	CaptchaType_name = map[int32]string{
+		0: "CAPTCHA_TYPE_UNSPECIFIED",
+		1: "CAPTCHA_TYPE_CLICK",
+		2: "CAPTCHA_TYPE_CLICK_SHAPE",
+		3: "CAPTCHA_TYPE_SLIDE",
+		4: "CAPTCHA_TYPE_DRAG",
+		5: "CAPTCHA_TYPE_ROTATE",
+	}
+	CaptchaType_value = map[string]int32{
+		"CAPTCHA_TYPE_UNSPECIFIED": 0,
+		"CAPTCHA_TYPE_CLICK":       1,
+		"CAPTCHA_TYPE_CLICK_SHAPE": 2,
+		"CAPTCHA_TYPE_SLIDE":       3,
+		"CAPTCHA_TYPE_DRAG":        4,
+		"CAPTCHA_TYPE_ROTATE":      5,
+	}
+)
+
+func (x CaptchaType) Enum() *CaptchaType {
+	p := new(CaptchaType)
+	*p = x
+	return p
+}
+
+func (x CaptchaType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CaptchaType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v2_api_proto_enumTypes[0].Descriptor()
+}
+
+func (CaptchaType) Type() protoreflect.EnumType {
+	return &file_proto_v2_api_proto_enumTypes[0]
+}
+
+func (x CaptchaType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CaptchaType.Descriptor instead.
+func (CaptchaType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{0}
+}
+
+type CaptchaStatus int32
+
+const (
+	CaptchaStatus_CAPTCHA_STATUS_UNSPECIFIED CaptchaStatus = 0
+	CaptchaStatus_CAPTCHA_STATUS_PENDING     CaptchaStatus = 1
+	CaptchaStatus_CAPTCHA_STATUS_VERIFIED    CaptchaStatus = 2
+	CaptchaStatus_CAPTCHA_STATUS_FAILED      CaptchaStatus = 3
+	CaptchaStatus_CAPTCHA_STATUS_EXPIRED     CaptchaStatus = 4
+	CaptchaStatus_CAPTCHA_STATUS_NOT_FOUND   CaptchaStatus = 5
+)
+
+// Enum value maps for CaptchaStatus.
+var (
+	CaptchaStatus_name = map[int32]string{
+		0: "CAPTCHA_STATUS_UNSPECIFIED",
+		1: "CAPTCHA_STATUS_PENDING",
+		2: "CAPTCHA_STATUS_VERIFIED",
+		3: "CAPTCHA_STATUS_FAILED",
+		4: "CAPTCHA_STATUS_EXPIRED",
+		5: "CAPTCHA_STATUS_NOT_FOUND",
+	}
+	CaptchaStatus_value = map[string]int32{
+		"CAPTCHA_STATUS_UNSPECIFIED": 0,
+		"CAPTCHA_STATUS_PENDING":     1,
+		"CAPTCHA_STATUS_VERIFIED":    2,
+		"CAPTCHA_STATUS_FAILED":      3,
+		"CAPTCHA_STATUS_EXPIRED":     4,
+		"CAPTCHA_STATUS_NOT_FOUND":   5,
+	}
+)
+
+func (x CaptchaStatus) Enum() *CaptchaStatus {
+	p := new(CaptchaStatus)
+	*p = x
+	return p
+}
+
+func (x CaptchaStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CaptchaStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v2_api_proto_enumTypes[1].Descriptor()
+}
+
+func (CaptchaStatus) Type() protoreflect.EnumType {
+	return &file_proto_v2_api_proto_enumTypes[1]
+}
+
+func (x CaptchaStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CaptchaStatus.Descriptor instead.
+func (CaptchaStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{1}
+}
+
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X int32 `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y int32 `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+// TrackPoint is a point of the slide track, t is the offset in milliseconds from the start of the slide
+type TrackPoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X int32 `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y int32 `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	T int64 `protobuf:"varint,3,opt,name=t,proto3" json:"t,omitempty"`
+}
+
+func (x *TrackPoint) Reset() {
+	*x = TrackPoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrackPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackPoint) ProtoMessage() {}
+
+func (x *TrackPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackPoint.ProtoReflect.Descriptor instead.
+func (*TrackPoint) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TrackPoint) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *TrackPoint) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *TrackPoint) GetT() int64 {
+	if x != nil {
+		return x.T
+	}
+	return 0
+}
+
+type Image struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Base64 string `protobuf:"bytes,1,opt,name=base64,proto3" json:"base64,omitempty"`
+	Width  int32  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Image) GetBase64() string {
+	if x != nil {
+		return x.Base64
+	}
+	return ""
+}
+
+func (x *Image) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Image) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type ClickAnswer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Points []*Point `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+}
+
+func (x *ClickAnswer) Reset() {
+	*x = ClickAnswer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClickAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClickAnswer) ProtoMessage() {}
+
+func (x *ClickAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClickAnswer.ProtoReflect.Descriptor instead.
+func (*ClickAnswer) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClickAnswer) GetPoints() []*Point {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+type SlideAnswer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Point *Point        `protobuf:"bytes,1,opt,name=point,proto3" json:"point,omitempty"`
+	Track []*TrackPoint `protobuf:"bytes,2,rep,name=track,proto3" json:"track,omitempty"`
+}
+
+func (x *SlideAnswer) Reset() {
+	*x = SlideAnswer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SlideAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlideAnswer) ProtoMessage() {}
+
+func (x *SlideAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlideAnswer.ProtoReflect.Descriptor instead.
+func (*SlideAnswer) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SlideAnswer) GetPoint() *Point {
+	if x != nil {
+		return x.Point
+	}
+	return nil
+}
+
+func (x *SlideAnswer) GetTrack() []*TrackPoint {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+type RotateAnswer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Angle float32 `protobuf:"fixed32,1,opt,name=angle,proto3" json:"angle,omitempty"`
+}
+
+func (x *RotateAnswer) Reset() {
+	*x = RotateAnswer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RotateAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateAnswer) ProtoMessage() {}
+
+func (x *RotateAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateAnswer.ProtoReflect.Descriptor instead.
+func (*RotateAnswer) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RotateAnswer) GetAngle() float32 {
+	if x != nil {
+		return x.Angle
+	}
+	return 0
+}
+
+type GetDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetDataRequest) Reset() {
+	*x = GetDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataRequest) ProtoMessage() {}
+
+func (x *GetDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataRequest.ProtoReflect.Descriptor instead.
+func (*GetDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetDataResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CaptchaKey  string                 `protobuf:"bytes,2,opt,name=captcha_key,json=captchaKey,proto3" json:"captcha_key,omitempty"`
+	Type        CaptchaType            `protobuf:"varint,3,opt,name=type,proto3,enum=gocaptcha.v2.CaptchaType" json:"type,omitempty"`
+	MasterImage *Image                 `protobuf:"bytes,4,opt,name=master_image,json=masterImage,proto3" json:"master_image,omitempty"`
+	ThumbImage  *Image                 `protobuf:"bytes,5,opt,name=thumb_image,json=thumbImage,proto3" json:"thumb_image,omitempty"`
+	ThumbSize   int32                  `protobuf:"varint,6,opt,name=thumb_size,json=thumbSize,proto3" json:"thumb_size,omitempty"`
+	Display     *Point                 `protobuf:"bytes,7,opt,name=display,proto3" json:"display,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *GetDataResponse) Reset() {
+	*x = GetDataResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataResponse) ProtoMessage() {}
+
+func (x *GetDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataResponse.ProtoReflect.Descriptor instead.
+func (*GetDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetDataResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetDataResponse) GetCaptchaKey() string {
+	if x != nil {
+		return x.CaptchaKey
+	}
+	return ""
+}
+
+func (x *GetDataResponse) GetType() CaptchaType {
+	if x != nil {
+		return x.Type
+	}
+	return CaptchaType_CAPTCHA_TYPE_UNSPECIFIED
+}
+
+func (x *GetDataResponse) GetMasterImage() *Image {
+	if x != nil {
+		return x.MasterImage
+	}
+	return nil
+}
+
+func (x *GetDataResponse) GetThumbImage() *Image {
+	if x != nil {
+		return x.ThumbImage
+	}
+	return nil
+}
+
+func (x *GetDataResponse) GetThumbSize() int32 {
+	if x != nil {
+		return x.ThumbSize
+	}
+	return 0
+}
+
+func (x *GetDataResponse) GetDisplay() *Point {
+	if x != nil {
+		return x.Display
+	}
+	return nil
+}
+
+func (x *GetDataResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CheckDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CaptchaKey string `protobuf:"bytes,2,opt,name=captcha_key,json=captchaKey,proto3" json:"captcha_key,omitempty"`
+	// Types that are assignable to Answer:
+	//	*CheckDataRequest_Click
+	//	*CheckDataRequest_Slide
+	//	*CheckDataRequest_Rotate
+	Answer isCheckDataRequest_Answer `protobuf_oneof:"answer"`
+}
+
+func (x *CheckDataRequest) Reset() {
+	*x = CheckDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDataRequest) ProtoMessage() {}
+
+func (x *CheckDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDataRequest.ProtoReflect.Descriptor instead.
+func (*CheckDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckDataRequest) GetCaptchaKey() string {
+	if x != nil {
+		return x.CaptchaKey
+	}
+	return ""
+}
+
+func (m *CheckDataRequest) GetAnswer() isCheckDataRequest_Answer {
+	if m != nil {
+		return m.Answer
+	}
+	return nil
+}
+
+func (x *CheckDataRequest) GetClick() *ClickAnswer {
+	if x, ok := x.GetAnswer().(*CheckDataRequest_Click); ok {
+		return x.Click
+	}
+	return nil
+}
+
+func (x *CheckDataRequest) GetSlide() *SlideAnswer {
+	if x, ok := x.GetAnswer().(*CheckDataRequest_Slide); ok {
+		return x.Slide
+	}
+	return nil
+}
+
+func (x *CheckDataRequest) GetRotate() *RotateAnswer {
+	if x, ok := x.GetAnswer().(*CheckDataRequest_Rotate); ok {
+		return x.Rotate
+	}
+	return nil
+}
+
+type isCheckDataRequest_Answer interface {
+	isCheckDataRequest_Answer()
+}
+
+type CheckDataRequest_Click struct {
+	Click *ClickAnswer `protobuf:"bytes,3,opt,name=click,proto3,oneof"`
+}
+
+type CheckDataRequest_Slide struct {
+	Slide *SlideAnswer `protobuf:"bytes,4,opt,name=slide,proto3,oneof"`
+}
+
+type CheckDataRequest_Rotate struct {
+	Rotate *RotateAnswer `protobuf:"bytes,5,opt,name=rotate,proto3,oneof"`
+}
+
+func (*CheckDataRequest_Click) isCheckDataRequest_Answer() {}
+
+func (*CheckDataRequest_Slide) isCheckDataRequest_Answer() {}
+
+func (*CheckDataRequest_Rotate) isCheckDataRequest_Answer() {}
+
+type CheckDataResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// Reason of a failed verification, ERROR_REASON_UNSPECIFIED when verified
+	Reason proto.ErrorReason `protobuf:"varint,2,opt,name=reason,proto3,enum=gocaptcha.ErrorReason" json:"reason,omitempty"`
+}
+
+func (x *CheckDataResponse) Reset() {
+	*x = CheckDataResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDataResponse) ProtoMessage() {}
+
+func (x *CheckDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDataResponse.ProtoReflect.Descriptor instead.
+func (*CheckDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CheckDataResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *CheckDataResponse) GetReason() proto.ErrorReason {
+	if x != nil {
+		return x.Reason
+	}
+	return proto.ErrorReason(0)
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CaptchaKey string `protobuf:"bytes,1,opt,name=captcha_key,json=captchaKey,proto3" json:"captcha_key,omitempty"`
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetStatusRequest) GetCaptchaKey() string {
+	if x != nil {
+		return x.CaptchaKey
+	}
+	return ""
+}
+
+type GetStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CaptchaKey string                 `protobuf:"bytes,1,opt,name=captcha_key,json=captchaKey,proto3" json:"captcha_key,omitempty"`
+	Type       CaptchaType            `protobuf:"varint,2,opt,name=type,proto3,enum=gocaptcha.v2.CaptchaType" json:"type,omitempty"`
+	Status     CaptchaStatus          `protobuf:"varint,3,opt,name=status,proto3,enum=gocaptcha.v2.CaptchaStatus" json:"status,omitempty"`
+	ExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_v2_api_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v2_api_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v2_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetStatusResponse) GetCaptchaKey() string {
+	if x != nil {
+		return x.CaptchaKey
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetType() CaptchaType {
+	if x != nil {
+		return x.Type
+	}
+	return CaptchaType_CAPTCHA_TYPE_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetStatus() CaptchaStatus {
+	if x != nil {
+		return x.Status
+	}
+	return CaptchaStatus_CAPTCHA_STATUS_UNSPECIFIED
+}
+
+func (x *GetStatusResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+var File_proto_v2_api_proto protoreflect.FileDescriptor
+
+var file_proto_v2_api_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x70, 0x69, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e,
+	0x76, 0x32, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61,
+	0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0x23, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x79, 0x22, 0x36, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x63, 0x6b,
+	0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01,
+	0x79, 0x12, 0x0c, 0x0a, 0x01, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x01, 0x74, 0x22,
+	0x4d, 0x0a, 0x05, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x61, 0x73, 0x65,
+	0x36, 0x34, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x61, 0x73, 0x65, 0x36, 0x34,
+	0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x3a,
+	0x0a, 0x0b, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x2b, 0x0a,
+	0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6f, 0x69,
+	0x6e, 0x74, 0x52, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x68, 0x0a, 0x0b, 0x53, 0x6c,
+	0x69, 0x64, 0x65, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x05, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70,
+	0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x05, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e,
+	0x76, 0x32, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x05, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x22, 0x24, 0x0a, 0x0c, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x41, 0x6e,
+	0x73, 0x77, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x02, 0x52, 0x05, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x22, 0x20, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xe8, 0x02, 0x0a,
+	0x0f, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x4b, 0x65,
+	0x79, 0x12, 0x2d, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x43,
+	0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x36, 0x0a, 0x0c, 0x6d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63,
+	0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x0b, 0x6d, 0x61, 0x73,
+	0x74, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x34, 0x0a, 0x0b, 0x74, 0x68, 0x75, 0x6d,
+	0x62, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x52, 0x0a, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x2d, 0x0a,
+	0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x50, 0x6f,
+	0x69, 0x6e, 0x74, 0x52, 0x07, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x12, 0x39, 0x0a, 0x0a,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0xe9, 0x01, 0x0a, 0x10, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b,
+	0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x4b, 0x65, 0x79, 0x12, 0x31, 0x0a,
+	0x05, 0x63, 0x6c, 0x69, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x43, 0x6c, 0x69, 0x63,
+	0x6b, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x48, 0x00, 0x52, 0x05, 0x63, 0x6c, 0x69, 0x63, 0x6b,
+	0x12, 0x31, 0x0a, 0x05, 0x73, 0x6c, 0x69, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x53,
+	0x6c, 0x69, 0x64, 0x65, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x48, 0x00, 0x52, 0x05, 0x73, 0x6c,
+	0x69, 0x64, 0x65, 0x12, 0x34, 0x0a, 0x06, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e,
+	0x76, 0x32, 0x2e, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x48,
+	0x00, 0x52, 0x06, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x61, 0x6e, 0x73,
+	0x77, 0x65, 0x72, 0x22, 0x53, 0x0a, 0x11, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x44, 0x61, 0x74, 0x61,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x2e, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70,
+	0x74, 0x63, 0x68, 0x61, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x33, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x4b, 0x65, 0x79, 0x22, 0xd3, 0x01,
+	0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x5f, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68,
+	0x61, 0x4b, 0x65, 0x79, 0x12, 0x2d, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76,
+	0x32, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e,
+	0x76, 0x32, 0x2e, 0x43, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x73, 0x41, 0x74, 0x2a, 0xa9, 0x01, 0x0a, 0x0b, 0x43, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x43, 0x4c, 0x49, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x43, 0x41, 0x50,
+	0x54, 0x43, 0x48, 0x41, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x4c, 0x49, 0x43, 0x4b, 0x5f,
+	0x53, 0x48, 0x41, 0x50, 0x45, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x41, 0x50, 0x54, 0x43,
+	0x48, 0x41, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x4c, 0x49, 0x44, 0x45, 0x10, 0x03, 0x12,
+	0x15, 0x0a, 0x11, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x44, 0x52, 0x41, 0x47, 0x10, 0x04, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48,
+	0x41, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x4f, 0x54, 0x41, 0x54, 0x45, 0x10, 0x05, 0x2a,
+	0xbd, 0x01, 0x0a, 0x0d, 0x43, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x1e, 0x0a, 0x1a, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x1a, 0x0a, 0x16, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x1b, 0x0a,
+	0x17, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x56, 0x45, 0x52, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x41,
+	0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x41, 0x49,
+	0x4c, 0x45, 0x44, 0x10, 0x03, 0x12, 0x1a, 0x0a, 0x16, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x45, 0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10,
+	0x04, 0x12, 0x1c, 0x0a, 0x18, 0x43, 0x41, 0x50, 0x54, 0x43, 0x48, 0x41, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x05, 0x32,
+	0xe0, 0x02, 0x0a, 0x10, 0x47, 0x6f, 0x43, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x67, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12,
+	0x1c, 0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x47,
+	0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e,
+	0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74,
+	0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1f, 0x82, 0xd3,
+	0xe4, 0x93, 0x02, 0x19, 0x12, 0x17, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x2f, 0x67, 0x65, 0x74, 0x2d, 0x64, 0x61, 0x74, 0x61, 0x12, 0x72, 0x0a,
+	0x09, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x63,
+	0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x63,
+	0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x44,
+	0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x1e, 0x3a, 0x01, 0x2a, 0x22, 0x19, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x2f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x2d, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x6f, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e,
+	0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x67, 0x6f, 0x63, 0x61, 0x70, 0x74, 0x63, 0x68, 0x61, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x19, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32,
+	0x2f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x2f, 0x67, 0x65, 0x74, 0x2d, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x42, 0x14, 0x5a, 0x12, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76, 0x32,
+	0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x76, 0x32, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_v2_api_proto_rawDescOnce sync.Once
+	file_proto_v2_api_proto_rawDescData = file_proto_v2_api_proto_rawDesc
+)
+
+func file_proto_v2_api_proto_rawDescGZIP() []byte {
+	file_proto_v2_api_proto_rawDescOnce.Do(func() {
+		file_proto_v2_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_v2_api_proto_rawDescData)
+	})
+	return file_proto_v2_api_proto_rawDescData
+}
+
+var file_proto_v2_api_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_v2_api_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_v2_api_proto_goTypes = []interface{}{
+	(CaptchaType)(0),              // 0: gocaptcha.v2.CaptchaType
+	(CaptchaStatus)(0),            // 1: gocaptcha.v2.CaptchaStatus
+	(*Point)(nil),                 // 2: gocaptcha.v2.Point
+	(*TrackPoint)(nil),            // 3: gocaptcha.v2.TrackPoint
+	(*Image)(nil),                 // 4: gocaptcha.v2.Image
+	(*ClickAnswer)(nil),           // 5: gocaptcha.v2.ClickAnswer
+	(*SlideAnswer)(nil),           // 6: gocaptcha.v2.SlideAnswer
+	(*RotateAnswer)(nil),          // 7: gocaptcha.v2.RotateAnswer
+	(*GetDataRequest)(nil),        // 8: gocaptcha.v2.GetDataRequest
+	(*GetDataResponse)(nil),       // 9: gocaptcha.v2.GetDataResponse
+	(*CheckDataRequest)(nil),      // 10: gocaptcha.v2.CheckDataRequest
+	(*CheckDataResponse)(nil),     // 11: gocaptcha.v2.CheckDataResponse
+	(*GetStatusRequest)(nil),      // 12: gocaptcha.v2.GetStatusRequest
+	(*GetStatusResponse)(nil),     // 13: gocaptcha.v2.GetStatusResponse
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+	(proto.ErrorReason)(0),        // 15: gocaptcha.ErrorReason
+}
+var file_proto_v2_api_proto_depIdxs = []int32{
+	2,  // 0: gocaptcha.v2.ClickAnswer.points:type_name -> gocaptcha.v2.Point
+	2,  // 1: gocaptcha.v2.SlideAnswer.point:type_name -> gocaptcha.v2.Point
+	3,  // 2: gocaptcha.v2.SlideAnswer.track:type_name -> gocaptcha.v2.TrackPoint
+	0,  // 3: gocaptcha.v2.GetDataResponse.type:type_name -> gocaptcha.v2.CaptchaType
+	4,  // 4: gocaptcha.v2.GetDataResponse.master_image:type_name -> gocaptcha.v2.Image
+	4,  // 5: gocaptcha.v2.GetDataResponse.thumb_image:type_name -> gocaptcha.v2.Image
+	2,  // 6: gocaptcha.v2.GetDataResponse.display:type_name -> gocaptcha.v2.Point
+	14, // 7: gocaptcha.v2.GetDataResponse.expires_at:type_name -> google.protobuf.Timestamp
+	5,  // 8: gocaptcha.v2.CheckDataRequest.click:type_name -> gocaptcha.v2.ClickAnswer
+	6,  // 9: gocaptcha.v2.CheckDataRequest.slide:type_name -> gocaptcha.v2.SlideAnswer
+	7,  // 10: gocaptcha.v2.CheckDataRequest.rotate:type_name -> gocaptcha.v2.RotateAnswer
+	15, // 11: gocaptcha.v2.CheckDataResponse.reason:type_name -> gocaptcha.ErrorReason
+	0,  // 12: gocaptcha.v2.GetStatusResponse.type:type_name -> gocaptcha.v2.CaptchaType
+	1,  // 13: gocaptcha.v2.GetStatusResponse.status:type_name -> gocaptcha.v2.CaptchaStatus
+	14, // 14: gocaptcha.v2.GetStatusResponse.expires_at:type_name -> google.protobuf.Timestamp
+	8,  // 15: gocaptcha.v2.GoCaptchaService.GetData:input_type -> gocaptcha.v2.GetDataRequest
+	10, // 16: gocaptcha.v2.GoCaptchaService.CheckData:input_type -> gocaptcha.v2.CheckDataRequest
+	12, // 17: gocaptcha.v2.GoCaptchaService.GetStatus:input_type -> gocaptcha.v2.GetStatusRequest
+	9,  // 18: gocaptcha.v2.GoCaptchaService.GetData:output_type -> gocaptcha.v2.GetDataResponse
+	11, // 19: gocaptcha.v2.GoCaptchaService.CheckData:output_type -> gocaptcha.v2.CheckDataResponse
+	13, // 20: gocaptcha.v2.GoCaptchaService.GetStatus:output_type -> gocaptcha.v2.GetStatusResponse
+	18, // [18:21] is the sub-list for method output_type
+	15, // [15:18] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_proto_v2_api_proto_init() }
+func file_proto_v2_api_proto_init() {
+	if File_proto_v2_api_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_v2_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrackPoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Image); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClickAnswer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SlideAnswer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RotateAnswer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDataResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckDataResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_v2_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_v2_api_proto_msgTypes[8].OneofWrappers = []interface{}{
+		(*CheckDataRequest_Click)(nil),
+		(*CheckDataRequest_Slide)(nil),
+		(*CheckDataRequest_Rotate)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_v2_api_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_v2_api_proto_goTypes,
+		DependencyIndexes: file_proto_v2_api_proto_depIdxs,
+		EnumInfos:         file_proto_v2_api_proto_enumTypes,
+		MessageInfos:      file_proto_v2_api_proto_msgTypes,
+	}.Build()
+	File_proto_v2_api_proto = out.File
+	file_proto_v2_api_proto_rawDesc = nil
+	file_proto_v2_api_proto_goTypes = nil
+	file_proto_v2_api_proto_depIdxs = nil
+}